@@ -0,0 +1,226 @@
+// cmd/flashsale-loadtest - load test reservasi flash sale terhadap database MySQL sungguhan.
+//
+// Membuat seller, produk, flash sale dan sejumlah user sementara, lalu semua user menyerbu
+// ReserveFlashSale bersamaan (seperti detik pertama kampanye dimulai). Setelah selesai dicek:
+//   - kuota terjual tidak melebihi quota (tidak over-selling)
+//   - kolom sold sama dengan jumlah quantity reservasi yang sukses dan yang tersimpan di tabel
+//   - tidak ada user yang melewati per_user_limit
+//
+// Pemakaian (memakai konfigurasi DB yang sama dengan server, dari .env):
+//
+//	go run ./cmd/flashsale-loadtest -users 1000 -quota 100 -per-user 2 -attempts 3
+//
+// Exit code 1 jika ada pelanggaran. Data test dihapus kecuali memakai -keep.
+package main
+
+import (
+	"ecommerce-golang/config"
+	"ecommerce-golang/models"
+	"ecommerce-golang/money"
+	"ecommerce-golang/utils"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+func main() {
+	users := flag.Int("users", 500, "jumlah user yang berebut kuota")
+	quota := flag.Uint("quota", 100, "kuota flash sale")
+	perUser := flag.Uint("per-user", 2, "batas pembelian per user (0 = tanpa batas)")
+	attempts := flag.Int("attempts", 3, "jumlah request reservasi per user")
+	quantity := flag.Uint("qty", 1, "quantity per request")
+	keep := flag.Bool("keep", false, "jangan hapus data test")
+	flag.Parse()
+
+	_ = godotenv.Load(".env")
+	db := config.ConnectDB()
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.SetMaxOpenConns(100)
+		sqlDB.SetMaxIdleConns(100)
+	}
+
+	runID := time.Now().UnixNano()
+	fixture, err := setup(db, runID, *users, *quota, *perUser)
+	if err != nil {
+		log.Fatalf("setup failed: %v", err)
+	}
+	if !*keep {
+		defer cleanup(db, fixture)
+	}
+
+	var (
+		success, soldOut, userLimit, failed atomic.Int64
+		reservedQuantity                    atomic.Int64
+		wg                                  sync.WaitGroup
+		start                               = make(chan struct{})
+	)
+
+	for _, userID := range fixture.userIDs {
+		for i := 0; i < *attempts; i++ {
+			wg.Add(1)
+			go func(userID uint) {
+				defer wg.Done()
+				<-start
+
+				_, err := utils.ReserveFlashSale(db, fixture.sale.ID, userID, *quantity, time.Now())
+				switch {
+				case err == nil:
+					success.Add(1)
+					reservedQuantity.Add(int64(*quantity))
+				case errors.Is(err, utils.ErrFlashSaleSoldOut):
+					soldOut.Add(1)
+				case errors.Is(err, utils.ErrFlashSaleUserLimit):
+					userLimit.Add(1)
+				default:
+					failed.Add(1)
+					log.Printf("reserve error: %v", err)
+				}
+			}(userID)
+		}
+	}
+
+	began := time.Now()
+	close(start)
+	wg.Wait()
+	elapsed := time.Since(began)
+
+	total := len(fixture.userIDs) * *attempts
+	fmt.Printf("requests: %d in %s (%.0f req/s)\n", total, elapsed.Round(time.Millisecond), float64(total)/elapsed.Seconds())
+	fmt.Printf("success: %d, sold out: %d, user limit: %d, error: %d\n",
+		success.Load(), soldOut.Load(), userLimit.Load(), failed.Load())
+
+	violations := verify(db, fixture, uint(reservedQuantity.Load()), *perUser)
+	if len(violations) > 0 {
+		for _, v := range violations {
+			fmt.Println("FAIL:", v)
+		}
+		if !*keep {
+			cleanup(db, fixture)
+		}
+		os.Exit(1)
+	}
+	fmt.Println("OK: no over-selling, per-user limit respected")
+}
+
+type fixture struct {
+	sellerID  uint
+	productID uint
+	sale      models.FlashSale
+	userIDs   []uint
+}
+
+func setup(db *gorm.DB, runID int64, users int, quota, perUser uint) (fixture, error) {
+	var f fixture
+
+	seller := models.Seller{
+		Email:    fmt.Sprintf("loadtest-seller-%d@example.com", runID),
+		Username: fmt.Sprintf("loadtest-seller-%d", runID),
+	}
+	if err := db.Create(&seller).Error; err != nil {
+		return f, err
+	}
+	f.sellerID = seller.ID
+
+	product := models.Product{
+		SellerID: seller.ID,
+		Name:     fmt.Sprintf("Load test product %d", runID),
+		Price:    money.IDR(100000),
+		Stock:    quota,
+		Weight:   100,
+		IsActive: true,
+	}
+	if err := db.Create(&product).Error; err != nil {
+		return f, err
+	}
+	f.productID = product.ID
+
+	now := time.Now()
+	f.sale = models.FlashSale{
+		ProductID:    product.ID,
+		SellerID:     seller.ID,
+		SalePrice:    money.IDR(10000),
+		Quota:        quota,
+		PerUserLimit: perUser,
+		StartsAt:     now.Add(-time.Second),
+		EndsAt:       now.Add(time.Hour),
+		IsActive:     true,
+	}
+	if err := db.Create(&f.sale).Error; err != nil {
+		return f, err
+	}
+
+	batch := make([]models.User, users)
+	for i := range batch {
+		batch[i] = models.User{
+			Email:    fmt.Sprintf("loadtest-%d-%d@example.com", runID, i),
+			Username: fmt.Sprintf("loadtest-%d-%d", runID, i),
+		}
+	}
+	if err := db.CreateInBatches(&batch, 500).Error; err != nil {
+		return f, err
+	}
+	for _, user := range batch {
+		f.userIDs = append(f.userIDs, user.ID)
+	}
+	return f, nil
+}
+
+func verify(db *gorm.DB, f fixture, reservedQuantity, perUser uint) []string {
+	var violations []string
+
+	var sale models.FlashSale
+	if err := db.First(&sale, f.sale.ID).Error; err != nil {
+		return []string{"reload flash sale: " + err.Error()}
+	}
+	if sale.Sold > sale.Quota {
+		violations = append(violations, fmt.Sprintf("over-selling: sold %d > quota %d", sale.Sold, sale.Quota))
+	}
+	if sale.Sold != reservedQuantity {
+		violations = append(violations, fmt.Sprintf("sold column %d != successful reservations %d", sale.Sold, reservedQuantity))
+	}
+
+	var stored uint
+	db.Model(&models.FlashSaleReservation{}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Where("flash_sale_id = ?", sale.ID).
+		Scan(&stored)
+	if stored != sale.Sold {
+		violations = append(violations, fmt.Sprintf("reservation rows total %d != sold column %d", stored, sale.Sold))
+	}
+
+	if perUser > 0 {
+		var over []struct {
+			UserID uint
+			Total  uint
+		}
+		db.Model(&models.FlashSaleReservation{}).
+			Select("user_id, SUM(quantity) as total").
+			Where("flash_sale_id = ?", sale.ID).
+			Group("user_id").
+			Having("SUM(quantity) > ?", perUser).
+			Scan(&over)
+		for _, row := range over {
+			violations = append(violations, fmt.Sprintf("user %d reserved %d > per-user limit %d", row.UserID, row.Total, perUser))
+		}
+	}
+
+	fmt.Printf("quota: %d, sold: %d, remaining: %d\n", sale.Quota, sale.Sold, sale.Remaining())
+	return violations
+}
+
+func cleanup(db *gorm.DB, f fixture) {
+	db.Where("flash_sale_id = ?", f.sale.ID).Delete(&models.FlashSaleReservation{})
+	db.Delete(&models.FlashSale{}, f.sale.ID)
+	db.Delete(&models.Product{}, f.productID)
+	if len(f.userIDs) > 0 {
+		db.Delete(&models.User{}, f.userIDs)
+	}
+	db.Delete(&models.Seller{}, f.sellerID)
+}
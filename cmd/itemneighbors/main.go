@@ -0,0 +1,42 @@
+// cmd/itemneighbors - job offline untuk rekomendasi item-to-item ("customers also bought").
+//
+// Membaca interaksi user dari product_user_histories (pembelian) dan product_user_carts
+// (keranjang), menghitung cosine similarity antar produk, lalu mengganti isi tabel
+// product_neighbors dengan top-N tetangga per produk.
+//
+// Pemakaian (memakai konfigurasi DB yang sama dengan server, dari .env), misalnya dari cron
+// setiap malam:
+//
+//	go run ./cmd/itemneighbors -top 20 -min-support 2 -max-items 50
+package main
+
+import (
+	"ecommerce-golang/config"
+	"ecommerce-golang/utils"
+	"flag"
+	"github.com/joho/godotenv"
+	"log"
+	"time"
+)
+
+func main() {
+	defaults := utils.DefaultItemNeighborOptions()
+	top := flag.Int("top", defaults.TopN, "jumlah tetangga yang disimpan per produk")
+	minSupport := flag.Uint("min-support", defaults.MinSupport, "minimal user yang berinteraksi dengan kedua produk")
+	maxItems := flag.Int("max-items", defaults.MaxItemsPerUser, "item terbaru per user yang dihitung (0 = semua)")
+	flag.Parse()
+
+	_ = godotenv.Load(".env")
+	db := config.ConnectDB()
+
+	start := time.Now()
+	products, rows, err := utils.RebuildItemNeighbors(db, utils.ItemNeighborOptions{
+		TopN:            *top,
+		MinSupport:      *minSupport,
+		MaxItemsPerUser: *maxItems,
+	}, start)
+	if err != nil {
+		log.Fatalf("Rebuild item neighbors failed: %v", err)
+	}
+	log.Printf("Saved %d neighbors for %d products in %s", rows, products, time.Since(start).Round(time.Millisecond))
+}
@@ -0,0 +1,354 @@
+// cmd/receval - evaluasi offline kualitas rekomendasi.
+//
+// Untuk setiap user dengan riwayat pembelian yang cukup, N pembelian terakhir disembunyikan
+// (held-out). Di dalam satu transaksi yang selalu di-rollback, baris held-out dihapus beserta
+// view dan keranjang user sejak pembelian tersebut, product_neighbors dan trending_products
+// dihitung ulang dari data yang tersisa, lalu setiap strategi di package recommend dijalankan
+// seolah-olah sebelum pembelian itu terjadi. Rekomendasi dibandingkan dengan produk yang
+// benar-benar dibeli dan dilaporkan precision@k, recall@k, NDCG@k, hit rate, coverage
+// katalog dan diversity kategori.
+//
+// Catatan: total_sold dan rating produk tetap nilai saat ini, jadi strategi bestseller sedikit
+// diuntungkan. Transaksi mengunci tabel yang dihapus selama evaluasi, jalankan di replica atau
+// salinan database, bukan di database produksi.
+//
+// Pemakaian (memakai konfigurasi DB yang sama dengan server, dari .env):
+//
+//	go run ./cmd/receval -k 10 -holdout 1 -max-users 1000
+//	go run ./cmd/receval -strategies personal,item_similarity -experiment -json
+//
+// Dengan -synthetic dibuat dulu seller, produk dan user sintetis dengan preferensi kategori dan
+// pasangan produk yang sering dibeli bersama (deterministik dari -seed), lalu hanya user sintetis
+// yang dievaluasi. Mode ini tidak butuh database: data dibuat di SQLite in-memory. Dengan
+// -mysql data sintetis ditulis ke database dari .env dan dihapus setelah selesai kecuali
+// memakai -keep:
+//
+//	go run ./cmd/receval -synthetic -synthetic-users 300 -synthetic-products 200 -seed 1
+//	go run ./cmd/receval -synthetic -mysql
+package main
+
+import (
+	"ecommerce-golang/config"
+	"ecommerce-golang/models"
+	"ecommerce-golang/recommend"
+	"ecommerce-golang/utils"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/glebarez/sqlite"
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type options struct {
+	k, holdout, minHistory, maxUsers int
+	strategies                       string
+	withExperiment, jsonOutput       bool
+	synthetic, useMySQL, keep        bool
+	syntheticOptions                 syntheticOptions
+}
+
+func main() {
+	var opts options
+	flag.IntVar(&opts.k, "k", 10, "jumlah rekomendasi teratas yang dinilai")
+	flag.IntVar(&opts.holdout, "holdout", 1, "jumlah pembelian terakhir per user yang disembunyikan")
+	flag.IntVar(&opts.minHistory, "min-history", 2, "minimal pembelian yang tersisa per user setelah holdout")
+	flag.IntVar(&opts.maxUsers, "max-users", 1000, "jumlah user yang dievaluasi (0 = semua)")
+	flag.StringVar(&opts.strategies, "strategies", "", "strategi dipisah koma (default semua strategi terdaftar + arm default)")
+	flag.BoolVar(&opts.withExperiment, "experiment", false, "ikut evaluasi setiap arm experiment yang aktif")
+	flag.BoolVar(&opts.jsonOutput, "json", false, "tulis laporan sebagai JSON")
+	flag.BoolVar(&opts.synthetic, "synthetic", false, "buat data sintetis dulu lalu evaluasi user sintetis saja")
+	flag.Int64Var(&opts.syntheticOptions.Seed, "seed", 1, "seed data sintetis")
+	flag.IntVar(&opts.syntheticOptions.Users, "synthetic-users", 300, "jumlah user sintetis")
+	flag.IntVar(&opts.syntheticOptions.Products, "synthetic-products", 200, "jumlah produk sintetis")
+	flag.BoolVar(&opts.useMySQL, "mysql", false, "mode synthetic: tulis data ke database dari .env, bukan SQLite in-memory")
+	flag.BoolVar(&opts.keep, "keep", false, "jangan hapus data sintetis (hanya dengan -mysql)")
+	flag.Parse()
+
+	if opts.k <= 0 || opts.holdout <= 0 || opts.minHistory < 0 {
+		log.Fatal("-k and -holdout must be greater than 0, -min-history must not be negative")
+	}
+
+	var db *gorm.DB
+	if opts.synthetic && !opts.useMySQL {
+		var err error
+		if db, err = openMemoryDB(); err != nil {
+			log.Fatalf("open in-memory database failed: %v", err)
+		}
+		opts.keep = true // database hilang saat proses selesai
+	} else {
+		_ = godotenv.Load(".env")
+		db = config.ConnectDB()
+	}
+
+	// run terpisah supaya data sintetis tetap dihapus (defer) walaupun evaluasi gagal
+	if err := run(db, opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(db *gorm.DB, opts options) error {
+	targets, err := evalTargets(db, opts.strategies, opts.withExperiment)
+	if err != nil {
+		return err
+	}
+
+	var onlyUsers, onlySellers []uint
+	if opts.synthetic {
+		opts.syntheticOptions.RunID = time.Now().UnixNano()
+		fixture, err := generateSynthetic(db, opts.syntheticOptions, time.Now())
+		if !opts.keep {
+			defer cleanupSynthetic(db, fixture)
+		}
+		if err != nil {
+			return fmt.Errorf("generate synthetic data failed: %w", err)
+		}
+		onlyUsers, onlySellers = fixture.userIDs, fixture.sellerIDs
+	}
+
+	cases, err := buildCases(db, onlyUsers, opts.holdout, opts.minHistory, opts.maxUsers)
+	if err != nil {
+		return fmt.Errorf("build holdout cases failed: %w", err)
+	}
+	if len(cases) == 0 {
+		return fmt.Errorf("no user has at least %d purchases", opts.holdout+opts.minHistory)
+	}
+
+	reports, err := evaluate(db, cases, targets, opts.k, onlySellers)
+	if err != nil {
+		return fmt.Errorf("evaluation failed: %w", err)
+	}
+
+	if opts.jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(reports)
+	}
+	printReports(reports)
+	return nil
+}
+
+// openMemoryDB - database SQLite in-memory dengan skema yang sama seperti config.ConnectDB
+func openMemoryDB() (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		TranslateError: true,
+		Logger:         logger.Default.LogMode(logger.Error),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Satu koneksi supaya semua query (termasuk transaksi evaluasi) melihat database yang sama
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+	return db, db.AutoMigrate(config.Models()...)
+}
+
+// evalTarget - satu strategi atau satu arm (campuran strategi) yang dievaluasi
+type evalTarget struct {
+	name string
+	run  func(db *gorm.DB, req recommend.Request) []models.ProductListView
+}
+
+func strategyTarget(r recommend.Recommender) evalTarget {
+	return evalTarget{name: r.Name(), run: func(db *gorm.DB, req recommend.Request) []models.ProductListView {
+		return r.Recommend(db, req).Products
+	}}
+}
+
+func armTarget(name string, arm models.ExperimentArm) evalTarget {
+	return evalTarget{name: name, run: func(db *gorm.DB, req recommend.Request) []models.ProductListView {
+		return recommend.Compose(db, req, arm).Products
+	}}
+}
+
+func evalTargets(db *gorm.DB, list string, withExperiment bool) ([]evalTarget, error) {
+	var targets []evalTarget
+	if strings.TrimSpace(list) == "" {
+		for _, name := range recommend.Names() {
+			r, _ := recommend.Lookup(name)
+			targets = append(targets, strategyTarget(r))
+		}
+		targets = append(targets, armTarget("arm:default", recommend.DefaultArm()))
+	} else {
+		for _, name := range strings.Split(list, ",") {
+			name = strings.TrimSpace(name)
+			if name == "default" {
+				targets = append(targets, armTarget("arm:default", recommend.DefaultArm()))
+				continue
+			}
+			r, ok := recommend.Lookup(name)
+			if !ok {
+				return nil, fmt.Errorf("unknown strategy %q (available: %s, default)", name, strings.Join(recommend.Names(), ", "))
+			}
+			targets = append(targets, strategyTarget(r))
+		}
+	}
+
+	if withExperiment {
+		experiment, err := recommend.LoadActiveExperiment(db)
+		if err != nil {
+			return nil, err
+		}
+		if experiment == nil {
+			log.Printf("No active recommendation experiment, -experiment ignored")
+		} else {
+			for _, arm := range experiment.Arms {
+				targets = append(targets, armTarget("arm:"+experiment.Name+"/"+arm.Name, arm))
+			}
+		}
+	}
+	return targets, nil
+}
+
+// holdoutCase - satu user: pembelian yang disembunyikan dan waktu pembelian pertama di antaranya
+type holdoutCase struct {
+	userID     uint
+	cutoff     time.Time
+	historyIDs []uint
+	relevant   []uint
+}
+
+// buildCases - N pembelian terakhir per user dijadikan ground truth, hanya untuk user yang
+// masih punya minHistory pembelian sebelumnya
+func buildCases(db *gorm.DB, onlyUsers []uint, holdout, minHistory, maxUsers int) ([]holdoutCase, error) {
+	query := db.Model(&models.ProductUserHistory{}).
+		Select("user_id").
+		Group("user_id").
+		Having("COUNT(*) >= ?", holdout+minHistory).
+		Order("user_id")
+	if onlyUsers != nil {
+		query = query.Where("user_id IN ?", onlyUsers)
+	}
+	if maxUsers > 0 {
+		query = query.Limit(maxUsers)
+	}
+	var userIDs []uint
+	if err := query.Pluck("user_id", &userIDs).Error; err != nil {
+		return nil, err
+	}
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	var histories []models.ProductUserHistory
+	if err := db.Select("id, user_id, product_id, created_at").
+		Where("user_id IN ?", userIDs).
+		Order("user_id, created_at DESC, id DESC").
+		Find(&histories).Error; err != nil {
+		return nil, err
+	}
+
+	var cases []holdoutCase
+	taken := 0
+	for i, history := range histories {
+		if i == 0 || histories[i-1].UserID != history.UserID {
+			cases = append(cases, holdoutCase{userID: history.UserID})
+			taken = 0
+		}
+		if taken >= holdout {
+			continue
+		}
+		current := &cases[len(cases)-1]
+		current.cutoff = history.CreatedAt
+		current.historyIDs = append(current.historyIDs, history.ID)
+		if !containsUint(current.relevant, history.ProductID) {
+			current.relevant = append(current.relevant, history.ProductID)
+		}
+		taken++
+	}
+	return cases, nil
+}
+
+// hideHoldout - menghapus data held-out di dalam tx lalu menghitung ulang tabel turunan
+func hideHoldout(tx *gorm.DB, cases []holdoutCase, now time.Time) error {
+	for _, hc := range cases {
+		if err := tx.Where("id IN ?", hc.historyIDs).Delete(&models.ProductUserHistory{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? AND viewed_at >= ?", hc.userID, hc.cutoff).Delete(&models.ProductView{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? AND updated_at >= ?", hc.userID, hc.cutoff).Delete(&models.ProductUserCart{}).Error; err != nil {
+			return err
+		}
+	}
+
+	if _, _, err := utils.RebuildItemNeighbors(tx, utils.DefaultItemNeighborOptions(), now); err != nil {
+		return fmt.Errorf("rebuild item neighbors: %w", err)
+	}
+	if _, err := utils.RefreshTrendingProducts(tx, now); err != nil {
+		return fmt.Errorf("refresh trending products: %w", err)
+	}
+	return nil
+}
+
+func evaluate(db *gorm.DB, cases []holdoutCase, targets []evalTarget, k int, onlySellers []uint) ([]recommend.EvalReport, error) {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	// Evaluasi tidak boleh mengubah data, apapun hasilnya
+	defer tx.Rollback()
+
+	start := time.Now()
+	if err := hideHoldout(tx, cases, start); err != nil {
+		return nil, err
+	}
+
+	catalog := tx.Model(&models.Product{}).Where("is_active = ? AND stock > 0", true)
+	if onlySellers != nil {
+		catalog = catalog.Where("seller_id IN ?", onlySellers)
+	}
+	var catalogSize int64
+	if err := catalog.Count(&catalogSize).Error; err != nil {
+		return nil, err
+	}
+
+	log.Printf("Evaluating %d strategies for %d users (k=%d, catalog=%d)", len(targets), len(cases), k, catalogSize)
+	reports := make([]recommend.EvalReport, 0, len(targets))
+	for _, target := range targets {
+		evaluation := recommend.NewEvaluation(target.name, k, int(catalogSize))
+		for _, hc := range cases {
+			recommended := target.run(tx, recommend.Request{
+				UserID:          hc.userID,
+				IsAuthenticated: true,
+				Limit:           k,
+			})
+			evaluation.Add(recommended, hc.relevant)
+		}
+		reports = append(reports, evaluation.Report())
+	}
+	log.Printf("Evaluation finished in %s", time.Since(start).Round(time.Millisecond))
+	return reports, nil
+}
+
+func printReports(reports []recommend.EvalReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "strategy\tusers\tempty\tprecision@k\trecall@k\tndcg@k\thit rate\tcoverage\tdiversity\t")
+	for _, r := range reports {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f\t%.4f\t\n",
+			r.Name, r.Users, r.EmptyLists, r.Precision, r.Recall, r.NDCG, r.HitRate, r.Coverage, r.Diversity)
+	}
+	w.Flush()
+	if len(reports) > 0 {
+		fmt.Printf("k = %d\n", reports[0].K)
+	}
+}
+
+func containsUint(values []uint, value uint) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+// cmd/receval/synthetic.go - generator data sintetis untuk menjalankan evaluasi tanpa data produksi
+package main
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/money"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+var (
+	syntheticCategories = []string{"Elektronik", "Fashion Pria", "Fashion Wanita", "Rumah Tangga", "Olahraga", "Kecantikan", "Buku", "Mainan"}
+	syntheticCities     = []string{"Jakarta", "Bandung", "Surabaya", "Medan", "Yogyakarta"}
+)
+
+const syntheticSellers = 10
+
+type syntheticOptions struct {
+	RunID    int64
+	Seed     int64
+	Users    int
+	Products int
+}
+
+type syntheticFixture struct {
+	sellerIDs  []uint
+	productIDs []uint
+	userIDs    []uint
+}
+
+// generateSynthetic - membuat data dengan pola yang bisa dipelajari strategi:
+//   - setiap user punya 1-2 kategori favorit, 80% pembelian dari kategori itu
+//   - popularitas produk di satu kategori mengikuti distribusi Zipf (ada best seller)
+//   - produk berpasangan (bundle); 40% pembelian berikutnya adalah pasangan produk sebelumnya,
+//     sinyal untuk item_similarity
+//   - view dan keranjang beberapa hari terakhir dari kategori favorit
+//
+// Fixture dikembalikan walaupun gagal di tengah jalan supaya data yang sudah dibuat bisa dihapus.
+func generateSynthetic(db *gorm.DB, opts syntheticOptions, now time.Time) (syntheticFixture, error) {
+	var f syntheticFixture
+	if opts.Users <= 0 || opts.Products < len(syntheticCategories) {
+		return f, fmt.Errorf("synthetic data needs at least 1 user and %d products", len(syntheticCategories))
+	}
+	r := rand.New(rand.NewSource(opts.Seed))
+
+	sellers := make([]models.Seller, syntheticSellers)
+	for i := range sellers {
+		sellers[i] = models.Seller{
+			Email:    fmt.Sprintf("receval-seller-%d-%d@example.com", opts.RunID, i),
+			Username: fmt.Sprintf("receval-seller-%d-%d", opts.RunID, i),
+		}
+	}
+	if err := db.Create(&sellers).Error; err != nil {
+		return f, err
+	}
+	profiles := make([]models.SellerProfile, len(sellers))
+	for i, seller := range sellers {
+		f.sellerIDs = append(f.sellerIDs, seller.ID)
+		profiles[i] = models.SellerProfile{
+			SellerID: seller.ID,
+			ShopName: fmt.Sprintf("Synthetic Shop %d", i+1),
+			City:     syntheticCities[i%len(syntheticCities)],
+		}
+	}
+	if err := db.Create(&profiles).Error; err != nil {
+		return f, err
+	}
+
+	products := make([]models.Product, opts.Products)
+	for i := range products {
+		products[i] = models.Product{
+			SellerID:  f.sellerIDs[r.Intn(len(f.sellerIDs))],
+			Name:      fmt.Sprintf("Synthetic product %d-%d", opts.RunID, i),
+			Price:     money.IDR(int64(10+r.Intn(490)) * 1000),
+			Stock:     1000,
+			Category:  syntheticCategories[i%len(syntheticCategories)],
+			Weight:    500,
+			IsActive:  true,
+			Rating:    math.Round((3.5+1.5*r.Float64())*10) / 10,
+			CreatedAt: now.Add(-90 * 24 * time.Hour),
+		}
+	}
+	if err := db.CreateInBatches(&products, 500).Error; err != nil {
+		return f, err
+	}
+	byCategory := make(map[string][]int)
+	for i, product := range products {
+		f.productIDs = append(f.productIDs, product.ID)
+		byCategory[product.Category] = append(byCategory[product.Category], i)
+	}
+	zipf := make(map[string]*rand.Zipf)
+	for category, list := range byCategory {
+		zipf[category] = rand.NewZipf(r, 1.2, 1, uint64(len(list)-1))
+	}
+	pick := func(category string) int {
+		return byCategory[category][zipf[category].Uint64()]
+	}
+	// Pasangan bundle: produk ke-0 dan ke-1 di kategori yang sama, ke-2 dan ke-3, dst
+	partner := func(index int) (int, bool) {
+		list := byCategory[products[index].Category]
+		for position, candidate := range list {
+			if candidate == index {
+				if other := position ^ 1; other < len(list) {
+					return list[other], true
+				}
+			}
+		}
+		return 0, false
+	}
+
+	users := make([]models.User, opts.Users)
+	for i := range users {
+		users[i] = models.User{
+			Email:    fmt.Sprintf("receval-%d-%d@example.com", opts.RunID, i),
+			Username: fmt.Sprintf("receval-%d-%d", opts.RunID, i),
+		}
+	}
+	if err := db.CreateInBatches(&users, 500).Error; err != nil {
+		return f, err
+	}
+
+	var (
+		userProfiles []models.UserProfile
+		histories    []models.ProductUserHistory
+		carts        []models.ProductUserCart
+		views        []models.ProductView
+		sold         = make(map[int]uint)
+	)
+	for _, user := range users {
+		f.userIDs = append(f.userIDs, user.ID)
+		userProfiles = append(userProfiles, models.UserProfile{
+			UserID: user.ID,
+			City:   syntheticCities[r.Intn(len(syntheticCities))],
+		})
+
+		favorites := []string{syntheticCategories[r.Intn(len(syntheticCategories))]}
+		if r.Float64() < 0.5 {
+			favorites = append(favorites, syntheticCategories[r.Intn(len(syntheticCategories))])
+		}
+		preferred := func() string {
+			if r.Float64() < 0.8 {
+				return favorites[r.Intn(len(favorites))]
+			}
+			return syntheticCategories[r.Intn(len(syntheticCategories))]
+		}
+
+		// Waktu pembelian tersebar di 60 hari terakhir, diurutkan supaya pasangan bundle
+		// selalu dibeli setelah produk pertamanya
+		purchases := 3 + r.Intn(6)
+		offsets := make([]time.Duration, purchases)
+		for i := range offsets {
+			offsets[i] = time.Duration(r.Int63n(int64(60 * 24 * time.Hour)))
+		}
+		sort.Slice(offsets, func(i, j int) bool { return offsets[i] > offsets[j] })
+
+		previous := -1
+		for _, offset := range offsets {
+			index := pick(preferred())
+			if previous >= 0 && r.Float64() < 0.4 {
+				if other, ok := partner(previous); ok {
+					index = other
+				}
+			}
+			quantity := uint(1 + r.Intn(2))
+			histories = append(histories, models.ProductUserHistory{
+				UserID:    user.ID,
+				ProductID: products[index].ID,
+				Category:  products[index].Category,
+				Quantity:  quantity,
+				Price:     products[index].Price,
+				CreatedAt: now.Add(-offset),
+			})
+			sold[index] += quantity
+			previous = index
+		}
+
+		inCart := make(map[uint]bool)
+		for i := r.Intn(3); i > 0; i-- {
+			index := pick(favorites[0])
+			if inCart[products[index].ID] {
+				continue
+			}
+			inCart[products[index].ID] = true
+			updatedAt := now.Add(-time.Duration(r.Int63n(int64(3 * 24 * time.Hour))))
+			carts = append(carts, models.ProductUserCart{
+				UserID:     user.ID,
+				ProductID:  products[index].ID,
+				Quantity:   1,
+				PriceAtAdd: products[index].Price,
+				Selected:   true,
+				CreatedAt:  updatedAt,
+				UpdatedAt:  updatedAt,
+			})
+		}
+
+		userID := user.ID
+		for i := 5 + r.Intn(11); i > 0; i-- {
+			index := pick(preferred())
+			views = append(views, models.ProductView{
+				UserID:    &userID,
+				ProductID: products[index].ID,
+				ViewedAt:  now.Add(-time.Duration(r.Int63n(int64(7 * 24 * time.Hour)))),
+			})
+		}
+	}
+
+	if err := db.CreateInBatches(&userProfiles, 500).Error; err != nil {
+		return f, err
+	}
+	if err := db.CreateInBatches(&histories, 500).Error; err != nil {
+		return f, err
+	}
+	if len(carts) > 0 {
+		if err := db.CreateInBatches(&carts, 500).Error; err != nil {
+			return f, err
+		}
+	}
+	if err := db.CreateInBatches(&views, 500).Error; err != nil {
+		return f, err
+	}
+	for index, quantity := range sold {
+		if err := db.Model(&models.Product{}).Where("id = ?", products[index].ID).
+			Update("total_sold", quantity).Error; err != nil {
+			return f, err
+		}
+	}
+
+	log.Printf("Generated %d sellers, %d products, %d users, %d purchases, %d cart items, %d views",
+		len(sellers), len(products), len(users), len(histories), len(carts), len(views))
+	return f, nil
+}
+
+// cleanupSynthetic - menghapus semua data sintetis, anak dulu baru induknya
+func cleanupSynthetic(db *gorm.DB, f syntheticFixture) {
+	steps := []struct {
+		model interface{}
+		query string
+		ids   []uint
+	}{
+		{&models.ProductView{}, "user_id IN ?", f.userIDs},
+		{&models.ProductView{}, "product_id IN ?", f.productIDs},
+		{&models.ProductUserCart{}, "user_id IN ?", f.userIDs},
+		{&models.ProductUserHistory{}, "user_id IN ?", f.userIDs},
+		{&models.ProductNeighbor{}, "product_id IN ?", f.productIDs},
+		{&models.ProductNeighbor{}, "neighbor_id IN ?", f.productIDs},
+		{&models.TrendingProduct{}, "product_id IN ?", f.productIDs},
+		{&models.Product{}, "id IN ?", f.productIDs},
+		{&models.SellerProfile{}, "seller_id IN ?", f.sellerIDs},
+		{&models.Seller{}, "id IN ?", f.sellerIDs},
+		{&models.UserProfile{}, "user_id IN ?", f.userIDs},
+		{&models.User{}, "id IN ?", f.userIDs},
+	}
+	for _, step := range steps {
+		if len(step.ids) == 0 {
+			continue
+		}
+		if err := db.Where(step.query, step.ids).Delete(step.model).Error; err != nil {
+			log.Printf("cleanup synthetic data failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"ecommerce-golang/money"
+	"log"
+)
+
+// ConnectFXRates - memasang kurs tampilan dari FX_RATES_FILE (JSON {"USD": 16250}) atau
+// FX_RATES ("USD=16250,SGD=12100"); keduanya kosong = kurs indikatif. Konfigurasi yang tidak
+// valid menghentikan startup, supaya kurs tidak diam-diam kembali ke kurs indikatif.
+func ConnectFXRates() {
+	if path := getEnv("FX_RATES_FILE", ""); path != "" {
+		rates, err := money.LoadRates(path)
+		if err != nil {
+			log.Fatalf("Invalid FX_RATES_FILE %s: %v", path, err)
+		}
+		money.SetDefaultRates(money.NewRateTable(rates))
+		return
+	}
+	if env := getEnv("FX_RATES", ""); env != "" {
+		rates, err := money.ParseRates(env)
+		if err != nil {
+			log.Fatalf("Invalid FX_RATES: %v", err)
+		}
+		money.SetDefaultRates(money.NewRateTable(rates))
+	}
+}
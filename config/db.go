@@ -2,6 +2,7 @@ package config
 
 import (
 	"ecommerce-golang/models"
+	"ecommerce-golang/utils"
 	"fmt"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -21,14 +22,44 @@ func ConnectDB() *gorm.DB {
 		getEnv("DB_NAME", "ecommerce"),
 	)
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	// TranslateError supaya pelanggaran unique index bisa dicek dengan gorm.ErrDuplicatedKey
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal("DB connection error:", err)
 	}
 	DB = db
 
+	// Kolom harga lama (DOUBLE) dibulatkan dulu sebelum diubah ke BIGINT rupiah
+	if err := utils.MigrateMoneyColumns(db); err != nil {
+		log.Fatalf("Money column migration failed: %v", err)
+	}
+
+	// SKU kosong harus diisi dulu sebelum unique index (seller_id, sku) dibuat
+	if err := utils.BackfillProductSKUs(db); err != nil {
+		log.Fatalf("Backfill product SKUs failed: %v", err)
+	}
+
 	// Optional: Auto migrate tabel user & seller
-	if err := db.AutoMigrate(
+	if err := db.AutoMigrate(Models()...); err != nil {
+		log.Fatalf("AutoMigrate failed: %v", err)
+	}
+
+	// Produk yang baru mendapat kolom sku dari AutoMigrate di atas
+	if err := utils.BackfillProductSKUs(db); err != nil {
+		log.Printf("Backfill product SKUs failed: %v", err)
+	}
+
+	// Isi dimensi terstruktur untuk produk lama yang hanya punya teks dimensions
+	if err := utils.BackfillProductDimensions(db); err != nil {
+		log.Printf("Backfill product dimensions failed: %v", err)
+	}
+
+	return db
+}
+
+// Models - semua tabel yang dibuat AutoMigrate, urut induk dulu baru anaknya
+func Models() []interface{} {
+	return []interface{}{
 		&models.User{},
 		&models.Seller{},
 		&models.UserProfile{},
@@ -36,11 +67,30 @@ func ConnectDB() *gorm.DB {
 		&models.Product{},
 		&models.ProductUserHistory{},
 		&models.ProductUserCart{},
-	); err != nil {
-		log.Fatalf("AutoMigrate failed: %v", err)
+		&models.SellerAPIKey{},
+		&models.ProductImportJob{},
+		&models.ImageAsset{},
+		&models.UserAddress{},
+		&models.Shipment{},
+		&models.ShipmentEvent{},
+		&models.Voucher{},
+		&models.VoucherRedemption{},
+		&models.FlashSale{},
+		&models.FlashSaleReservation{},
+		&models.TaxRule{},
+		&models.InvoiceSequence{},
+		&models.Invoice{},
+		&models.InvoiceItem{},
+		&models.WishlistCollection{},
+		&models.WishlistItem{},
+		&models.ShopFollow{},
+		&models.GuestCart{},
+		&models.GuestCartItem{},
+		&models.ProductNeighbor{},
+		&models.RecommendationExperiment{},
+		&models.ProductView{},
+		&models.TrendingProduct{},
 	}
-
-	return db
 }
 
 func getEnv(key, fallback string) string {
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"ecommerce-golang/ratelimit"
+	"log"
+	"strconv"
+	"time"
+)
+
+// ConnectLoadShedding - mengaktifkan concurrency limiter global (LoadSheddingMiddleware).
+// LOAD_SHED_ENABLED=false mematikannya. Limit awal/min/max dan panjang antrian diatur lewat
+// LOAD_SHED_INITIAL_LIMIT, LOAD_SHED_MIN_LIMIT, LOAD_SHED_MAX_LIMIT dan LOAD_SHED_MAX_QUEUE;
+// LOAD_SHED_TARGET_LATENCY kosong = target dihitung dari baseline latency yang teramati.
+// Limit berlaku per replica.
+func ConnectLoadShedding() {
+	if getEnv("LOAD_SHED_ENABLED", "true") != "true" {
+		ratelimit.SetActiveConcurrencyLimiter(nil)
+		return
+	}
+
+	limiterConfig := ratelimit.DefaultConcurrencyConfig()
+	limiterConfig.InitialLimit = envInt("LOAD_SHED_INITIAL_LIMIT", limiterConfig.InitialLimit)
+	limiterConfig.MinLimit = envInt("LOAD_SHED_MIN_LIMIT", limiterConfig.MinLimit)
+	limiterConfig.MaxLimit = envInt("LOAD_SHED_MAX_LIMIT", limiterConfig.MaxLimit)
+	limiterConfig.MaxQueue = envInt("LOAD_SHED_MAX_QUEUE", 0)
+	if target := getEnv("LOAD_SHED_TARGET_LATENCY", ""); target != "" {
+		latency, err := time.ParseDuration(target)
+		if err != nil {
+			log.Printf("Invalid LOAD_SHED_TARGET_LATENCY, using adaptive target: %v", err)
+		} else {
+			limiterConfig.TargetLatency = latency
+		}
+	}
+
+	ratelimit.SetActiveConcurrencyLimiter(ratelimit.NewConcurrencyLimiter(limiterConfig, nil))
+}
+
+func envInt(key string, fallback int) int {
+	raw := getEnv(key, "")
+	if raw == "" {
+		return fallback
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		log.Printf("Invalid %s, using %d", key, fallback)
+		return fallback
+	}
+	return value
+}
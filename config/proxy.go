@@ -0,0 +1,36 @@
+package config
+
+import (
+	"github.com/gin-gonic/gin"
+	"log"
+	"strings"
+)
+
+// ConfigureTrustedProxies - proxy / load balancer yang boleh menentukan IP client lewat
+// X-Forwarded-For / X-Real-IP. TRUSTED_PROXIES berisi IP atau CIDR dipisah koma; kosong = tidak
+// ada proxy yang dipercaya sehingga c.ClientIP() selalu alamat koneksi dan header tersebut tidak
+// bisa dipakai client untuk memalsukan IP (rate limit, allowlist/denylist).
+// TRUSTED_PLATFORM=cloudflare / google-app-engine / fly-io memakai header IP dari platform tersebut.
+func ConfigureTrustedProxies(r *gin.Engine) {
+	var proxies []string
+	for _, proxy := range strings.Split(getEnv("TRUSTED_PROXIES", ""), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+
+	switch platform := getEnv("TRUSTED_PLATFORM", ""); platform {
+	case "":
+	case "cloudflare":
+		r.TrustedPlatform = gin.PlatformCloudflare
+	case "google-app-engine":
+		r.TrustedPlatform = gin.PlatformGoogleAppEngine
+	case "fly-io":
+		r.TrustedPlatform = gin.PlatformFlyIO
+	default:
+		log.Fatal("Unknown TRUSTED_PLATFORM, use cloudflare, google-app-engine or fly-io")
+	}
+}
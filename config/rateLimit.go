@@ -0,0 +1,61 @@
+package config
+
+import (
+	"ecommerce-golang/ratelimit"
+	"log"
+	"time"
+)
+
+// ConnectLimiterStore - memilih store rate limiter berdasarkan RATE_LIMIT_STORE (memory atau redis).
+// Pakai redis jika API dijalankan lebih dari satu replica, supaya limit dibagi bersama.
+// Store yang menyimpan key di memori dibersihkan dari key kedaluwarsa setiap menit.
+func ConnectLimiterStore() ratelimit.Store {
+	var store ratelimit.Store
+	switch getEnv("RATE_LIMIT_STORE", "memory") {
+	case "memory":
+		store = ratelimit.NewMemoryStore()
+	case "redis":
+		redisConfig, err := ratelimit.ParseRedisURL(getEnv("REDIS_URL", "redis://127.0.0.1:6379/0"))
+		if err != nil {
+			log.Fatal("Invalid REDIS_URL:", err)
+		}
+		redisConfig.Prefix = getEnv("RATE_LIMIT_REDIS_PREFIX", "ratelimit:")
+		store, err = ratelimit.NewRedisStore(redisConfig)
+		if err != nil {
+			log.Fatal("Rate limiter store init error:", err)
+		}
+	default:
+		log.Fatal("Unknown RATE_LIMIT_STORE, use memory or redis")
+	}
+
+	if cleaner, ok := store.(ratelimit.Cleaner); ok {
+		go func() {
+			ticker := time.NewTicker(time.Minute)
+			defer ticker.Stop()
+
+			for now := range ticker.C {
+				cleaner.Cleanup(now)
+			}
+		}()
+	}
+	return store
+}
+
+// ConnectRateLimitPolicies - memuat policy rate limit dari RATE_LIMIT_POLICY_FILE (kosong = policy
+// bawaan) dan mengaktifkannya. File diperiksa setiap RATE_LIMIT_POLICY_RELOAD_INTERVAL dan dimuat
+// ulang jika berubah; file yang tidak valid dilewati dan policy lama tetap dipakai.
+func ConnectRateLimitPolicies(store ratelimit.Store) {
+	path := getEnv("RATE_LIMIT_POLICY_FILE", "")
+	registry, err := ratelimit.NewPolicyRegistry(path, store, nil)
+	if err != nil {
+		log.Fatal("Rate limit policy error:", err)
+	}
+
+	interval, err := time.ParseDuration(getEnv("RATE_LIMIT_POLICY_RELOAD_INTERVAL", "10s"))
+	if err != nil {
+		log.Printf("Invalid RATE_LIMIT_POLICY_RELOAD_INTERVAL, using 10s: %v", err)
+		interval = 10 * time.Second
+	}
+	registry.Watch(interval)
+	ratelimit.SetActivePolicies(registry)
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"ecommerce-golang/storage"
+	"log"
+)
+
+var Storage storage.Storage
+
+// ConnectStorage - memilih storage berdasarkan STORAGE_DRIVER (local atau s3)
+func ConnectStorage() storage.Storage {
+	var store storage.Storage
+	var err error
+
+	switch getEnv("STORAGE_DRIVER", "local") {
+	case "s3":
+		store, err = storage.NewS3Storage(storage.S3Config{
+			Endpoint:     getEnv("S3_ENDPOINT", ""),
+			Region:       getEnv("S3_REGION", "us-east-1"),
+			Bucket:       getEnv("S3_BUCKET", ""),
+			AccessKey:    getEnv("S3_ACCESS_KEY", ""),
+			SecretKey:    getEnv("S3_SECRET_KEY", ""),
+			PublicURL:    getEnv("S3_PUBLIC_URL", ""),
+			UsePathStyle: getEnv("S3_USE_PATH_STYLE", "false") == "true",
+		})
+	case "local":
+		store, err = storage.NewLocalStorage(
+			getEnv("STORAGE_LOCAL_DIR", "./uploads"),
+			getEnv("STORAGE_PUBLIC_URL", "/uploads"),
+		)
+	default:
+		log.Fatal("Unknown STORAGE_DRIVER, use local or s3")
+	}
+	if err != nil {
+		log.Fatal("Storage init error:", err)
+	}
+
+	Storage = store
+	return store
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"ecommerce-golang/shipping"
+	"log"
+	"time"
+)
+
+// ConnectShippingRates - memasang tabel tarif ongkir dari SHIPPING_RATES_FILE (kosong = tarif
+// bawaan). File yang tidak bisa dibaca atau tidak valid menghentikan startup, supaya tarif tidak
+// diam-diam kembali ke tabel bawaan.
+func ConnectShippingRates() []shipping.Rate {
+	path := getEnv("SHIPPING_RATES_FILE", "")
+	if path == "" {
+		return shipping.DefaultRates()
+	}
+	rates, err := shipping.LoadTableRates(path)
+	if err != nil {
+		log.Fatalf("Invalid SHIPPING_RATES_FILE %s: %v", path, err)
+	}
+	shipping.SetDefaultProvider(shipping.NewTableRateProvider(rates))
+	return rates
+}
+
+// ConnectTrackers - mendaftarkan tracker kurir. Kurir yang ada di tabel tarif menerima
+// webhook ternormalisasi yang ditandatangani TRACKING_WEBHOOK_SECRET.
+func ConnectTrackers(rates []shipping.Rate) {
+	secret := getEnv("TRACKING_WEBHOOK_SECRET", "")
+	registered := make(map[string]bool)
+	for _, rate := range rates {
+		if registered[rate.Courier] {
+			continue
+		}
+		registered[rate.Courier] = true
+		shipping.RegisterTracker(&shipping.WebhookTracker{CourierName: rate.Courier, Secret: secret})
+	}
+
+	// Kurir "fake" untuk development lokal, jangan diaktifkan di production
+	if getEnv("TRACKING_ENABLE_FAKE", "false") == "true" {
+		shipping.RegisterTracker(shipping.NewFakeTracker())
+	}
+}
+
+// ShipmentAutoCompleteGrace - jeda setelah delivered sebelum shipment otomatis completed
+func ShipmentAutoCompleteGrace() time.Duration {
+	grace, err := time.ParseDuration(getEnv("SHIPMENT_AUTO_COMPLETE_GRACE", "72h"))
+	if err != nil {
+		log.Printf("Invalid SHIPMENT_AUTO_COMPLETE_GRACE, using 72h: %v", err)
+		return 72 * time.Hour
+	}
+	return grace
+}
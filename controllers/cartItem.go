@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+)
+
+// findUserCartItem - baris keranjang milik user, response 404/500 sudah dikirim jika gagal
+func findUserCartItem(c *gin.Context, db *gorm.DB, userID uint) (models.ProductUserCart, bool) {
+	var cartItem models.ProductUserCart
+	err := db.Where("id = ? AND user_id = ?", c.Param("cart_id"), userID).First(&cartItem).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Cart item not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		}
+		return cartItem, false
+	}
+	return cartItem, true
+}
+
+// SaveCartItemForLater - memindahkan item dari keranjang aktif ke daftar "simpan untuk nanti"
+func SaveCartItemForLater(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	cartItem, ok := findUserCartItem(c, db, userID)
+	if !ok {
+		return
+	}
+
+	if err := db.Model(&cartItem).Update("saved_for_later", true).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart item"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Item saved for later",
+		"data":    cartItem,
+	})
+}
+
+// MoveCartItemToCart - mengembalikan item yang disimpan ke keranjang aktif (dicentang),
+// dengan cek produk dan stok yang sama seperti AddProductToCart
+func MoveCartItemToCart(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	cartItem, ok := findUserCartItem(c, db, userID)
+	if !ok {
+		return
+	}
+
+	var product models.Product
+	if err := db.First(&product, "id = ? AND is_active = ?", cartItem.ProductID, true).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found atau tidak aktif"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		}
+		return
+	}
+
+	if product.Stock < cartItem.Quantity {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":              "Insufficient stock",
+			"available_stock":    product.Stock,
+			"requested_quantity": cartItem.Quantity,
+		})
+		return
+	}
+
+	if err := db.Model(&cartItem).Updates(map[string]interface{}{
+		"saved_for_later": false,
+		"selected":        true,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart item"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Item moved to cart",
+		"data":    cartItem,
+	})
+}
+
+// UpdateCartItemNote - catatan untuk seller, ikut disalin ke item pesanan. Note kosong menghapus catatan.
+func UpdateCartItemNote(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	var input struct {
+		Note string `json:"note" binding:"max=500"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cartItem, ok := findUserCartItem(c, db, userID)
+	if !ok {
+		return
+	}
+
+	if err := db.Model(&cartItem).Update("note", strings.TrimSpace(input.Note)).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart item"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cart item note updated",
+		"data":    cartItem,
+	})
+}
+
+// UpdateCartSelection - mencentang / menghapus centang item keranjang aktif.
+// cart_ids kosong berarti semua item, total di GetUserCart hanya menghitung item yang dicentang.
+func UpdateCartSelection(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	var input struct {
+		CartIDs  []uint `json:"cart_ids"`
+		Selected *bool  `json:"selected" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	query := db.Model(&models.ProductUserCart{}).
+		Where("user_id = ? AND saved_for_later = ?", userID, false)
+	if len(input.CartIDs) > 0 {
+		query = query.Where("id IN ?", input.CartIDs)
+	}
+
+	result := query.Update("selected", *input.Selected)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart selection"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cart selection updated",
+		"data": gin.H{
+			"selected": *input.Selected,
+			"updated":  result.RowsAffected,
+		},
+	})
+}
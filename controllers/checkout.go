@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"ecommerce-golang/shipping"
+	"ecommerce-golang/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type checkoutShippingInput struct {
+	SellerID uint   `json:"seller_id" binding:"required"`
+	Courier  string `json:"courier" binding:"required"`
+	Service  string `json:"service" binding:"required"`
+}
+
+// Checkout - membuat pesanan dari item keranjang yang dicentang. Ongkir tidak diambil dari
+// request, harga dicari ulang dari opsi GetCartShippingQuotes sesuai kurir/layanan yang dipilih.
+func Checkout(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	var input struct {
+		AddressID uint                    `json:"address_id"`
+		Shipping  []checkoutShippingInput `json:"shipping" binding:"required,min=1,dive"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	destination, ok := userDestination(c, db, userID, input.AddressID)
+	if !ok {
+		return
+	}
+	quotes, err := utils.GetCartShippingQuotes(c.Request.Context(), db, shipping.DefaultProvider(), userID, destination.City)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get shipping quotes"})
+		return
+	}
+
+	prices := make(map[uint]int64, len(input.Shipping))
+	for _, selected := range input.Shipping {
+		price, found := selectedShippingPrice(quotes, selected)
+		if !found {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":     "Shipping option not available",
+				"seller_id": selected.SellerID,
+			})
+			return
+		}
+		prices[selected.SellerID] = price
+	}
+
+	items, result, err := utils.PlaceOrder(db, userID, prices, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrCartEmpty), errors.Is(err, utils.ErrShippingNotSelected):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, utils.ErrOutOfStock), errors.Is(err, utils.ErrFlashSaleExpired):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, utils.ErrVoucherNotUsable):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Checkout failed"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Order placed",
+		"data": gin.H{
+			"items":   items,
+			"summary": result,
+		},
+	})
+}
+
+func selectedShippingPrice(quotes []utils.SellerShippingQuote, selected checkoutShippingInput) (int64, bool) {
+	for _, quote := range quotes {
+		if quote.SellerID != selected.SellerID {
+			continue
+		}
+		for _, option := range quote.Options {
+			if strings.EqualFold(option.Courier, selected.Courier) && strings.EqualFold(option.Service, selected.Service) {
+				return option.Price.Amount, true
+			}
+		}
+	}
+	return 0, false
+}
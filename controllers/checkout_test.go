@@ -0,0 +1,382 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/money"
+	"ecommerce-golang/pricing"
+	"ecommerce-golang/shipping"
+	"ecommerce-golang/utils"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type checkoutFixture struct {
+	db       *gorm.DB
+	userID   uint
+	sellerID uint
+	regular  models.Product
+	flash    models.Product
+	sale     models.FlashSale
+}
+
+// newCheckoutFixture - satu seller dan pembeli di kota yang sama, satu produk biasa
+// dan satu produk yang sedang flash sale
+func newCheckoutFixture(t *testing.T) checkoutFixture {
+	t.Helper()
+	db := newTestDB(t)
+	seller := models.Seller{Email: "seller@example.com", Username: "seller"}
+	user := models.User{Email: "buyer@example.com", Username: "buyer"}
+	db.Create(&seller)
+	db.Create(&user)
+	db.Create(&models.SellerProfile{SellerID: seller.ID, ShopName: "Toko", City: "Kota Bandung"})
+	db.Create(&models.UserAddress{UserID: user.ID, RecipientName: "Buyer", Phone: "0812", Province: "Jawa Barat",
+		City: "Kota Bandung", District: "Coblong", PostalCode: "40132", IsDefault: true})
+
+	f := checkoutFixture{db: db, userID: user.ID, sellerID: seller.ID}
+	f.regular = models.Product{SellerID: seller.ID, Name: "Kaos", Price: money.IDR(50000), Stock: 5, Weight: 200, IsActive: true}
+	f.flash = models.Product{SellerID: seller.ID, Name: "Sepatu", Price: money.IDR(500000), Stock: 10, Weight: 200, IsActive: true}
+	for _, product := range []*models.Product{&f.regular, &f.flash} {
+		if err := db.Create(product).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	f.sale = models.FlashSale{ProductID: f.flash.ID, SellerID: seller.ID, SalePrice: money.IDR(99000), Quota: 5,
+		StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Hour), IsActive: true}
+	if err := db.Create(&f.sale).Error; err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func (f checkoutFixture) addToCart(t *testing.T, product models.Product, quantity uint) {
+	t.Helper()
+	item := models.ProductUserCart{UserID: f.userID, ProductID: product.ID, Quantity: quantity, Note: "warna hitam"}
+	if err := f.db.Create(&item).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (f checkoutFixture) checkout(t *testing.T, body gin.H) *httptest.ResponseRecorder {
+	t.Helper()
+	return performRequest(f.db, Checkout, http.MethodPost, "/user/checkout", mustJSON(t, body), nil, gin.H{"id": f.userID})
+}
+
+func (f checkoutFixture) jneREG() gin.H {
+	return gin.H{"shipping": []gin.H{{"seller_id": f.sellerID, "courier": "JNE", "service": "REG"}}}
+}
+
+func TestCheckout(t *testing.T) {
+	f := newCheckoutFixture(t)
+	f.addToCart(t, f.regular, 2)
+	// 2 dari 3 sepatu mendapat harga flash sale
+	if _, err := utils.ReserveFlashSale(f.db, f.sale.ID, f.userID, 2, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	f.addToCart(t, f.flash, 3)
+
+	w := f.checkout(t, f.jneREG())
+	if w.Code != http.StatusOK {
+		t.Fatalf("checkout = %d: %s", w.Code, w.Body)
+	}
+
+	var items []models.ProductUserHistory
+	f.db.Order("id ASC").Find(&items)
+	want := []struct {
+		productID uint
+		quantity  uint
+		price     int64
+	}{
+		{f.regular.ID, 2, 50000},
+		{f.flash.ID, 2, 99000},
+		{f.flash.ID, 1, 500000},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("order items = %d, want %d", len(items), len(want))
+	}
+	for i, item := range items {
+		if item.ProductID != want[i].productID || item.Quantity != want[i].quantity || item.Price.Amount != want[i].price {
+			t.Errorf("item %d = product %d x%d @%d, want product %d x%d @%d", i,
+				item.ProductID, item.Quantity, item.Price.Amount, want[i].productID, want[i].quantity, want[i].price)
+		}
+		if item.Note != "warna hitam" {
+			t.Errorf("item %d note = %q", i, item.Note)
+		}
+	}
+
+	var regular, flash models.Product
+	f.db.First(&regular, f.regular.ID)
+	f.db.First(&flash, f.flash.ID)
+	if regular.Stock != 3 || regular.TotalSold != 2 || flash.Stock != 7 || flash.TotalSold != 3 {
+		t.Errorf("stock/sold = %d/%d and %d/%d, want 3/2 and 7/3", regular.Stock, regular.TotalSold, flash.Stock, flash.TotalSold)
+	}
+
+	var reservation models.FlashSaleReservation
+	f.db.Where("user_id = ?", f.userID).First(&reservation)
+	if reservation.Status != models.FlashSalePurchased || reservation.Quantity != 2 {
+		t.Errorf("reservation = %s x%d, want purchased x2", reservation.Status, reservation.Quantity)
+	}
+
+	var cartItems int64
+	f.db.Model(&models.ProductUserCart{}).Where("user_id = ?", f.userID).Count(&cartItems)
+	if cartItems != 0 {
+		t.Errorf("cart items after checkout = %d, want 0", cartItems)
+	}
+}
+
+// Reservasi lebih banyak dari quantity yang dibeli: sisanya kembali ke kuota
+func TestCheckoutReleasesUnusedFlashSaleQuota(t *testing.T) {
+	f := newCheckoutFixture(t)
+	if _, err := utils.ReserveFlashSale(f.db, f.sale.ID, f.userID, 3, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	f.addToCart(t, f.flash, 1)
+
+	if w := f.checkout(t, f.jneREG()); w.Code != http.StatusOK {
+		t.Fatalf("checkout = %d: %s", w.Code, w.Body)
+	}
+	var sale models.FlashSale
+	f.db.First(&sale, f.sale.ID)
+	if sale.Sold != 1 {
+		t.Errorf("flash sale sold = %d, want 1", sale.Sold)
+	}
+}
+
+func TestCheckoutRejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity uint
+		shipping func(f checkoutFixture) gin.H
+		wantCode int
+	}{
+		{"stok tidak cukup", 6, checkoutFixture.jneREG, http.StatusConflict},
+		{"layanan kurir tidak ada", 1, func(f checkoutFixture) gin.H {
+			return gin.H{"shipping": []gin.H{{"seller_id": f.sellerID, "courier": "JNE", "service": "KILAT"}}}
+		}, http.StatusBadRequest},
+		{"seller tidak ada di keranjang", 1, func(f checkoutFixture) gin.H {
+			return gin.H{"shipping": []gin.H{{"seller_id": f.sellerID + 1, "courier": "JNE", "service": "REG"}}}
+		}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newCheckoutFixture(t)
+			f.addToCart(t, f.regular, tt.quantity)
+
+			if w := f.checkout(t, tt.shipping(f)); w.Code != tt.wantCode {
+				t.Fatalf("checkout = %d, want %d: %s", w.Code, tt.wantCode, w.Body)
+			}
+
+			// Tidak ada yang berubah: transaksi di-rollback
+			var items, cartItems int64
+			f.db.Model(&models.ProductUserHistory{}).Count(&items)
+			f.db.Model(&models.ProductUserCart{}).Count(&cartItems)
+			var product models.Product
+			f.db.First(&product, f.regular.ID)
+			if items != 0 || cartItems != 1 || product.Stock != 5 {
+				t.Errorf("items = %d, cart = %d, stock = %d, want 0, 1, 5", items, cartItems, product.Stock)
+			}
+		})
+	}
+}
+
+func TestCheckoutEmptyCart(t *testing.T) {
+	f := newCheckoutFixture(t)
+	if w := f.checkout(t, f.jneREG()); w.Code != http.StatusBadRequest {
+		t.Errorf("checkout empty cart = %d, want 400", w.Code)
+	}
+}
+
+func (f checkoutFixture) createVoucher(t *testing.T, code string, minSpend int64) models.Voucher {
+	t.Helper()
+	now := time.Now()
+	voucher := models.Voucher{Code: code, Name: code, Type: models.VoucherFixed, Value: 10000, MinSpend: money.IDR(minSpend),
+		Scope: models.VoucherScopePlatform, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour), UsageLimit: 10, IsActive: true}
+	if err := f.db.Create(&voucher).Error; err != nil {
+		t.Fatal(err)
+	}
+	return voucher
+}
+
+func TestCheckoutRedeemsVoucher(t *testing.T) {
+	f := newCheckoutFixture(t)
+	f.addToCart(t, f.regular, 2)
+	voucher := f.createVoucher(t, "HEMAT10", 0)
+	if _, _, err := utils.ApplyVoucherToCart(f.db, f.userID, voucher.Code, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	w := f.checkout(t, f.jneREG())
+	if w.Code != http.StatusOK {
+		t.Fatalf("checkout = %d: %s", w.Code, w.Body)
+	}
+
+	var redemption models.VoucherRedemption
+	f.db.Where("user_id = ?", f.userID).First(&redemption)
+	if redemption.Status != models.RedemptionRedeemed || redemption.ExpiresAt != nil {
+		t.Errorf("redemption = %s (expires %v), want redeemed without expiry", redemption.Status, redemption.ExpiresAt)
+	}
+	// Klaim yang sudah ditebus tidak ikut dilepas cleanup
+	if released, err := utils.ReleaseExpiredVoucherClaims(f.db, time.Now().Add(utils.VoucherClaimTTL+time.Minute)); err != nil || released != 0 {
+		t.Errorf("released = %d, %v; want 0", released, err)
+	}
+	f.db.First(&voucher, voucher.ID)
+	if voucher.UsedCount != 1 {
+		t.Errorf("used count = %d, want 1", voucher.UsedCount)
+	}
+}
+
+// Voucher yang sudah tidak memenuhi syarat keranjang membatalkan checkout, bukan diabaikan
+func TestCheckoutRejectsUnusableVoucher(t *testing.T) {
+	f := newCheckoutFixture(t)
+	f.addToCart(t, f.regular, 2)
+	voucher := f.createVoucher(t, "MIN100K", 100000)
+	if _, _, err := utils.ApplyVoucherToCart(f.db, f.userID, voucher.Code, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	f.db.Model(&models.ProductUserCart{}).Where("user_id = ?", f.userID).Update("quantity", 1)
+
+	if w := f.checkout(t, f.jneREG()); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("checkout = %d, want 422: %s", w.Code, w.Body)
+	}
+	var redemption models.VoucherRedemption
+	f.db.Where("user_id = ?", f.userID).First(&redemption)
+	if redemption.Status != models.RedemptionApplied {
+		t.Errorf("redemption = %s, want applied", redemption.Status)
+	}
+}
+
+// Klaim voucher yang ditinggal di keranjang dilepas setelah VoucherClaimTTL dan kuotanya kembali
+func TestReleaseExpiredVoucherClaims(t *testing.T) {
+	f := newCheckoutFixture(t)
+	f.addToCart(t, f.regular, 2)
+	voucher := f.createVoucher(t, "HEMAT10", 0)
+	now := time.Now()
+	if _, _, err := utils.ApplyVoucherToCart(f.db, f.userID, voucher.Code, now); err != nil {
+		t.Fatal(err)
+	}
+
+	if released, err := utils.ReleaseExpiredVoucherClaims(f.db, now.Add(utils.VoucherClaimTTL-time.Minute)); err != nil || released != 0 {
+		t.Fatalf("before TTL released = %d, %v; want 0", released, err)
+	}
+	expired := now.Add(utils.VoucherClaimTTL + time.Minute)
+	if applied, err := utils.GetAppliedVoucher(f.db, f.userID, expired); err != nil || applied != nil {
+		t.Errorf("applied voucher after TTL = %v, %v; want nil", applied, err)
+	}
+	if released, err := utils.ReleaseExpiredVoucherClaims(f.db, expired); err != nil || released != 1 {
+		t.Fatalf("after TTL released = %d, %v; want 1", released, err)
+	}
+
+	var claims int64
+	f.db.Model(&models.VoucherRedemption{}).Count(&claims)
+	f.db.First(&voucher, voucher.ID)
+	if claims != 0 || voucher.UsedCount != 0 {
+		t.Errorf("claims = %d, used count = %d, want 0 and 0", claims, voucher.UsedCount)
+	}
+}
+
+// Klaim yang diperpanjang user setelah dibaca cleanup tidak boleh ikut dilepas
+func TestReleaseExpiredVoucherClaimsSkipsRenewedClaim(t *testing.T) {
+	f := newCheckoutFixture(t)
+	f.addToCart(t, f.regular, 2)
+	voucher := f.createVoucher(t, "HEMAT10", 0)
+	now := time.Now()
+	if _, _, err := utils.ApplyVoucherToCart(f.db, f.userID, voucher.Code, now); err != nil {
+		t.Fatal(err)
+	}
+	expired := now.Add(utils.VoucherClaimTTL + time.Minute)
+
+	// Voucher yang sama dipasang ulang tepat setelah cleanup membaca klaim kedaluwarsa
+	renewed := false
+	err := f.db.Callback().Query().After("gorm:query").Register("test:renew_claim", func(tx *gorm.DB) {
+		if renewed || tx.Statement.Table != "voucher_redemptions" {
+			return
+		}
+		renewed = true
+		if _, _, err := utils.ApplyVoucherToCart(f.db, f.userID, voucher.Code, expired); err != nil {
+			t.Error(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if released, err := utils.ReleaseExpiredVoucherClaims(f.db, expired); err != nil || released != 0 {
+		t.Fatalf("released = %d, %v; want 0", released, err)
+	}
+	if !renewed {
+		t.Fatal("claim was not renewed during cleanup")
+	}
+	if applied, err := utils.GetAppliedVoucher(f.db, f.userID, expired); err != nil || applied == nil {
+		t.Errorf("applied voucher = %v, %v; want renewed claim", applied, err)
+	}
+	f.db.First(&voucher, voucher.ID)
+	if voucher.UsedCount != 1 {
+		t.Errorf("used count = %d, want 1", voucher.UsedCount)
+	}
+}
+
+// Invoice shipment memakai rincian harga checkout: potongan voucher, ongkir dan PPN
+func TestInvoiceUsesCheckoutPricing(t *testing.T) {
+	f := newCheckoutFixture(t)
+	f.db.Model(&models.SellerProfile{}).Where("seller_id = ?", f.sellerID).Update("is_pkp", true)
+	tracker := shipping.NewFakeTracker()
+	shipping.RegisterTracker(tracker)
+
+	f.addToCart(t, f.regular, 2)
+	if _, err := utils.ReserveFlashSale(f.db, f.sale.ID, f.userID, 1, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	f.addToCart(t, f.flash, 2)
+	voucher := f.createVoucher(t, "HEMAT10", 0)
+	if _, _, err := utils.ApplyVoucherToCart(f.db, f.userID, voucher.Code, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	w := f.checkout(t, f.jneREG())
+	if w.Code != http.StatusOK {
+		t.Fatalf("checkout = %d: %s", w.Code, w.Body)
+	}
+	var response struct {
+		Data struct {
+			Items   []models.ProductUserHistory `json:"items"`
+			Summary pricing.Result              `json:"summary"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	summary := response.Data.Summary
+	if summary.Discount.Amount != 10000 || summary.Shipping.Amount == 0 || summary.Tax.Amount == 0 {
+		t.Fatalf("summary discount/shipping/tax = %d/%d/%d", summary.Discount.Amount, summary.Shipping.Amount, summary.Tax.Amount)
+	}
+
+	var historyIDs []uint
+	for _, item := range response.Data.Items {
+		historyIDs = append(historyIDs, item.ID)
+	}
+	body := mustJSON(t, gin.H{"history_ids": historyIDs, "courier": "fake", "awb": "FAKE100"})
+	if w := performRequest(f.db, CreateShipment, http.MethodPost, "/seller/shipments", body, nil, gin.H{"id": f.sellerID}); w.Code != http.StatusOK {
+		t.Fatalf("create shipment = %d: %s", w.Code, w.Body)
+	}
+
+	var inv models.Invoice
+	if err := f.db.Preload("Items").First(&inv).Error; err != nil {
+		t.Fatal(err)
+	}
+	got := []int64{inv.Subtotal.Amount, inv.Discount.Amount, inv.Shipping.Amount, inv.ShippingDiscount.Amount, inv.Tax.Amount, inv.TaxIncluded.Amount, inv.Total.Amount}
+	want := []int64{summary.Subtotal.Amount, summary.Discount.Amount, summary.Shipping.Amount, summary.ShippingDiscount.Amount, summary.Tax.Amount, summary.TaxIncluded.Amount, summary.GrandTotal.Amount}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("invoice subtotal/discount/shipping/shipping discount/tax/tax included/total = %v, want %v", got, want)
+			break
+		}
+	}
+	if len(inv.Items) != 3 {
+		t.Errorf("invoice items = %d, want 3 (kaos, sepatu flash sale, sepatu)", len(inv.Items))
+	}
+}
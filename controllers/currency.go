@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"ecommerce-golang/money"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// GetCurrencies - mata uang tampilan yang didukung beserta kursnya (rupiah per 1 unit)
+func GetCurrencies(c *gin.Context) {
+	rates := money.DefaultRates()
+
+	data := make([]gin.H, 0)
+	for _, code := range rates.Currencies() {
+		rate, _ := rates.Rate(code)
+		currency := money.LookupCurrency(code)
+		data = append(data, gin.H{
+			"code":     code,
+			"symbol":   currency.Symbol,
+			"exponent": currency.Exponent,
+			"rate":     rate,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Mata uang berhasil diambil",
+		"settlement": money.Settlement,
+		"data":       data,
+	})
+}
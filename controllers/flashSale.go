@@ -0,0 +1,241 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/money"
+	"ecommerce-golang/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type flashSaleInput struct {
+	ProductID    uint      `json:"product_id" binding:"required"`
+	SalePrice    float64   `json:"sale_price" binding:"required"`
+	Quota        uint      `json:"quota" binding:"required"`
+	PerUserLimit uint      `json:"per_user_limit"`
+	StartsAt     time.Time `json:"starts_at" binding:"required"`
+	EndsAt       time.Time `json:"ends_at" binding:"required"`
+}
+
+// CreateSellerFlashSale - seller menjadwalkan flash sale untuk produknya sendiri
+func CreateSellerFlashSale(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var input flashSaleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var product models.Product
+	if err := db.Where("id = ? AND seller_id = ?", input.ProductID, sellerID).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	saveFlashSale(c, db, input, product)
+}
+
+// CreateAdminFlashSale - flash sale dari platform untuk produk seller manapun
+func CreateAdminFlashSale(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	var input flashSaleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var product models.Product
+	if err := db.First(&product, input.ProductID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	saveFlashSale(c, db, input, product)
+}
+
+func saveFlashSale(c *gin.Context, db *gorm.DB, input flashSaleInput, product models.Product) {
+	sale := models.FlashSale{
+		ProductID:    product.ID,
+		SellerID:     product.SellerID,
+		SalePrice:    money.FromFloat(input.SalePrice),
+		Quota:        input.Quota,
+		PerUserLimit: input.PerUserLimit,
+		StartsAt:     input.StartsAt,
+		EndsAt:       input.EndsAt,
+		IsActive:     true,
+	}
+
+	if err := utils.ValidateFlashSale(db, sale, product); err != nil {
+		if errors.Is(err, utils.ErrFlashSaleOverlap) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if err := db.Create(&sale).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat flash sale"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Flash sale created",
+		"data":    sale,
+	})
+}
+
+func GetSellerFlashSales(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var sales []models.FlashSale
+	if err := db.Where("seller_id = ?", sellerID).Order("starts_at DESC").Find(&sales).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil flash sale"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Flash sale berhasil diambil",
+		"data":    sales,
+		"total":   len(sales),
+	})
+}
+
+// CancelSellerFlashSale - menonaktifkan flash sale, reservasi yang ada tetap kedaluwarsa sendiri
+func CancelSellerFlashSale(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	result := db.Model(&models.FlashSale{}).
+		Where("id = ? AND seller_id = ?", c.Param("flash_sale_id"), sellerID).
+		Update("is_active", false)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membatalkan flash sale"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Flash sale not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Flash sale cancelled"})
+}
+
+// GetFlashSales - flash sale yang sedang berjalan dan yang dimulai dalam 24 jam ke depan
+func GetFlashSales(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	now := time.Now()
+
+	var sales []models.FlashSale
+	err := db.Preload("Product").
+		Joins("JOIN products ON flash_sales.product_id = products.id").
+		Where("flash_sales.is_active = ? AND products.is_active = ?", true, true).
+		Where("flash_sales.ends_at > ? AND flash_sales.starts_at <= ?", now, now.Add(24*time.Hour)).
+		Order("flash_sales.starts_at ASC").
+		Find(&sales).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil flash sale"})
+		return
+	}
+
+	data := make([]gin.H, 0, len(sales))
+	for _, sale := range sales {
+		image := ""
+		if len(sale.Product.Images) > 0 {
+			image = utils.ThumbnailURL(sale.Product.Images[0])
+		}
+		data = append(data, gin.H{
+			"id":             sale.ID,
+			"product_id":     sale.ProductID,
+			"product_name":   sale.Product.Name,
+			"product_image":  image,
+			"original_price": sale.Product.Price,
+			"sale_price":     sale.SalePrice,
+			"quota":          sale.Quota,
+			"remaining":      sale.Remaining(),
+			"per_user_limit": sale.PerUserLimit,
+			"starts_at":      sale.StartsAt,
+			"ends_at":        sale.EndsAt,
+			"is_live":        sale.IsLive(now),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Flash sale berhasil diambil",
+		"data":    data,
+		"total":   len(data),
+	})
+}
+
+// ReserveFlashSale - klaim kuota flash sale lalu masukkan produk ke keranjang.
+// Harga flash sale berlaku di keranjang selama reservasi belum kedaluwarsa.
+func ReserveFlashSale(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	flashSaleID, err := strconv.ParseUint(c.Param("flash_sale_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid flash sale ID"})
+		return
+	}
+
+	var input struct {
+		Quantity uint `json:"quantity" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reservation, err := utils.ReserveFlashSale(db, uint(flashSaleID), userID, input.Quantity, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrFlashSaleNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, utils.ErrFlashSaleSoldOut), errors.Is(err, utils.ErrFlashSaleUserLimit):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case errors.Is(err, utils.ErrFlashSaleNotLive):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal reservasi flash sale"})
+		}
+		return
+	}
+
+	var cartItem models.ProductUserCart
+	err = db.Where("user_id = ? AND product_id = ?", userID, reservation.ProductID).First(&cartItem).Error
+	if err == nil {
+		cartItem.Quantity += reservation.Quantity
+		cartItem.SavedForLater = false
+		cartItem.Selected = true
+		err = db.Save(&cartItem).Error
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		cartItem = models.ProductUserCart{
+			UserID:    userID,
+			ProductID: reservation.ProductID,
+			Quantity:  reservation.Quantity,
+		}
+		err = db.Create(&cartItem).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Flash sale reserved",
+		"data": gin.H{
+			"reservation": reservation,
+			"cart_id":     cartItem.ID,
+			"quantity":    cartItem.Quantity,
+		},
+	})
+}
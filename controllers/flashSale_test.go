@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/money"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newFlashSaleFixture(t *testing.T, quota, perUserLimit uint) (*gorm.DB, models.FlashSale) {
+	t.Helper()
+	db := newTestDB(t)
+	seller := models.Seller{Email: "seller@example.com", Username: "seller"}
+	if err := db.Create(&seller).Error; err != nil {
+		t.Fatal(err)
+	}
+	product := models.Product{SellerID: seller.ID, Name: "Sepatu", Price: money.IDR(500000), Stock: quota, Weight: 800}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	sale := models.FlashSale{
+		ProductID:    product.ID,
+		SellerID:     seller.ID,
+		SalePrice:    money.IDR(99000),
+		Quota:        quota,
+		PerUserLimit: perUserLimit,
+		StartsAt:     now.Add(-time.Minute),
+		EndsAt:       now.Add(time.Hour),
+		IsActive:     true,
+	}
+	if err := db.Create(&sale).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db, sale
+}
+
+func reserveFlashSale(db *gorm.DB, saleID, userID uint, body []byte) int {
+	params := gin.Params{{Key: "flash_sale_id", Value: strconv.FormatUint(uint64(saleID), 10)}}
+	w := performRequest(db, ReserveFlashSale, http.MethodPost, "/user/flash-sales/reserve", body, params, gin.H{"id": userID})
+	return w.Code
+}
+
+func TestReserveFlashSaleInvalidBody(t *testing.T) {
+	db, sale := newFlashSaleFixture(t, 10, 2)
+	user := models.User{Email: "buyer@example.com", Username: "buyer"}
+	db.Create(&user)
+
+	for _, body := range []string{``, `{"quantity": 0}`, `{"quantity": "dua"}`} {
+		if code := reserveFlashSale(db, sale.ID, user.ID, []byte(body)); code != http.StatusBadRequest {
+			t.Errorf("body %q = %d, want 400", body, code)
+		}
+	}
+	var reservations int64
+	db.Model(&models.FlashSaleReservation{}).Count(&reservations)
+	if reservations != 0 {
+		t.Errorf("reservations = %d, want none for invalid requests", reservations)
+	}
+}
+
+// Banyak user berebut kuota bersamaan: kuota tidak boleh terjual lebih dari quota dan
+// tidak ada user yang melewati per_user_limit (versi MySQL: cmd/flashsale-loadtest)
+func TestReserveFlashSaleConcurrentNoOversell(t *testing.T) {
+	const (
+		quota    = 10
+		perUser  = 2
+		users    = 25
+		attempts = 3
+	)
+	db, sale := newFlashSaleFixture(t, quota, perUser)
+
+	userIDs := make([]uint, users)
+	for i := range userIDs {
+		user := models.User{Email: fmt.Sprintf("buyer%d@example.com", i), Username: fmt.Sprintf("buyer%d", i)}
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatal(err)
+		}
+		userIDs[i] = user.ID
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		codes = map[int]int{}
+	)
+	body := []byte(`{"quantity": 1}`)
+	for _, userID := range userIDs {
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func(userID uint) {
+				defer wg.Done()
+				code := reserveFlashSale(db, sale.ID, userID, body)
+				mu.Lock()
+				codes[code]++
+				mu.Unlock()
+			}(userID)
+		}
+	}
+	wg.Wait()
+
+	if codes[http.StatusOK] != quota || codes[http.StatusOK]+codes[http.StatusConflict] != users*attempts {
+		t.Errorf("responses = %v, want %d x 200 and the rest 409", codes, quota)
+	}
+
+	var updated models.FlashSale
+	db.First(&updated, sale.ID)
+	if updated.Sold != quota {
+		t.Errorf("sold = %d, want %d", updated.Sold, quota)
+	}
+
+	var reserved uint
+	db.Model(&models.FlashSaleReservation{}).Select("COALESCE(SUM(quantity), 0)").
+		Where("flash_sale_id = ?", sale.ID).Scan(&reserved)
+	if reserved != updated.Sold {
+		t.Errorf("reserved quantity = %d, sold = %d", reserved, updated.Sold)
+	}
+
+	var overLimit int64
+	db.Model(&models.FlashSaleReservation{}).
+		Select("user_id").Group("user_id").Having("SUM(quantity) > ?", perUser).Count(&overLimit)
+	if overLimit > 0 {
+		t.Errorf("%d users exceeded per_user_limit", overLimit)
+	}
+}
@@ -0,0 +1,352 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/money"
+	"ecommerce-golang/pricing"
+	"ecommerce-golang/utils"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// currentGuestCart - guest cart dari header X-Guest-Cart atau cookie guest_cart.
+// Token yang tidak valid atau sudah kedaluwarsa dianggap tidak ada.
+func currentGuestCart(c *gin.Context, db *gorm.DB) (models.GuestCart, bool) {
+	token := c.GetHeader(utils.GuestCartHeader)
+	if token == "" {
+		token, _ = c.Cookie(utils.GuestCartCookie)
+	}
+	if token == "" {
+		return models.GuestCart{}, false
+	}
+	cart, err := utils.FindGuestCart(db, token, time.Now())
+	return cart, err == nil
+}
+
+func setGuestCartCookie(c *gin.Context, token string) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(utils.GuestCartCookie, token, int(utils.GuestCartTTL().Seconds()), "/", "", c.Request.TLS != nil, true)
+	c.Header(utils.GuestCartHeader, token)
+}
+
+func clearGuestCartCookie(c *gin.Context) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(utils.GuestCartCookie, "", -1, "/", "", c.Request.TLS != nil, true)
+}
+
+// guestLinePrice - harga normal (tanpa flash sale) untuk response tambah/ubah item guest cart
+func guestLinePrice(product models.Product, quantity uint) money.Money {
+	line := pricing.Line{Quantity: int64(quantity), UnitPrice: product.Price.Amount}
+	return money.IDR(line.Subtotal())
+}
+
+func GetGuestCart(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	cart, ok := currentGuestCart(c, db)
+	if !ok {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Cart berhasil diambil",
+			"data":    []interface{}{},
+			"summary": gin.H{"total_items": 0, "item_count": 0},
+		})
+		return
+	}
+
+	var cartItems []struct {
+		models.GuestCartItem
+		ProductName  string      `json:"product_name"`
+		ProductPrice money.Money `json:"product_price"`
+		ProductImage string      `json:"product_image"`
+		ShopName     string      `json:"shop_name"`
+		IsActive     bool        `json:"is_active"`
+		Stock        uint        `json:"stock"`
+		TotalPrice   money.Money `json:"total_price" gorm:"-"`
+
+		Pricing *pricing.LineResult `json:"pricing,omitempty" gorm:"-"`
+	}
+
+	err := db.Table("guest_cart_items").
+		Select(`guest_cart_items.*,
+				products.name as product_name,
+				products.price as product_price,
+				products.images as product_image,
+				products.is_active,
+				products.stock,
+				seller_profiles.shop_name`).
+		Joins("LEFT JOIN products ON guest_cart_items.product_id = products.id").
+		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
+		Where("guest_cart_items.guest_cart_id = ?", cart.ID).
+		Order("guest_cart_items.created_at DESC").
+		Find(&cartItems).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get cart items"})
+		return
+	}
+
+	result, err := utils.PriceGuestCart(db, cart.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to price cart"})
+		return
+	}
+	lines := make(map[uint]*pricing.LineResult, len(result.Lines))
+	for i := range result.Lines {
+		lines[result.Lines[i].CartID] = &result.Lines[i]
+	}
+
+	var totalItems uint = 0
+	for i := range cartItems {
+		if cartItems[i].ProductImage != "" {
+			var imageList []string
+			if json.Unmarshal([]byte(cartItems[i].ProductImage), &imageList) == nil && len(imageList) > 0 {
+				cartItems[i].ProductImage = utils.ThumbnailURL(imageList[0])
+			}
+		}
+
+		if line, ok := lines[cartItems[i].ID]; ok {
+			cartItems[i].Pricing = line
+			cartItems[i].TotalPrice = line.Total
+			totalItems += cartItems[i].Quantity
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cart berhasil diambil",
+		"data":    cartItems,
+		"summary": gin.H{
+			"total_items":  totalItems,
+			"total_price":  result.Subtotal,
+			"item_count":   len(cartItems),
+			"tax":          result.Tax,
+			"tax_included": result.TaxIncluded,
+			"grand_total":  result.GrandTotal,
+			"sellers":      result.Sellers,
+			"expires_at":   cart.ExpiresAt,
+		},
+	})
+}
+
+// AddProductToGuestCart - sama seperti AddProductToCart, guest cart dibuat saat item pertama ditambahkan
+func AddProductToGuestCart(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	productID := c.Param("id")
+
+	var input struct {
+		Quantity uint `json:"quantity" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		input.Quantity = 1
+	}
+
+	productIDUint, err := strconv.ParseUint(productID, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var product models.Product
+	if err := db.First(&product, "id = ? AND is_active = ?", uint(productIDUint), true).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found atau tidak aktif"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		}
+		return
+	}
+
+	if product.Stock < input.Quantity {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":              "Insufficient stock",
+			"available_stock":    product.Stock,
+			"requested_quantity": input.Quantity,
+		})
+		return
+	}
+
+	now := time.Now()
+	cart, ok := currentGuestCart(c, db)
+	token := ""
+	if !ok {
+		cart, token, err = utils.CreateGuestCart(db, now)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create guest cart"})
+			return
+		}
+		setGuestCartCookie(c, token)
+	} else if err := utils.TouchGuestCart(db, &cart, now); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart"})
+		return
+	}
+
+	var cartItem models.GuestCartItem
+	err = db.Where("guest_cart_id = ? AND product_id = ?", cart.ID, product.ID).First(&cartItem).Error
+
+	if err == nil {
+		newQuantity := cartItem.Quantity + input.Quantity
+
+		if product.Stock < newQuantity {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":           "Total quantity would exceed stock",
+				"current_in_cart": cartItem.Quantity,
+				"requested_add":   input.Quantity,
+				"available_stock": product.Stock,
+			})
+			return
+		}
+
+		cartItem.Quantity = newQuantity
+		if err := db.Save(&cartItem).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Product quantity updated in cart",
+			"data": gin.H{
+				"cart_id":      cartItem.ID,
+				"product_id":   cartItem.ProductID,
+				"new_quantity": cartItem.Quantity,
+				"total_price":  guestLinePrice(product, cartItem.Quantity),
+			},
+		})
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		newItem := models.GuestCartItem{
+			GuestCartID: cart.ID,
+			ProductID:   product.ID,
+			Quantity:    input.Quantity,
+		}
+
+		if err := db.Create(&newItem).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add to cart"})
+			return
+		}
+
+		response := gin.H{
+			"message": "Product added to cart successfully",
+			"data": gin.H{
+				"cart_id":     newItem.ID,
+				"product_id":  newItem.ProductID,
+				"quantity":    newItem.Quantity,
+				"total_price": guestLinePrice(product, newItem.Quantity),
+			},
+		}
+		// Token hanya dikirim saat guest cart baru dibuat, untuk client yang tidak memakai cookie
+		if token != "" {
+			response["guest_cart_token"] = token
+		}
+		c.JSON(http.StatusOK, response)
+	} else {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+	}
+}
+
+func UpdateGuestCartItem(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	var input struct {
+		Quantity uint `json:"quantity" binding:"required,min=1"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cart, ok := currentGuestCart(c, db)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cart item not found"})
+		return
+	}
+
+	var cartItem models.GuestCartItem
+	err := db.Where("id = ? AND guest_cart_id = ?", c.Param("item_id"), cart.ID).First(&cartItem).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Cart item not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		}
+		return
+	}
+
+	var product models.Product
+	if err := db.First(&product, cartItem.ProductID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	if product.Stock < input.Quantity {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":              "Insufficient stock",
+			"available_stock":    product.Stock,
+			"requested_quantity": input.Quantity,
+		})
+		return
+	}
+
+	cartItem.Quantity = input.Quantity
+	if err := db.Save(&cartItem).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart item"})
+		return
+	}
+	if err := utils.TouchGuestCart(db, &cart, time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart item"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cart item updated successfully",
+		"data": gin.H{
+			"cart_id":      cartItem.ID,
+			"product_id":   cartItem.ProductID,
+			"new_quantity": cartItem.Quantity,
+			"total_price":  guestLinePrice(product, cartItem.Quantity),
+		},
+	})
+}
+
+func RemoveFromGuestCart(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	cart, ok := currentGuestCart(c, db)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cart item not found"})
+		return
+	}
+
+	result := db.Where("id = ? AND guest_cart_id = ?", c.Param("item_id"), cart.ID).Delete(&models.GuestCartItem{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove from cart"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cart item not found"})
+		return
+	}
+	utils.TouchGuestCart(db, &cart, time.Now())
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Item removed from cart successfully",
+	})
+}
+
+func ClearGuestCart(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	if cart, ok := currentGuestCart(c, db); ok {
+		if err := db.Where("guest_cart_id = ?", cart.ID).Delete(&models.GuestCartItem{}).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear cart"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cart cleared successfully",
+	})
+}
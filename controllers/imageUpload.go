@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/storage"
+	"ecommerce-golang/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"io"
+	"net/http"
+)
+
+// Maksimal jumlah gambar per produk
+const maxProductImages = 10
+
+func UploadProductImage(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+	store := c.MustGet("storage").(storage.Storage)
+	productID := c.Param("id")
+
+	var product models.Product
+	if err := db.First(&product, "id = ? AND seller_id = ?", productID, sellerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if len(product.Images) >= maxProductImages {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Maximum 10 images per product"})
+		return
+	}
+
+	asset, ok := handleImageUpload(c, db, store, "seller", sellerID)
+	if !ok {
+		return
+	}
+
+	alreadyAttached := false
+	for _, image := range product.Images {
+		if image == asset.OriginalURL {
+			alreadyAttached = true
+			break
+		}
+	}
+	if !alreadyAttached {
+		product.Images = append(product.Images, asset.OriginalURL)
+		if err := db.Save(&product).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal Update Product"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Image uploaded",
+		"data": gin.H{
+			"image":  asset,
+			"images": product.Images,
+		},
+	})
+}
+
+func UploadShopLogo(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+	store := c.MustGet("storage").(storage.Storage)
+
+	var profile models.SellerProfile
+	if err := db.Where("seller_id = ?", sellerID).First(&profile).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+		return
+	}
+
+	asset, ok := handleImageUpload(c, db, store, "seller", sellerID)
+	if !ok {
+		return
+	}
+
+	profile.ShopLogo = asset.MediumURL
+	if err := db.Save(&profile).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logo berhasil diupload",
+		"data": gin.H{
+			"image":   asset,
+			"profile": profile,
+		},
+	})
+}
+
+func UploadUserPhoto(c *gin.Context) {
+	userID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+	store := c.MustGet("storage").(storage.Storage)
+
+	var profile models.UserProfile
+	if err := db.Where("user_id = ?", userID).First(&profile).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+		return
+	}
+
+	asset, ok := handleImageUpload(c, db, store, "user", userID)
+	if !ok {
+		return
+	}
+
+	profile.PhotoProfile = asset.MediumURL
+	if err := db.Save(&profile).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal update profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Foto profile berhasil diupload",
+		"data": gin.H{
+			"image":   asset,
+			"profile": profile,
+		},
+	})
+}
+
+// handleImageUpload - membaca field multipart "image", lalu memproses dan menyimpannya.
+// Jika gagal, response error sudah dikirim dan ok bernilai false.
+func handleImageUpload(c *gin.Context, db *gorm.DB, store storage.Storage, uploaderType string, uploaderID uint) (models.ImageAsset, bool) {
+	// Batasi body request supaya file besar ditolak sebelum dibaca seluruhnya
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, utils.MaxImageUploadSize+(1<<20))
+
+	fileHeader, err := c.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": utils.ErrImageTooLarge.Error()})
+			return models.ImageAsset{}, false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Image is required (form field: image)"})
+		return models.ImageAsset{}, false
+	}
+	if fileHeader.Size > utils.MaxImageUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": utils.ErrImageTooLarge.Error()})
+		return models.ImageAsset{}, false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal membaca file"})
+		return models.ImageAsset{}, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, utils.MaxImageUploadSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal membaca file"})
+		return models.ImageAsset{}, false
+	}
+
+	asset, err := utils.SaveImageUpload(c.Request.Context(), db, store, data, uploaderType, uploaderID)
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrImageTooLarge):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		case errors.Is(err, utils.ErrUnsupportedImage), errors.Is(err, utils.ErrImageDimensionLimit):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan gambar"})
+		}
+		return models.ImageAsset{}, false
+	}
+
+	return asset, true
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"bytes"
+	"ecommerce-golang/invoice"
+	"ecommerce-golang/models"
+	"ecommerce-golang/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+// IssueSellerInvoice - menerbitkan invoice untuk shipment milik seller (idempotent)
+func IssueSellerInvoice(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var shipment models.Shipment
+	if err := db.Where("id = ? AND seller_id = ?", c.Param("shipment_id"), sellerID).First(&shipment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shipment not found"})
+		return
+	}
+
+	inv, err := utils.IssueShipmentInvoice(db, shipment.ID, time.Now())
+	if err != nil {
+		if errors.Is(err, utils.ErrShipmentHasNoItems) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menerbitkan invoice"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invoice issued",
+		"data":    inv,
+	})
+}
+
+func GetSellerInvoices(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+	listInvoices(c, db, "seller_id = ?", sellerID)
+}
+
+func GetSellerInvoice(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+	renderInvoice(c, db, "seller_id = ?", sellerID)
+}
+
+func GetUserInvoices(c *gin.Context) {
+	userID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+	listInvoices(c, db, "user_id = ?", userID)
+}
+
+func GetUserInvoice(c *gin.Context) {
+	userID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+	renderInvoice(c, db, "user_id = ?", userID)
+}
+
+func listInvoices(c *gin.Context, db *gorm.DB, ownerQuery string, ownerID uint) {
+	var invoices []models.Invoice
+	if err := db.Where(ownerQuery, ownerID).Order("issued_at DESC, id DESC").Find(&invoices).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil invoice"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Invoice berhasil diambil",
+		"data":    invoices,
+		"total":   len(invoices),
+	})
+}
+
+// renderInvoice - detail invoice sebagai JSON, atau download dengan ?format=html / ?format=pdf
+func renderInvoice(c *gin.Context, db *gorm.DB, ownerQuery string, ownerID uint) {
+	var inv models.Invoice
+	err := db.Preload("Items").
+		Where("id = ?", c.Param("invoice_id")).
+		Where(ownerQuery, ownerID).
+		First(&inv).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		}
+		return
+	}
+
+	doc := utils.InvoiceDocument(inv)
+	switch c.Query("format") {
+	case "pdf":
+		data, err := invoice.RenderPDF(doc)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat PDF"})
+			return
+		}
+		c.Header("Content-Disposition", `attachment; filename="`+doc.Filename("pdf")+`"`)
+		c.Data(http.StatusOK, "application/pdf", data)
+	case "html":
+		var buf bytes.Buffer
+		if err := invoice.RenderHTML(&buf, doc); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat invoice"})
+			return
+		}
+		c.Header("Content-Disposition", `inline; filename="`+doc.Filename("html")+`"`)
+		c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+	case "", "json":
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Invoice berhasil diambil",
+			"data":    inv,
+		})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json, html or pdf"})
+	}
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"bytes"
+	"ecommerce-golang/models"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"net/http/httptest"
+	"testing"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestDB - database SQLite in-memory dengan skema yang sama seperti config.ConnectDB
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		TranslateError: true,
+		Logger:         logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Satu koneksi supaya semua query melihat database in-memory yang sama
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(
+		&models.User{},
+		&models.Seller{},
+		&models.UserProfile{},
+		&models.SellerProfile{},
+		&models.UserAddress{},
+		&models.Product{},
+		&models.ProductUserHistory{},
+		&models.Shipment{},
+		&models.ShipmentEvent{},
+		&models.TaxRule{},
+		&models.InvoiceSequence{},
+		&models.Invoice{},
+		&models.InvoiceItem{},
+		&models.ProductUserCart{},
+		&models.FlashSale{},
+		&models.FlashSaleReservation{},
+		&models.Voucher{},
+		&models.VoucherRedemption{},
+		&models.ProductView{},
+	); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// performRequest - menjalankan handler dengan context yang diisi seperti middleware auth
+func performRequest(db *gorm.DB, handler gin.HandlerFunc, method, target string, body []byte, params gin.Params, values gin.H) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, target, bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	c.Set("db", db)
+	for key, value := range values {
+		c.Set(key, value)
+	}
+	handler(c)
+	return w
+}
+
+func mustJSON(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
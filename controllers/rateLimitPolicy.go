@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"ecommerce-golang/ratelimit"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// rateLimitPolicyResponse - policy aktif dan status reload untuk admin
+func rateLimitPolicyResponse(registry *ratelimit.PolicyRegistry, rules []ratelimit.PolicyRule) gin.H {
+	status := registry.Status()
+
+	policies := make([]gin.H, 0, len(rules))
+	for _, rule := range rules {
+		policies = append(policies, gin.H{
+			"name":      rule.Name,
+			"routes":    rule.Routes,
+			"methods":   rule.Methods,
+			"tiers":     rule.Tiers,
+			"algorithm": rule.Algorithm,
+			"limit":     rule.Limit,
+			"period":    rule.Period.String(),
+			"burst":     rule.Burst,
+			"key":       rule.Key,
+			"per_route": rule.PerRoute,
+		})
+	}
+
+	source := gin.H{
+		"path":      status.Source,
+		"checksum":  status.Checksum,
+		"loaded_at": status.LoadedAt,
+	}
+	if status.LastError != "" {
+		source["last_error"] = status.LastError
+		source["last_error_at"] = status.LastErrorAt
+	}
+	priorities := make([]gin.H, 0)
+	for _, rule := range registry.Current().PriorityRules() {
+		priorities = append(priorities, gin.H{
+			"class":   rule.Class,
+			"routes":  rule.Routes,
+			"methods": rule.Methods,
+		})
+	}
+	return gin.H{"source": source, "policies": policies, "priorities": priorities}
+}
+
+// GetRateLimitPolicies - policy rate limit yang sedang berlaku.
+// Dengan ?route=/product/:id (opsional &method=GET&tier=user) hanya aturan yang berlaku untuk
+// request tersebut yang dikembalikan.
+func GetRateLimitPolicies(c *gin.Context) {
+	registry := ratelimit.ActivePolicies()
+	if registry == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Rate limit policies are not loaded"})
+		return
+	}
+
+	rules := registry.Status().Rules
+	if route := c.Query("route"); route != "" {
+		method := strings.ToUpper(c.DefaultQuery("method", http.MethodGet))
+		tier := c.DefaultQuery("tier", ratelimit.TierAnonymous)
+
+		var matched []ratelimit.PolicyRule
+		for _, match := range registry.Current().Match(method, route, tier) {
+			matched = append(matched, match.Rule)
+		}
+		rules = matched
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Policy rate limit berhasil diambil",
+		"data":    rateLimitPolicyResponse(registry, rules),
+	})
+}
+
+// ReloadRateLimitPolicies - memuat ulang file policy tanpa menunggu interval watch
+func ReloadRateLimitPolicies(c *gin.Context) {
+	registry := ratelimit.ActivePolicies()
+	if registry == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Rate limit policies are not loaded"})
+		return
+	}
+
+	reloaded, err := registry.Reload()
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Policy file tidak valid, policy lama tetap dipakai: " + err.Error()})
+		return
+	}
+
+	message := "Policy rate limit tidak berubah"
+	if reloaded {
+		message = "Policy rate limit berhasil dimuat ulang"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    rateLimitPolicyResponse(registry, registry.Status().Rules),
+	})
+}
+
+// GetRateLimitStats - counter allowed/limited per policy sejak proses start (per replica)
+// dan key yang paling sering kena limit (?top=, default 10, maksimal 100)
+func GetRateLimitStats(c *gin.Context) {
+	top, err := strconv.Atoi(c.DefaultQuery("top", "10"))
+	if err != nil || top < 1 || top > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "top must be between 1 and 100"})
+		return
+	}
+
+	snapshot := ratelimit.DefaultMetrics.Snapshot(top)
+	policies := make([]gin.H, 0, len(snapshot.Policies))
+	for _, stats := range snapshot.Policies {
+		keys := make([]gin.H, 0, len(stats.TopLimited))
+		for _, key := range stats.TopLimited {
+			keys = append(keys, gin.H{
+				"key":          key.Key,
+				"limited":      key.Limited,
+				"last_limited": key.LastLimited,
+			})
+		}
+		policies = append(policies, gin.H{
+			"policy":      stats.Policy,
+			"allowed":     stats.Allowed,
+			"limited":     stats.Limited,
+			"errors":      stats.Errors,
+			"top_limited": keys,
+		})
+	}
+
+	data := gin.H{
+		"since":       snapshot.Since,
+		"allowlisted": snapshot.Allowlisted,
+		"denylisted":  snapshot.Denylisted,
+		"policies":    policies,
+	}
+	if registry := ratelimit.ActivePolicies(); registry != nil {
+		allow, deny := registry.Current().AccessLists()
+		data["allow"] = allow
+		data["deny"] = deny
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Statistik rate limit berhasil diambil",
+		"data":    data,
+	})
+}
+
+// rateLimitKeyLimiter - Limiter policy dari ?policy= untuk ?key= (format key seperti di top_limited,
+// contoh "ip:203.0.113.7", "user:42" atau "ip:203.0.113.7:/user/login" untuk policy per_route)
+func rateLimitKeyLimiter(c *gin.Context) (*ratelimit.Limiter, string, bool) {
+	policy, key := c.Query("policy"), c.Query("key")
+	if policy == "" || key == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "policy and key are required"})
+		return nil, "", false
+	}
+	registry := ratelimit.ActivePolicies()
+	if registry == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Rate limit policies are not loaded"})
+		return nil, "", false
+	}
+	limiter, found := registry.Current().Limiter(policy)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Policy tidak ditemukan"})
+		return nil, "", false
+	}
+	return limiter, key, true
+}
+
+// InspectRateLimitKey - sisa kuota key pada satu policy tanpa memakai kuota
+func InspectRateLimitKey(c *gin.Context) {
+	limiter, key, ok := rateLimitKeyLimiter(c)
+	if !ok {
+		return
+	}
+
+	result, err := limiter.Peek(c.Request.Context(), key)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Gagal membaca rate limit store: " + err.Error()})
+		return
+	}
+
+	policy := limiter.Policy()
+	c.JSON(http.StatusOK, gin.H{
+		"message": "State rate limit berhasil diambil",
+		"data": gin.H{
+			"policy":         policy.Name,
+			"algorithm":      policy.Algorithm,
+			"key":            key,
+			"allowed":        result.Allowed,
+			"limit":          result.Limit,
+			"remaining":      result.Remaining,
+			"retry_after_ms": result.RetryAfter.Milliseconds(),
+			"reset_after_ms": result.ResetAfter.Milliseconds(),
+		},
+	})
+}
+
+// ResetRateLimitKey - mengembalikan kuota key menjadi penuh (misalnya setelah komplain 429)
+func ResetRateLimitKey(c *gin.Context) {
+	limiter, key, ok := rateLimitKeyLimiter(c)
+	if !ok {
+		return
+	}
+
+	if err := limiter.Reset(c.Request.Context(), key); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Gagal reset rate limit: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Rate limit key berhasil di-reset"})
+}
+
+// GetLoadSheddingStats - limit concurrency saat ini (berubah mengikuti latency), request yang
+// sedang diproses dan antri, serta jumlah yang diterima/dibuang per kelas sejak proses start
+func GetLoadSheddingStats(c *gin.Context) {
+	limiter := ratelimit.ActiveConcurrencyLimiter()
+	if limiter == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Load shedding is disabled"})
+		return
+	}
+
+	stats := limiter.Stats()
+	classes := make(gin.H, len(stats.Queued))
+	for class := range stats.Queued {
+		classes[class] = gin.H{
+			"limit":            stats.ClassLimits[class],
+			"queued":           stats.Queued[class],
+			"admitted":         stats.Admitted[class],
+			"shed":             stats.Shed[class],
+			"queue_timeout_ms": stats.QueueTimeout[class].Milliseconds(),
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Statistik load shedding berhasil diambil",
+		"data": gin.H{
+			"limit":       stats.Limit,
+			"in_flight":   stats.InFlight,
+			"latency_ms":  stats.Latency.Milliseconds(),
+			"baseline_ms": stats.Baseline.Milliseconds(),
+			"classes":     classes,
+		},
+	})
+}
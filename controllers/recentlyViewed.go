@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// GetRecentlyViewed - produk yang terakhir dilihat user, terbaru lebih dulu
+func GetRecentlyViewed(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	pageInt, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || pageInt < 1 {
+		pageInt = 1
+	}
+	limitInt, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limitInt < 1 || limitInt > 50 {
+		limitInt = 20
+	}
+
+	products, total, err := utils.GetRecentlyViewed(db, userID, limitInt, (pageInt-1)*limitInt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil riwayat produk"})
+		return
+	}
+	listViews := make([]models.ProductListView, len(products))
+	for i := range products {
+		listViews[i] = products[i].ProductListView
+	}
+	utils.ApplyFlashSalePrices(db, listViews)
+	for i := range products {
+		products[i].ProductListView = listViews[i]
+	}
+
+	var profile models.UserProfile
+	db.Where("user_id = ?", userID).Limit(1).Find(&profile)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Riwayat produk berhasil diambil",
+		"data":    products,
+		"pagination": gin.H{
+			"page":  pageInt,
+			"limit": limitInt,
+			"total": total,
+		},
+		"paused": profile.ViewHistoryPaused,
+	})
+}
+
+// ClearRecentlyViewed - menghapus seluruh riwayat produk yang dilihat
+func ClearRecentlyViewed(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	deleted, err := utils.ClearViewHistory(db, userID, 0, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus riwayat produk"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Riwayat produk dihapus",
+		"deleted": deleted,
+	})
+}
+
+// RemoveRecentlyViewed - menghapus satu produk dari riwayat
+func RemoveRecentlyViewed(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	productID, err := strconv.ParseUint(c.Param("product_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	deleted, err := utils.ClearViewHistory(db, userID, uint(productID), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus riwayat produk"})
+		return
+	}
+	if deleted == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not in history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Produk dihapus dari riwayat"})
+}
+
+// UpdateViewHistorySettings - menjeda / melanjutkan pencatatan riwayat produk yang dilihat
+func UpdateViewHistorySettings(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	var input struct {
+		Paused *bool `json:"paused" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var profile models.UserProfile
+	if err := db.Where("user_id = ?", userID).First(&profile).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+		return
+	}
+	if err := db.Model(&profile).Update("view_history_paused", *input.Paused).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan pengaturan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pengaturan riwayat produk disimpan",
+		"data":    gin.H{"paused": *input.Paused},
+	})
+}
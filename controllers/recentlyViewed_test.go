@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/utils"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// View yang masih di buffer recorder saat riwayat dihapus tidak boleh muncul kembali
+func TestClearRecentlyViewedDropsBufferedViews(t *testing.T) {
+	db := newTestDB(t)
+	user := models.User{Email: "buyer@example.com", Username: "buyer"}
+	db.Create(&user)
+	db.Create(&models.UserProfile{UserID: user.ID})
+	before := models.Product{Name: "Kaos", IsActive: true}
+	after := models.Product{Name: "Sepatu", IsActive: true}
+	db.Create(&before)
+	db.Create(&after)
+
+	// Batch ditulis setelah dua view, ticker tidak ikut menulis selama test
+	recorder := utils.NewViewRecorder(db, 10, 2, time.Hour)
+	recorder.Record(models.ProductView{UserID: &user.ID, ProductID: before.ID, ViewedAt: time.Now().Add(-time.Second)})
+
+	w := performRequest(db, ClearRecentlyViewed, http.MethodDelete, "/user/recently-viewed", nil, nil, gin.H{"id": user.ID})
+	if w.Code != http.StatusOK {
+		t.Fatalf("clear = %d: %s", w.Code, w.Body)
+	}
+
+	recorder.Record(models.ProductView{UserID: &user.ID, ProductID: after.ID, ViewedAt: time.Now().Add(time.Second)})
+	recorder.Start()
+
+	var views []models.ProductView
+	for deadline := time.Now().Add(2 * time.Second); len(views) == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		db.Where("user_id = ?", user.ID).Find(&views)
+	}
+	if len(views) != 1 || views[0].ProductID != after.ID {
+		t.Errorf("views = %+v, want only product %d", views, after.ID)
+	}
+}
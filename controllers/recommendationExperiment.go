@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/recommend"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"strings"
+)
+
+// GetRecommendationExperiments - daftar experiment beserta strategi yang bisa dipakai di arm
+func GetRecommendationExperiments(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	var experiments []models.RecommendationExperiment
+	if err := db.Order("updated_at DESC").Find(&experiments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil experiment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Experiment rekomendasi berhasil diambil",
+		"data":        experiments,
+		"strategies":  recommend.Names(),
+		"default_arm": recommend.DefaultArm(),
+	})
+}
+
+// UpsertRecommendationExperiment - membuat / mengubah experiment berdasarkan nama.
+// Mengaktifkan satu experiment menonaktifkan yang lain; berlaku di request berikutnya tanpa restart.
+func UpsertRecommendationExperiment(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	var input struct {
+		Name   string                 `json:"name" binding:"required,max=100"`
+		Active bool                   `json:"active"`
+		Arms   []models.ExperimentArm `json:"arms" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	experiment := models.RecommendationExperiment{
+		Name:   strings.TrimSpace(input.Name),
+		Active: input.Active,
+		Arms:   input.Arms,
+	}
+	if err := recommend.ValidateExperiment(experiment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if experiment.Active {
+			if err := tx.Model(&models.RecommendationExperiment{}).
+				Where("name <> ? AND active = ?", experiment.Name, true).
+				Update("active", false).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"active", "arms", "updated_at"}),
+		}).Create(&experiment).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan experiment"})
+		return
+	}
+	db.Where("name = ?", experiment.Name).First(&experiment)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Experiment saved",
+		"data":    experiment,
+	})
+}
+
+func DeleteRecommendationExperiment(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	result := db.Delete(&models.RecommendationExperiment{}, c.Param("experiment_id"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus experiment"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Experiment not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Experiment deleted"})
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+func CreateSellerAPIKey(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var input struct {
+		Name          string   `json:"name" binding:"required"`
+		Scopes        []string `json:"scopes" binding:"required,min=1"`
+		ExpiresInDays int      `json:"expires_in_days"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, scope := range input.Scopes {
+		if !isValidAPIKeyScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":        "Invalid scope: " + scope,
+				"valid_scopes": models.ValidAPIKeyScopes,
+			})
+			return
+		}
+	}
+
+	fullKey, prefix, secretHash, err := utils.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal generate API key"})
+		return
+	}
+
+	apiKey := models.SellerAPIKey{
+		SellerID:   sellerID,
+		Name:       input.Name,
+		Prefix:     prefix,
+		SecretHash: secretHash,
+		Scopes:     input.Scopes,
+	}
+	if input.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, input.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	if err := db.Create(&apiKey).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan API key"})
+		return
+	}
+
+	// Key lengkap hanya dikembalikan sekali di sini
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key created. Simpan key ini, tidak akan ditampilkan lagi",
+		"data": gin.H{
+			"api_key": fullKey,
+			"key":     apiKey,
+		},
+	})
+}
+
+func GetSellerAPIKeys(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var apiKeys []models.SellerAPIKey
+	if err := db.Where("seller_id = ?", sellerID).Order("created_at DESC").Find(&apiKeys).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API keys berhasil diambil",
+		"data":    apiKeys,
+		"total":   len(apiKeys),
+	})
+}
+
+func RevokeSellerAPIKey(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+	keyID := c.Param("key_id")
+
+	var apiKey models.SellerAPIKey
+	if err := db.Where("id = ? AND seller_id = ?", keyID, sellerID).First(&apiKey).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		return
+	}
+
+	if apiKey.RevokedAt == nil {
+		now := time.Now()
+		apiKey.RevokedAt = &now
+		if err := db.Save(&apiKey).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal revoke API key"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "API key revoked",
+		"data":    apiKey,
+	})
+}
+
+func isValidAPIKeyScope(scope string) bool {
+	for _, s := range models.ValidAPIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// GetSellerOrders - item pembelian untuk produk milik seller, terbaru dulu.
+// ?unshipped=true hanya mengembalikan item yang belum punya shipment (untuk sync pesanan ke ERP).
+func GetSellerOrders(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	query := db.Table("product_user_histories").
+		Select("product_user_histories.*").
+		Joins("JOIN products ON product_user_histories.product_id = products.id").
+		Where("products.seller_id = ?", sellerID)
+	if c.Query("unshipped") == "true" {
+		query = query.Where("product_user_histories.shipment_id IS NULL")
+	}
+
+	var items []models.ProductUserHistory
+	if err := query.Order("product_user_histories.created_at DESC").Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil pesanan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pesanan berhasil diambil",
+		"data":    items,
+		"total":   len(items),
+	})
+}
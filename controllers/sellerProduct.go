@@ -2,6 +2,10 @@ package controllers
 
 import (
 	"ecommerce-golang/models"
+	"ecommerce-golang/money"
+	"ecommerce-golang/shipping"
+	"ecommerce-golang/utils"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 	"net/http"
@@ -11,6 +15,7 @@ func CreateProduct(c *gin.Context) {
 	sellerID := c.MustGet("id").(uint)
 	db := c.MustGet("db").(*gorm.DB)
 	var input struct {
+		SKU         string   `json:"sku" binding:"max=100"`
 		Name        string   `json:"name" binding:"required"`
 		Description string   `json:"description"`
 		Price       float64  `json:"price" binding:"required,gt=0"`
@@ -19,6 +24,9 @@ func CreateProduct(c *gin.Context) {
 		Images      []string `json:"images"`
 		Weight      float64  `json:"weight"`
 		Dimensions  string   `json:"dimensions"`
+		Length      float64  `json:"length" binding:"gte=0"`
+		Width       float64  `json:"width" binding:"gte=0"`
+		Height      float64  `json:"height" binding:"gte=0"`
 		Brand       string   `json:"brand"`
 	}
 
@@ -27,21 +35,40 @@ func CreateProduct(c *gin.Context) {
 		return
 	}
 
+	if models.IsAutoSKU(input.SKU) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "SKU must not start with " + models.AutoSKUPrefix})
+		return
+	}
+	if input.SKU != "" && skuTaken(db, sellerID, input.SKU, 0) {
+		c.JSON(http.StatusConflict, gin.H{"error": "SKU already used by another product"})
+		return
+	}
+
 	product := models.Product{
 		SellerID:    sellerID,
+		SKU:         input.SKU,
 		Name:        input.Name,
 		Description: input.Description,
-		Price:       input.Price,
+		Price:       money.FromFloat(input.Price),
 		Stock:       input.Stock,
 		Category:    input.Category,
 		Images:      input.Images,
 		Weight:      input.Weight,
 		Dimensions:  input.Dimensions,
+		Length:      input.Length,
+		Width:       input.Width,
+		Height:      input.Height,
 		Brand:       input.Brand,
 		IsActive:    true,
 	}
+	utils.NormalizeProductDimensions(&product)
 
 	if err := db.Create(&product).Error; err != nil {
+		// skuTaken bisa lolos jika dua request memakai SKU yang sama bersamaan
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "SKU already used by another product"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(), // tampilkan pesan error asli
 		})
@@ -93,6 +120,7 @@ func UpdateProduct(c *gin.Context) {
 	productID := c.Param("id")
 
 	var input struct {
+		SKU         string   `json:"sku" binding:"max=100"`
 		Name        string   `json:"name" binding:"required"`
 		Description string   `json:"description"`
 		Price       float64  `json:"price" binding:"required,gt=0"`
@@ -101,6 +129,9 @@ func UpdateProduct(c *gin.Context) {
 		Images      []string `json:"images"`
 		Weight      float64  `json:"weight"`
 		Dimensions  string   `json:"dimensions"`
+		Length      float64  `json:"length" binding:"gte=0"`
+		Width       float64  `json:"width" binding:"gte=0"`
+		Height      float64  `json:"height" binding:"gte=0"`
 		Brand       string   `json:"brand"`
 		IsActive    *bool    `json:"is_active"`
 	}
@@ -116,6 +147,17 @@ func UpdateProduct(c *gin.Context) {
 		return
 	}
 
+	if input.SKU != "" && input.SKU != product.SKU {
+		if models.IsAutoSKU(input.SKU) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "SKU must not start with " + models.AutoSKUPrefix})
+			return
+		}
+		if skuTaken(db, sellerID, input.SKU, product.ID) {
+			c.JSON(http.StatusConflict, gin.H{"error": "SKU already used by another product"})
+			return
+		}
+		product.SKU = input.SKU
+	}
 	if input.Name != "" {
 		product.Name = input.Name
 	}
@@ -123,7 +165,7 @@ func UpdateProduct(c *gin.Context) {
 		product.Description = input.Description
 	}
 	if input.Price > 0 {
-		product.Price = input.Price
+		product.Price = money.FromFloat(input.Price)
 	}
 	if input.Stock >= 0 {
 		product.Stock = input.Stock
@@ -137,8 +179,13 @@ func UpdateProduct(c *gin.Context) {
 	if input.Weight > 0 {
 		product.Weight = input.Weight
 	}
-	if input.Dimensions != "" {
+	if input.Length > 0 && input.Width > 0 && input.Height > 0 {
+		product.Length, product.Width, product.Height = input.Length, input.Width, input.Height
+		product.Dimensions = shipping.FormatDimensions(input.Length, input.Width, input.Height)
+	} else if input.Dimensions != "" {
 		product.Dimensions = input.Dimensions
+		product.Length, product.Width, product.Height = 0, 0, 0
+		utils.NormalizeProductDimensions(&product)
 	}
 	if input.Brand != "" {
 		product.Brand = input.Brand
@@ -148,6 +195,10 @@ func UpdateProduct(c *gin.Context) {
 	}
 
 	if err := db.Save(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"error": "SKU already used by another product"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal Update Product"})
 		return
 	}
@@ -178,3 +229,45 @@ func DeleteProduct(c *gin.Context) {
 		"massage": "Product deleted",
 	})
 }
+
+// UpdateProductStock - update stock saja, dipakai untuk sinkronisasi inventory dari ERP seller
+func UpdateProductStock(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+	productID := c.Param("id")
+
+	var input struct {
+		Stock *uint `json:"stock" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var product models.Product
+	if err := db.First(&product, "id = ? AND seller_id = ?", productID, sellerID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	if err := db.Model(&product).UpdateColumn("stock", *input.Stock).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal Update Stock"})
+		return
+	}
+	product.Stock = *input.Stock
+
+	c.JSON(http.StatusOK, gin.H{
+		"massage": "Stock updated",
+		"data":    product,
+	})
+}
+
+// skuTaken - mengecek apakah SKU sudah dipakai produk lain milik seller yang sama
+func skuTaken(db *gorm.DB, sellerID uint, sku string, excludeProductID uint) bool {
+	var count int64
+	db.Model(&models.Product{}).
+		Where("seller_id = ? AND sku = ? AND id <> ?", sellerID, sku, excludeProductID).
+		Count(&count)
+	return count > 0
+}
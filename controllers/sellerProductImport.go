@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"bytes"
+	"ecommerce-golang/models"
+	"ecommerce-golang/utils"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+// Batas ukuran file import (10 MB)
+const maxImportFileSize = 10 << 20
+
+// ImportSellerProducts - import produk dari CSV/XLSX. Dengan ?dry_run=true file hanya divalidasi
+// dan hasilnya langsung dikembalikan, tanpa itu import diproses di background sebagai job.
+func ImportSellerProducts(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+	dryRun := c.Query("dry_run") == "true"
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required (form field: file)"})
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "File too large, maximum 10MB"})
+		return
+	}
+
+	format, err := utils.DetectImportFormat(fileHeader.Filename)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal membaca file"})
+		return
+	}
+	defer file.Close()
+
+	rows, err := utils.ParseProductImportFile(format, file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if dryRun {
+		result := utils.ImportProducts(db, sellerID, rows, true)
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Dry run selesai, tidak ada data yang disimpan",
+			"data":    result,
+		})
+		return
+	}
+
+	job := models.ProductImportJob{
+		SellerID:  sellerID,
+		FileName:  fileHeader.Filename,
+		Format:    format,
+		Status:    models.ImportJobPending,
+		TotalRows: len(rows),
+	}
+	if err := db.Create(&job).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat import job"})
+		return
+	}
+
+	go utils.RunProductImportJob(db, job.ID, sellerID, rows)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Import sedang diproses",
+		"data":    job,
+	})
+}
+
+func GetProductImportJob(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+	jobID := c.Param("job_id")
+
+	var job models.ProductImportJob
+	if err := db.Where("id = ? AND seller_id = ?", jobID, sellerID).First(&job).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Import job not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Import job berhasil diambil",
+		"data":    job,
+	})
+}
+
+// ExportSellerProducts - export semua produk seller dengan skema kolom yang sama seperti import
+func ExportSellerProducts(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+	format := c.DefaultQuery("format", utils.ImportFormatCSV)
+
+	if format != utils.ImportFormatCSV && format != utils.ImportFormatXLSX {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, use csv or xlsx"})
+		return
+	}
+
+	if err := utils.EnsureProductSKUs(db, sellerID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyiapkan SKU produk"})
+		return
+	}
+
+	var products []models.Product
+	if err := db.Where("seller_id = ?", sellerID).Order("id ASC").Find(&products).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal Mengambil Product"})
+		return
+	}
+
+	var buf bytes.Buffer
+	var contentType string
+	var err error
+	if format == utils.ImportFormatXLSX {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		err = utils.WriteProductsXLSX(&buf, products)
+	} else {
+		contentType = "text/csv; charset=utf-8"
+		err = utils.WriteProductsCSV(&buf, products)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal export product"})
+		return
+	}
+
+	fileName := fmt.Sprintf("products-%d-%s.%s", sellerID, time.Now().Format("20060102"), format)
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
@@ -63,3 +63,33 @@ func UpdateSellerProfile(c *gin.Context) {
 		"data":    profile,
 	})
 }
+
+// UpdateSellerHoliday - mode libur toko, pembeli yang menyimpan produk toko ini di keranjang mendapat peringatan
+func UpdateSellerHoliday(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var input struct {
+		OnHoliday *bool `json:"on_holiday" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var profile models.SellerProfile
+	if err := db.Where("seller_id = ?", sellerID).First(&profile).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+		return
+	}
+
+	if err := db.Model(&profile).Update("on_holiday", *input.OnHoliday).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan status libur"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Status libur toko berhasil diupdate",
+		"data":    profile,
+	})
+}
@@ -0,0 +1,321 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/shipping"
+	"ecommerce-golang/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CreateShipment - seller memasukkan nomor resi untuk item pembelian yang dikirim dalam satu paket
+func CreateShipment(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var input struct {
+		HistoryIDs []uint `json:"history_ids" binding:"required,min=1"`
+		Courier    string `json:"courier" binding:"required"`
+		AWB        string `json:"awb" binding:"required,max=100"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tracker, err := shipping.GetTracker(input.Courier)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown courier: " + input.Courier})
+		return
+	}
+
+	var items []models.ProductUserHistory
+	if err := db.Table("product_user_histories").
+		Select("product_user_histories.*").
+		Joins("JOIN products ON product_user_histories.product_id = products.id").
+		Where("product_user_histories.id IN ? AND products.seller_id = ?", input.HistoryIDs, sellerID).
+		Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+	if len(items) != len(input.HistoryIDs) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Some purchase items not found"})
+		return
+	}
+
+	userID := items[0].UserID
+	for _, item := range items {
+		if item.UserID != userID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "All items in one shipment must belong to the same buyer"})
+			return
+		}
+		if item.ShipmentID != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Item already shipped", "history_id": item.ID})
+			return
+		}
+	}
+
+	shipment := models.Shipment{
+		SellerID: sellerID,
+		UserID:   userID,
+		Courier:  tracker.Courier(),
+		AWB:      strings.TrimSpace(input.AWB),
+		Status:   models.ShipmentPending,
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		tx.Model(&models.Shipment{}).Where("courier = ? AND awb = ?", shipment.Courier, shipment.AWB).Count(&count)
+		if count > 0 {
+			return errDuplicateAWB
+		}
+		if err := tx.Create(&shipment).Error; err != nil {
+			return err
+		}
+		// Pengecekan ShipmentID di atas terjadi di luar transaksi; request lain bisa sudah mengirim
+		// item yang sama, jadi semua item harus benar-benar terupdate
+		result := tx.Model(&models.ProductUserHistory{}).
+			Where("id IN ? AND shipment_id IS NULL", input.HistoryIDs).
+			Update("shipment_id", shipment.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(items)) {
+			return errItemsAlreadyShipped
+		}
+		return nil
+	})
+	if errors.Is(err, errDuplicateAWB) || errors.Is(err, errItemsAlreadyShipped) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat shipment"})
+		return
+	}
+
+	// Invoice diterbitkan bersamaan dengan pengiriman; jika gagal seller bisa menerbitkan ulang
+	response := gin.H{
+		"message": "Shipment created",
+		"data":    shipment,
+	}
+	if inv, err := utils.IssueShipmentInvoice(db, shipment.ID, time.Now()); err != nil {
+		log.Printf("Issue invoice for shipment %d failed: %v", shipment.ID, err)
+	} else {
+		response["invoice"] = gin.H{"id": inv.ID, "number": inv.Number}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+var (
+	errDuplicateAWB        = errors.New("AWB already registered for this courier")
+	errItemsAlreadyShipped = errors.New("Item already shipped")
+)
+
+func GetSellerShipments(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	query := db.Where("seller_id = ?", sellerID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var shipments []models.Shipment
+	if err := query.Order("created_at DESC").Find(&shipments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil shipments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Shipments berhasil diambil",
+		"data":    shipments,
+		"total":   len(shipments),
+	})
+}
+
+func GetSellerShipment(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	shipment, ok := findShipmentWithTimeline(c, db, "seller_id = ?", sellerID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Shipment berhasil diambil",
+		"data":    shipment,
+	})
+}
+
+// RefreshShipmentTracking - menarik event terbaru langsung dari kurir (untuk kurir yang mendukung pull)
+func RefreshShipmentTracking(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var shipment models.Shipment
+	if err := db.Where("id = ? AND seller_id = ?", c.Param("shipment_id"), sellerID).First(&shipment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shipment not found"})
+		return
+	}
+
+	tracker, err := shipping.GetTracker(shipment.Courier)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	events, err := tracker.Track(c.Request.Context(), shipment.AWB)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	added, err := utils.AppendShipmentEvents(db, shipment.ID, events)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan tracking"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tracking updated",
+		"data": gin.H{
+			"events_received": len(events),
+			"events_added":    added,
+		},
+	})
+}
+
+func GetUserShipments(c *gin.Context) {
+	userID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var shipments []models.Shipment
+	if err := db.Where("user_id = ?", userID).Order("created_at DESC").Find(&shipments).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil shipments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Shipments berhasil diambil",
+		"data":    shipments,
+		"total":   len(shipments),
+	})
+}
+
+// GetUserShipment - detail shipment beserta timeline tracking (urut dari event terlama)
+func GetUserShipment(c *gin.Context) {
+	userID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	shipment, ok := findShipmentWithTimeline(c, db, "user_id = ?", userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Shipment berhasil diambil",
+		"data":    shipment,
+	})
+}
+
+// CompleteUserShipment - buyer mengkonfirmasi paket sudah diterima sebelum auto-complete
+func CompleteUserShipment(c *gin.Context) {
+	userID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var shipment models.Shipment
+	if err := db.Where("id = ? AND user_id = ?", c.Param("shipment_id"), userID).First(&shipment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shipment not found"})
+		return
+	}
+	if shipment.Status != models.ShipmentDelivered {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Shipment has not been delivered yet", "status": shipment.Status})
+		return
+	}
+
+	now := time.Now()
+	result := db.Model(&models.Shipment{}).
+		Where("id = ? AND status = ?", shipment.ID, models.ShipmentDelivered).
+		Updates(map[string]interface{}{"status": models.ShipmentCompleted, "completed_at": now})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal update shipment"})
+		return
+	}
+	shipment.Status = models.ShipmentCompleted
+	shipment.CompletedAt = &now
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Pesanan selesai",
+		"data":    shipment,
+	})
+}
+
+// CourierWebhook - menerima update tracking dari kurir. Aman dipanggil berulang kali
+// untuk event yang sama karena penyimpanan event idempotent.
+func CourierWebhook(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	tracker, err := shipping.GetTracker(c.Param("courier"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	awb, events, err := tracker.ParseWebhook(c.Request)
+	if err != nil {
+		if errors.Is(err, shipping.ErrInvalidSignature) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	var shipment models.Shipment
+	if err := db.Where("courier = ? AND awb = ?", tracker.Courier(), awb).First(&shipment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shipment not found"})
+		return
+	}
+
+	added, err := utils.AppendShipmentEvents(db, shipment.ID, events)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan tracking"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Webhook processed",
+		"data": gin.H{
+			"shipment_id":     shipment.ID,
+			"events_received": len(events),
+			"events_added":    added,
+		},
+	})
+}
+
+func findShipmentWithTimeline(c *gin.Context, db *gorm.DB, ownerQuery string, ownerID uint) (models.Shipment, bool) {
+	var shipment models.Shipment
+	err := db.Where("id = ?", c.Param("shipment_id")).Where(ownerQuery, ownerID).
+		Preload("Events", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("occurred_at ASC, id ASC")
+		}).
+		Preload("Items.Product").
+		First(&shipment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Shipment not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		}
+		return shipment, false
+	}
+	return shipment, true
+}
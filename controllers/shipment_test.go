@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/money"
+	"ecommerce-golang/shipping"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type shipmentFixture struct {
+	db         *gorm.DB
+	tracker    *shipping.FakeTracker
+	sellerID   uint
+	userID     uint
+	historyIDs []uint
+}
+
+func newShipmentFixture(t *testing.T) shipmentFixture {
+	t.Helper()
+	db := newTestDB(t)
+	tracker := shipping.NewFakeTracker()
+	shipping.RegisterTracker(tracker)
+
+	seller := models.Seller{Email: "seller@example.com", Username: "seller"}
+	user := models.User{Email: "buyer@example.com", Username: "buyer"}
+	db.Create(&seller)
+	db.Create(&user)
+	product := models.Product{SellerID: seller.ID, Name: "Kaos", Price: money.IDR(50000), Stock: 10, Weight: 200}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	f := shipmentFixture{db: db, tracker: tracker, sellerID: seller.ID, userID: user.ID}
+	for i := 0; i < 2; i++ {
+		history := models.ProductUserHistory{UserID: user.ID, ProductID: product.ID, Quantity: 1, Price: money.IDR(50000)}
+		if err := db.Create(&history).Error; err != nil {
+			t.Fatal(err)
+		}
+		f.historyIDs = append(f.historyIDs, history.ID)
+	}
+	return f
+}
+
+func (f shipmentFixture) createShipment(t *testing.T, awb string) (int, models.Shipment) {
+	t.Helper()
+	body := mustJSON(t, gin.H{"history_ids": f.historyIDs, "courier": "fake", "awb": awb})
+	w := performRequest(f.db, CreateShipment, http.MethodPost, "/seller/shipments", body, nil, gin.H{"id": f.sellerID})
+
+	var shipment models.Shipment
+	f.db.Where("awb = ?", awb).First(&shipment)
+	return w.Code, shipment
+}
+
+func TestCreateShipment(t *testing.T) {
+	f := newShipmentFixture(t)
+
+	code, shipment := f.createShipment(t, "FAKE001")
+	if code != http.StatusOK || shipment.ID == 0 {
+		t.Fatalf("create shipment = %d, shipment %+v", code, shipment)
+	}
+	var shipped int64
+	f.db.Model(&models.ProductUserHistory{}).Where("shipment_id = ?", shipment.ID).Count(&shipped)
+	if shipped != int64(len(f.historyIDs)) {
+		t.Errorf("items shipped = %d, want %d", shipped, len(f.historyIDs))
+	}
+
+	if code, _ := f.createShipment(t, "FAKE002"); code != http.StatusConflict {
+		t.Errorf("second shipment for the same items = %d, want 409", code)
+	}
+}
+
+// Request lain mengirim item yang sama setelah pengecekan awal CreateShipment: update
+// shipment_id tidak mengenai semua item dan shipment yang baru dibuat harus di-rollback
+func TestCreateShipmentConcurrentDoubleShipment(t *testing.T) {
+	f := newShipmentFixture(t)
+
+	var other models.Shipment
+	err := f.db.Callback().Create().Before("gorm:create").Register("test:concurrent_shipment", func(tx *gorm.DB) {
+		if _, ok := tx.Statement.Dest.(*models.Shipment); !ok || other.ID != 0 {
+			return
+		}
+		other = models.Shipment{SellerID: f.sellerID, UserID: f.userID, Courier: "fake", AWB: "OTHER", Status: models.ShipmentPending}
+		session := tx.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+		session.Exec("INSERT INTO shipments (seller_id, user_id, courier, awb, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			other.SellerID, other.UserID, other.Courier, other.AWB, other.Status, time.Now(), time.Now())
+		session.Raw("SELECT id FROM shipments WHERE awb = ?", other.AWB).Scan(&other.ID)
+		session.Exec("UPDATE product_user_histories SET shipment_id = ? WHERE id = ?", other.ID, f.historyIDs[0])
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	code, shipment := f.createShipment(t, "FAKE001")
+	if code != http.StatusConflict {
+		t.Fatalf("create shipment = %d, want 409", code)
+	}
+	if shipment.ID != 0 {
+		t.Errorf("shipment %d was not rolled back", shipment.ID)
+	}
+	var history models.ProductUserHistory
+	f.db.First(&history, f.historyIDs[1])
+	if history.ShipmentID != nil {
+		t.Errorf("item %d assigned to shipment %d, want unshipped", history.ID, *history.ShipmentID)
+	}
+}
+
+func TestCourierWebhookAndRefresh(t *testing.T) {
+	f := newShipmentFixture(t)
+	if code, _ := f.createShipment(t, "FAKE001"); code != http.StatusOK {
+		t.Fatalf("create shipment = %d", code)
+	}
+
+	pickedUp := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	webhook := mustJSON(t, gin.H{
+		"awb": "FAKE001",
+		"events": []shipping.TrackingEvent{
+			{ExternalID: "e1", Status: models.ShipmentPickedUp, Location: "Bandung", OccurredAt: pickedUp},
+			{ExternalID: "e2", Status: models.ShipmentInTransit, Location: "Jakarta", OccurredAt: pickedUp.Add(6 * time.Hour)},
+		},
+	})
+	courier := gin.Params{{Key: "courier", Value: "fake"}}
+
+	tests := []struct {
+		name       string
+		handler    gin.HandlerFunc
+		body       []byte
+		params     gin.Params
+		values     gin.H
+		wantAdded  int
+		wantStatus string
+	}{
+		{name: "webhook", handler: CourierWebhook, body: webhook, params: courier, wantAdded: 2, wantStatus: models.ShipmentInTransit},
+		{name: "webhook dikirim ulang", handler: CourierWebhook, body: webhook, params: courier, wantAdded: 0, wantStatus: models.ShipmentInTransit},
+		{
+			name:    "refresh dari kurir",
+			handler: RefreshShipmentTracking,
+			params:  gin.Params{{Key: "shipment_id", Value: "1"}},
+			values:  gin.H{"id": f.sellerID},
+			// e2 sudah ada dari webhook, hanya delivered yang baru
+			wantAdded:  1,
+			wantStatus: models.ShipmentDelivered,
+		},
+	}
+
+	f.tracker.AddEvent("FAKE001", shipping.TrackingEvent{ExternalID: "e2", Status: models.ShipmentInTransit, OccurredAt: pickedUp.Add(6 * time.Hour)})
+	f.tracker.AddEvent("FAKE001", shipping.TrackingEvent{ExternalID: "e3", Status: models.ShipmentDelivered, Location: "Jakarta", OccurredAt: pickedUp.Add(30 * time.Hour)})
+
+	for _, tt := range tests {
+		w := performRequest(f.db, tt.handler, http.MethodPost, "/", tt.body, tt.params, tt.values)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", tt.name, w.Code, w.Body.String())
+		}
+		var response struct {
+			Data struct {
+				EventsAdded int `json:"events_added"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		if response.Data.EventsAdded != tt.wantAdded {
+			t.Errorf("%s: events_added = %d, want %d", tt.name, response.Data.EventsAdded, tt.wantAdded)
+		}
+
+		var shipment models.Shipment
+		f.db.First(&shipment, 1)
+		if shipment.Status != tt.wantStatus {
+			t.Errorf("%s: status = %s, want %s", tt.name, shipment.Status, tt.wantStatus)
+		}
+	}
+
+	var shipment models.Shipment
+	f.db.First(&shipment, 1)
+	if shipment.DeliveredAt == nil || !shipment.DeliveredAt.Equal(pickedUp.Add(30*time.Hour)) {
+		t.Errorf("delivered_at = %v, want %v", shipment.DeliveredAt, pickedUp.Add(30*time.Hour))
+	}
+
+	unknown := mustJSON(t, gin.H{"awb": "UNKNOWN", "events": []shipping.TrackingEvent{}})
+	if w := performRequest(f.db, CourierWebhook, http.MethodPost, "/", unknown, courier, nil); w.Code != http.StatusNotFound {
+		t.Errorf("webhook for unknown AWB = %d, want 404", w.Code)
+	}
+	if w := performRequest(f.db, CourierWebhook, http.MethodPost, "/", []byte("{"), courier, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("malformed webhook = %d, want 400", w.Code)
+	}
+}
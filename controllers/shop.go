@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+// GetShop - halaman toko: profil, jumlah follower dan produk aktif.
+// Jika user login (optional auth), is_following menunjukkan status follow.
+func GetShop(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	sellerID, err := strconv.ParseUint(c.Param("seller_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid seller ID"})
+		return
+	}
+
+	var profile models.SellerProfile
+	if err := db.Where("seller_id = ?", sellerID).First(&profile).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+		return
+	}
+
+	pageInt, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || pageInt < 1 {
+		pageInt = 1
+	}
+	limitInt, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limitInt < 1 || limitInt > 100 {
+		limitInt = 20
+	}
+
+	var total int64
+	db.Model(&models.Product{}).Where("seller_id = ? AND is_active = ?", sellerID, true).Count(&total)
+
+	var products []models.ProductListView
+	err = db.Table("products").
+		Select(`products.id, products.name, products.price, products.rating,
+                products.total_sold, products.images as image,
+                seller_profiles.shop_name, seller_profiles.city`).
+		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
+		Where("products.seller_id = ? AND products.is_active = ?", sellerID, true).
+		Order("products.total_sold DESC, products.created_at DESC").
+		Limit(limitInt).
+		Offset((pageInt - 1) * limitInt).
+		Find(&products).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil produk toko"})
+		return
+	}
+	utils.ProcessProductImages(&products)
+	utils.ApplyFlashSalePrices(db, products)
+
+	isFollowing := false
+	if userID, ok := c.Get("id"); ok && c.GetString("user_type") == "user" {
+		if id, ok := userID.(int); ok {
+			isFollowing = utils.IsFollowingShop(db, uint(id), uint(sellerID))
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Toko berhasil diambil",
+		"data": gin.H{
+			"seller_id":      profile.SellerID,
+			"shop_name":      profile.ShopName,
+			"shop_logo":      profile.ShopLogo,
+			"city":           profile.City,
+			"follower_count": profile.FollowerCount,
+			"product_count":  total,
+			"is_following":   isFollowing,
+			"joined_at":      profile.CreatedAt,
+			"products":       products,
+		},
+		"pagination": gin.H{
+			"page":        pageInt,
+			"limit":       limitInt,
+			"total":       total,
+			"total_pages": (total + int64(limitInt) - 1) / int64(limitInt),
+		},
+	})
+}
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/http"
+	"strings"
+)
+
+// UpsertTaxRule - admin mengatur tarif PPN per kategori (category kosong = tarif default)
+func UpsertTaxRule(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	var input struct {
+		Category    string   `json:"category" binding:"max=255"`
+		Rate        *float64 `json:"rate" binding:"required"`
+		Description string   `json:"description" binding:"max=255"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := models.TaxRule{
+		Category:    strings.TrimSpace(input.Category),
+		Rate:        *input.Rate,
+		Description: input.Description,
+	}
+	if err := utils.ValidateTaxRule(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "description", "updated_at"}),
+	}).Create(&rule).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan aturan pajak"})
+		return
+	}
+	db.Where("category = ?", rule.Category).First(&rule)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Tax rule saved",
+		"data":    rule,
+	})
+}
+
+func GetTaxRules(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	var rules []models.TaxRule
+	if err := db.Order("category ASC").Find(&rules).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil aturan pajak"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Aturan pajak berhasil diambil",
+		"data":         rules,
+		"default_rate": utils.DefaultTaxRate(),
+	})
+}
+
+func DeleteTaxRule(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	result := db.Delete(&models.TaxRule{}, c.Param("rule_id"))
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus aturan pajak"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tax rule not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Tax rule deleted"})
+}
+
+// UpdateSellerTaxProfile - status PKP, NPWP dan mode harga (termasuk/belum termasuk PPN)
+func UpdateSellerTaxProfile(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var input struct {
+		IsPKP        *bool  `json:"is_pkp" binding:"required"`
+		NPWP         string `json:"npwp"`
+		PriceTaxMode string `json:"price_tax_mode" binding:"omitempty,oneof=inclusive exclusive"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var profile models.SellerProfile
+	if err := db.Where("seller_id = ?", sellerID).First(&profile).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		}
+		return
+	}
+
+	updates := map[string]interface{}{"is_pkp": *input.IsPKP}
+
+	// Seller PKP wajib punya NPWP untuk dicantumkan di faktur
+	npwp := input.NPWP
+	if npwp == "" && *input.IsPKP {
+		npwp = profile.NPWP
+	}
+	if npwp != "" || *input.IsPKP {
+		normalized, err := utils.NormalizeNPWP(npwp)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		updates["npwp"] = normalized
+	}
+	if input.PriceTaxMode != "" {
+		updates["price_tax_mode"] = input.PriceTaxMode
+	}
+
+	if err := db.Model(&profile).Updates(updates).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan profil pajak"})
+		return
+	}
+	db.First(&profile, profile.ID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Profil pajak berhasil diupdate",
+		"data":    profile,
+	})
+}
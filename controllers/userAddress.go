@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/regions"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strings"
+)
+
+// Maksimal jumlah alamat per user
+const maxUserAddresses = 20
+
+type addressInput struct {
+	Label         string   `json:"label" binding:"max=50"`
+	RecipientName string   `json:"recipient_name" binding:"required,max=100"`
+	Phone         string   `json:"phone" binding:"required,max=20"`
+	Province      string   `json:"province" binding:"required"`
+	City          string   `json:"city" binding:"required"`
+	District      string   `json:"district" binding:"required,max=100"`
+	PostalCode    string   `json:"postal_code" binding:"required"`
+	AddressLine   string   `json:"address_line" binding:"required"`
+	Latitude      *float64 `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude     *float64 `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+	IsDefault     bool     `json:"is_default"`
+}
+
+func GetUserAddresses(c *gin.Context) {
+	userID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var addresses []models.UserAddress
+	if err := db.Where("user_id = ?", userID).Order("is_default DESC, updated_at DESC").Find(&addresses).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil alamat"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Alamat berhasil diambil",
+		"data":    addresses,
+		"total":   len(addresses),
+	})
+}
+
+func GetUserAddress(c *gin.Context) {
+	userID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var address models.UserAddress
+	if err := db.Where("id = ? AND user_id = ?", c.Param("address_id"), userID).First(&address).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Address not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Alamat berhasil diambil",
+		"data":    address,
+	})
+}
+
+func CreateUserAddress(c *gin.Context) {
+	userID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var input addressInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	match, err := regions.ValidateAddress(input.Province, input.City, strings.TrimSpace(input.PostalCode))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var count int64
+	db.Model(&models.UserAddress{}).Where("user_id = ?", userID).Count(&count)
+	if count >= maxUserAddresses {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Maximum 20 addresses per user"})
+		return
+	}
+
+	address := models.UserAddress{UserID: userID}
+	applyAddressInput(&address, input, match)
+	// Alamat pertama otomatis jadi default
+	address.IsDefault = input.IsDefault || count == 0
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if address.IsDefault {
+			if err := clearDefaultAddress(tx, userID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&address).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan alamat"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Alamat berhasil ditambahkan",
+		"data":    address,
+	})
+}
+
+func UpdateUserAddress(c *gin.Context) {
+	userID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var input addressInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var address models.UserAddress
+	if err := db.Where("id = ? AND user_id = ?", c.Param("address_id"), userID).First(&address).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Address not found"})
+		return
+	}
+
+	match, err := regions.ValidateAddress(input.Province, input.City, strings.TrimSpace(input.PostalCode))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	applyAddressInput(&address, input, match)
+	// Default hanya bisa dipindah ke alamat lain, tidak bisa dilepas begitu saja
+	makeDefault := input.IsDefault && !address.IsDefault
+	if makeDefault {
+		address.IsDefault = true
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if makeDefault {
+			if err := clearDefaultAddress(tx, userID); err != nil {
+				return err
+			}
+		}
+		return tx.Save(&address).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal update alamat"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Alamat berhasil diupdate",
+		"data":    address,
+	})
+}
+
+func SetDefaultUserAddress(c *gin.Context) {
+	userID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var address models.UserAddress
+	if err := db.Where("id = ? AND user_id = ?", c.Param("address_id"), userID).First(&address).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Address not found"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := clearDefaultAddress(tx, userID); err != nil {
+			return err
+		}
+		address.IsDefault = true
+		return tx.Model(&address).Update("is_default", true).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah alamat default"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Alamat default berhasil diubah",
+		"data":    address,
+	})
+}
+
+func DeleteUserAddress(c *gin.Context) {
+	userID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var address models.UserAddress
+	if err := db.Where("id = ? AND user_id = ?", c.Param("address_id"), userID).First(&address).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Address not found"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&address).Error; err != nil {
+			return err
+		}
+		if !address.IsDefault {
+			return nil
+		}
+
+		// Alamat default dihapus, jadikan alamat terbaru sebagai default
+		var next models.UserAddress
+		err := tx.Where("user_id = ?", userID).Order("updated_at DESC").First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus alamat"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Alamat berhasil dihapus",
+	})
+}
+
+// GetRegions - dataset provinsi, kota/kabupaten dan kode pos untuk form alamat
+func GetRegions(c *gin.Context) {
+	provinces, err := regions.Provinces()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data wilayah"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Data wilayah berhasil diambil",
+		"data":    provinces,
+	})
+}
+
+func applyAddressInput(address *models.UserAddress, input addressInput, match regions.Match) {
+	address.Label = input.Label
+	address.RecipientName = input.RecipientName
+	address.Phone = input.Phone
+	address.Province = match.Province
+	address.City = match.City
+	address.District = input.District
+	address.PostalCode = strings.TrimSpace(input.PostalCode)
+	address.RegionWarning = match.Warning
+	address.AddressLine = input.AddressLine
+	address.Latitude = input.Latitude
+	address.Longitude = input.Longitude
+}
+
+func clearDefaultAddress(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.UserAddress{}).
+		Where("user_id = ? AND is_default = ?", userID, true).
+		Update("is_default", false).Error
+}
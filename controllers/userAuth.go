@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"log"
 	"net/http"
 )
 
@@ -90,7 +91,24 @@ func UserLogin(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal generate token" + err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"token": token, "message": "Login success"})
+	response := gin.H{"token": token, "message": "Login success"}
+
+	// Riwayat produk yang dilihat sebelum login ikut menjadi milik user
+	if err := utils.AttachVisitorViews(db, visitorID(c, false), user.ID); err != nil {
+		log.Printf("Attach visitor views to user %d failed: %v", user.ID, err)
+	}
+
+	// Isi guest cart digabung ke keranjang user, gagal merge tidak menggagalkan login
+	if guestCart, ok := currentGuestCart(c, db); ok {
+		merge, err := utils.MergeGuestCart(db, guestCart.ID, user.ID)
+		if err != nil {
+			log.Printf("Merge guest cart %d into user %d failed: %v", guestCart.ID, user.ID, err)
+		} else {
+			response["cart_merge"] = merge
+			clearGuestCartCookie(c)
+		}
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 func UserMe(c *gin.Context) {
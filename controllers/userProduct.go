@@ -2,13 +2,19 @@ package controllers
 
 import (
 	"ecommerce-golang/models"
+	"ecommerce-golang/money"
+	"ecommerce-golang/pricing"
+	"ecommerce-golang/recommend"
+	"ecommerce-golang/shipping"
 	"ecommerce-golang/utils"
 	"encoding/json"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 func SearchProduct(c *gin.Context) {
@@ -90,6 +96,10 @@ func SearchProduct(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil products"})
 		return
 	}
+	for i := range products {
+		products[i].Image = utils.ThumbnailURL(products[i].Image)
+	}
+	utils.ApplyFlashSalePrices(db, products)
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Products berhasil diambil",
@@ -119,226 +129,182 @@ func GetProductDetail(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Product tidaK ditemukan"})
 		return
 	}
+	utils.ApplyFlashSaleToDetail(db, &productDetail)
+
+	// Dicatat async untuk recently viewed dan rekomendasi
+	if userID, ok := optionalUserID(c); ok {
+		utils.RecordProductView(userID, visitorID(c, false), productDetail.ID, time.Now())
+	} else {
+		utils.RecordProductView(0, visitorID(c, true), productDetail.ID, time.Now())
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Product detail berhasil diambil",
 		"data":    productDetail,
 	})
 }
 
-// aku ingin jika anonymus maka akan diberikan best rating dan best seller
-// jika sudah login maka akan berdasarkan kategori riwayat pembelian +best rating dan best seller
-// jika user belum pernah beli apapun maka berdasarkan kategori keranjang +best rating dan best seller
-// jika user baru saja membuat maka akan seperti anonymus
-func GetRecommendations(c *gin.Context) {
+// GetRelatedProducts - "customers also bought" dari product_neighbors,
+// jika belum ada data memakai produk terlaris di kategori yang sama
+func GetRelatedProducts(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
 
-	// Check authentication
-	userID, exists := c.Get("id")
-	var isAuthenticated bool
-	var userIDUint uint
-
-	if exists {
-		isAuthenticated = true
-		switch v := userID.(type) {
-		case uint:
-			userIDUint = v
-		case int:
-			userIDUint = uint(v)
-		case float64:
-			userIDUint = uint(v)
-		default:
-			isAuthenticated = false // tipe tidak dikenal, anggap tidak login
-		}
-	}
-
-	var recommendedProducts []models.ProductListView
-	var basis string
-	var categories []string
-
-	if !isAuthenticated {
-		// Case 1: Anonymous user
-		recommendedProducts = utils.GetAnonymousRecommendations(db)
-		basis = "anonymous_bestseller_and_rating"
-	} else {
-		// Case 2: Authenticated user - check purchase history
-		if utils.CheckUserHasPurchaseHistory(db, userIDUint) {
-			// User has purchase history
-			categories = utils.GetTopCategoriesByPurchase(db, userIDUint, 3)
-			recommendedProducts = utils.GetCategoryBasedRecommendations(db, categories, userIDUint)
-			basis = "purchase_history_based"
-		} else if utils.CheckUserHasCartItems(db, userIDUint) {
-			// User has cart items but no purchase history
-			categories = utils.GetTopCategoriesByCart(db, userIDUint)
-			recommendedProducts = utils.GetCategoryBasedRecommendations(db, categories, userIDUint)
-			basis = "cart_based"
+	var product models.Product
+	if err := db.Where("id = ? AND is_active = ?", c.Param("id"), true).First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product tidak ditemukan"})
 		} else {
-			// Case 3: New user (no purchase, no cart) - treat like anonymous
-			recommendedProducts = utils.GetAnonymousRecommendations(db)
-			basis = "new_user_bestseller_and_rating"
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
 		}
+		return
 	}
 
-	// Fallback jika tidak ada hasil
-	if len(recommendedProducts) == 0 {
-		recommendedProducts = utils.GetAnonymousRecommendations(db)
-		basis = "fallback_bestseller_and_rating"
-	}
-
-	// Limit hasil akhir
-	if len(recommendedProducts) > 20 {
-		recommendedProducts = recommendedProducts[:20]
+	related := utils.GetRelatedProducts(db, product.ID, 12)
+	basis := "customers_also_bought"
+	if len(related) == 0 {
+		related = []models.ProductListView{}
+		for _, candidate := range utils.GetCategoryBasedRecommendations(db, []string{product.Category}, 0) {
+			if candidate.ID != product.ID && len(related) < 12 {
+				related = append(related, candidate)
+			}
+		}
+		basis = "same_category_bestseller"
 	}
+	utils.ApplyFlashSalePrices(db, related)
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Recommendations berhasil diambil",
-		"data":    recommendedProducts,
+		"message": "Related products berhasil diambil",
+		"data":    related,
 		"metadata": gin.H{
-			"total":                len(recommendedProducts),
-			"is_authenticated":     isAuthenticated,
+			"total":                len(related),
+			"product_id":           product.ID,
 			"recommendation_basis": basis,
-			"categories_used":      categories,
 		},
 	})
 }
 
-func getUserPurchaseCategories(db *gorm.DB, userID uint) []string {
-	var categories []string
-
-	err := db.Table("product_user_histories").
-		Select("DISTINCT category").
-		Where("user_id = ?", userID).
-		Order("created_at DESC").
-		Limit(5). // Ambil maksimal 5 kategori terakhir
-		Find(&categories).Error
+// GetTrendingProducts - produk trending 24 jam / 7 hari terakhir. Jika ?city= kosong dan user
+// login, dipakai kota alamat default user ("populer di kotamu"). Kota yang belum punya
+// cukup data kembali ke ranking nasional.
+func GetTrendingProducts(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
 
-	if err != nil {
-		return []string{}
+	window := c.DefaultQuery("window", models.TrendingWindowDay)
+	if _, err := utils.LookupTrendingWindow(window); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-
-	return categories
-
-}
-
-func getUserCartCategories(db *gorm.DB, userID uint) []string {
-	var categories []string
-	err := db.Table("product_user_carts").
-		Select("DISTINCT products.category").
-		Joins("JOIN products ON product_user_carts.product_id = products.id").
-		Where("product_user_carts.user_id = ? AND products.is_active = ?", userID, true).
-		Find(&categories).Error
-
-	if err != nil {
-		return []string{}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 50 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 50"})
+		return
 	}
 
-	return categories
-}
+	city := shipping.NormalizeCity(c.Query("city"))
+	if city == "" {
+		if userID, ok := optionalUserID(c); ok {
+			if destination, err := utils.GetUserDestination(db, userID, 0); err == nil {
+				city = shipping.NormalizeCity(destination.City)
+			}
+		}
+	}
 
-func getAnonymousRecommendations(db *gorm.DB) []models.ProductListView {
 	var products []models.ProductListView
-
-	err := db.Table("products").
-		Select(`products.id, products.name, products.price, products.rating, 
-                products.total_sold, products.images,
-                seller_profiles.shop_name, seller_profiles.city`).
-		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
-		Where("products.is_active = ? AND products.stock > 0", true).
-		Where("(products.rating >= 4.0 OR products.total_sold >= 10)").              // Filter best rating atau best seller
-		Order("(products.rating * 0.7 + (products.total_sold / 100.0) * 0.3) DESC"). // Weighted scoring
-		Limit(20).
-		Find(&products).Error
-
-	if err != nil {
-		return []models.ProductListView{}
+	var computedAt time.Time
+	scope := "national"
+	if city != "" {
+		products, computedAt, err = utils.GetTrendingProducts(db, window, city, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil produk trending"})
+			return
+		}
+		scope = "city"
+	}
+	if len(products) == 0 {
+		products, computedAt, err = utils.GetTrendingProducts(db, window, "", limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil produk trending"})
+			return
+		}
+		scope = "national"
 	}
+	if products == nil {
+		products = []models.ProductListView{}
+	}
+	utils.ApplyFlashSalePrices(db, products)
 
-	// Process images untuk thumbnail
-	processProductImages(&products)
-	return products
+	metadata := gin.H{
+		"total":  len(products),
+		"window": window,
+		"city":   city,
+		"scope":  scope,
+	}
+	if !computedAt.IsZero() {
+		metadata["computed_at"] = computedAt
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Produk trending berhasil diambil",
+		"data":     products,
+		"metadata": metadata,
+	})
 }
 
-func getCategoryBasedRecommendations(db *gorm.DB, categories []string, userID uint) []models.ProductListView {
-	var products []models.ProductListView
+// aku ingin jika anonymus maka akan diberikan best rating dan best seller
+// jika sudah login maka akan berdasarkan kategori riwayat pembelian +best rating dan best seller
+// jika user belum pernah beli apapun maka berdasarkan kategori keranjang +best rating dan best seller
+// jika belum ada pembelian/keranjang tapi punya wishlist atau follow toko maka berdasarkan kategori wishlist
+// jika user baru saja membuat maka akan seperti anonymus
+// produk dari toko yang di-follow selalu ikut dicampurkan untuk user yang login
+// produk yang sering dibeli bersama (product_neighbors) diselang-seling dengan hasil kategori
+//
+// Urutan di atas adalah arm default (recommend.DefaultArm). Jika ada experiment aktif,
+// user dibagi ke salah satu arm dan campuran strateginya mengikuti arm tersebut.
+func GetRecommendations(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
 
-	// Query untuk produk dari kategori yang diminati
-	categoryQuery := db.Table("products").
-		Select(`products.id, products.name, products.price, products.rating, 
-                products.total_sold, products.images,
-                seller_profiles.shop_name, seller_profiles.city`).
-		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
-		Where("products.is_active = ? AND products.stock > 0", true).
-		Where("products.category IN ?", categories).
-		Order("(products.rating * 0.6 + (products.total_sold / 100.0) * 0.4) DESC"). // Weighted untuk kategori
-		Limit(15)
+	// Token seller tidak dianggap login sebagai user
+	userIDUint, isAuthenticated := optionalUserID(c)
 
-	err := categoryQuery.Find(&products).Error
+	experiment, err := recommend.LoadActiveExperiment(db)
 	if err != nil {
-		return getAnonymousRecommendations(db)
+		log.Printf("Load recommendation experiment failed: %v", err)
 	}
+	assignment := recommend.Assign(experiment, recommendationBucketKey(c, isAuthenticated, userIDUint))
 
-	// Tambahkan beberapa best seller/rating dari kategori lain untuk variasi
-	var additionalProducts []models.ProductListView
-	additionalQuery := db.Table("products").
-		Select(`products.id, products.name, products.price, products.rating, 
-                products.total_sold, products.images,
-                seller_profiles.shop_name, seller_profiles.city`).
-		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
-		Where("products.is_active = ? AND products.stock > 0", true).
-		Where("products.category NOT IN ?", categories).
-		Where("(products.rating >= 4.5 OR products.total_sold >= 50)").
-		Order("(products.rating * 0.8 + (products.total_sold / 100.0) * 0.2) DESC").
-		Limit(5)
-
-	additionalQuery.Find(&additionalProducts)
-
-	// Gabungkan hasil
-	products = append(products, additionalProducts...)
-
-	// Remove duplicates jika ada
-	products = removeDuplicateProducts(products)
-
-	// Process images untuk thumbnail
-	processProductImages(&products)
+	result := recommend.Compose(db, recommend.Request{
+		UserID:          userIDUint,
+		IsAuthenticated: isAuthenticated,
+		VisitorID:       visitorID(c, false),
+		Limit:           20,
+	}, assignment.Mix)
+	utils.ApplyFlashSalePrices(db, result.Products)
 
-	return products
-}
-
-func processProductImages(products *[]models.ProductListView) {
-	for i := range *products {
-		var imageList []string
-		err := json.Unmarshal([]byte((*products)[i].Image), &imageList)
-		if err == nil && len(imageList) > 0 {
-			(*products)[i].Image = imageList[0]
-		} else {
-			(*products)[i].Image = ""
-		}
-	}
-}
-
-func removeDuplicateProducts(products []models.ProductListView) []models.ProductListView {
-	seen := make(map[uint]bool)
-	var result []models.ProductListView
-
-	for _, product := range products {
-		if !seen[product.ID] {
-			seen[product.ID] = true
-			result = append(result, product)
-		}
-	}
-
-	return result
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Recommendations berhasil diambil",
+		"data":    result.Products,
+		"metadata": gin.H{
+			"total":                len(result.Products),
+			"is_authenticated":     isAuthenticated,
+			"recommendation_basis": result.Basis,
+			"categories_used":      result.Categories,
+			"followed_shop_items":  result.Contributions[recommend.StrategyFollowedShops],
+			"similar_items":        result.Contributions[recommend.StrategyItemSimilarity],
+			"strategies":           result.Contributions,
+			"experiment":           assignment,
+		},
+	})
 }
 
-func getBasisDescription(isAuthenticated bool, hasCategories bool, hasUserID bool) string {
-	if !isAuthenticated {
-		return "best_rating_and_bestseller"
+// recommendationBucketKey - key pembagian arm: ID user jika login, selain itu visitor ID
+// (header X-Visitor-ID / cookie visitor_id), terakhir IP
+func recommendationBucketKey(c *gin.Context, isAuthenticated bool, userID uint) string {
+	if isAuthenticated {
+		return "user:" + strconv.FormatUint(uint64(userID), 10)
 	}
-
-	if hasCategories {
-		return "purchase_history_and_bestseller"
+	if visitor := visitorID(c, false); visitor != "" {
+		return "visitor:" + visitor
 	}
-
-	return "cart_categories_and_bestseller"
+	return "ip:" + c.ClientIP()
 }
 
 func GetCategories(c *gin.Context) {
@@ -425,6 +391,9 @@ func AddProductToCart(c *gin.Context) {
 		}
 
 		cartItem.Quantity = newQuantity
+		cartItem.PriceAtAdd = product.Price
+		cartItem.SavedForLater = false
+		cartItem.Selected = true
 		if err := db.Save(&cartItem).Error; err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart"})
 			return
@@ -436,15 +405,16 @@ func AddProductToCart(c *gin.Context) {
 				"cart_id":      cartItem.ID,
 				"product_id":   cartItem.ProductID,
 				"new_quantity": cartItem.Quantity,
-				"total_price":  float64(cartItem.Quantity) * product.Price,
+				"total_price":  utils.PriceProductLine(db, userID, product, cartItem.Quantity),
 			},
 		})
 	} else if errors.Is(err, gorm.ErrRecordNotFound) {
 		// Kalau belum ada → buat baru
 		newItem := models.ProductUserCart{
-			UserID:    userID,
-			ProductID: uint(productIDUint),
-			Quantity:  input.Quantity,
+			UserID:     userID,
+			ProductID:  uint(productIDUint),
+			Quantity:   input.Quantity,
+			PriceAtAdd: product.Price,
 		}
 
 		if err := db.Create(&newItem).Error; err != nil {
@@ -458,7 +428,7 @@ func AddProductToCart(c *gin.Context) {
 				"cart_id":     newItem.ID,
 				"product_id":  newItem.ProductID,
 				"quantity":    newItem.Quantity,
-				"total_price": float64(newItem.Quantity) * product.Price,
+				"total_price": utils.PriceProductLine(db, userID, product, newItem.Quantity),
 			},
 		})
 	} else {
@@ -473,13 +443,17 @@ func GetUserCart(c *gin.Context) {
 
 	var cartItems []struct {
 		models.ProductUserCart
-		ProductName  string  `json:"product_name"`
-		ProductPrice float64 `json:"product_price"`
-		ProductImage string  `json:"product_image"`
-		ShopName     string  `json:"shop_name"`
-		IsActive     bool    `json:"is_active"`
-		Stock        uint    `json:"stock"`
-		TotalPrice   float64 `json:"total_price"`
+		ProductName  string      `json:"product_name"`
+		ProductPrice money.Money `json:"product_price"`
+		ProductImage string      `json:"product_image"`
+		ShopName     string      `json:"shop_name"`
+		IsActive     bool        `json:"is_active"`
+		Stock        uint        `json:"stock"`
+		OnHoliday    bool        `json:"seller_on_holiday"`
+		TotalPrice   money.Money `json:"total_price" gorm:"-"`
+
+		Pricing  *pricing.LineResult `json:"pricing,omitempty" gorm:"-"`
+		Warnings []utils.CartWarning `json:"warnings" gorm:"-"`
 	}
 
 	err := db.Table("product_user_carts").
@@ -490,7 +464,7 @@ func GetUserCart(c *gin.Context) {
 				products.is_active,
 				products.stock,
 				seller_profiles.shop_name,
-				(product_user_carts.quantity * products.price) as total_price`).
+				COALESCE(seller_profiles.on_holiday, false) as on_holiday`).
 		Joins("LEFT JOIN products ON product_user_carts.product_id = products.id").
 		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
 		Where("product_user_carts.user_id = ?", userID).
@@ -502,33 +476,77 @@ func GetUserCart(c *gin.Context) {
 		return
 	}
 
-	// Process product images and calculate totals
-	var totalItems uint = 0
-	var totalPrice float64 = 0
+	// Semua angka uang dihitung oleh pricing engine (hanya produk aktif)
+	result, err := utils.PriceUserCart(db, userID, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to price cart"})
+		return
+	}
+	lines := make(map[uint]*pricing.LineResult, len(result.Lines))
+	for i := range result.Lines {
+		lines[result.Lines[i].CartID] = &result.Lines[i]
+	}
 
+	var totalItems uint = 0
+	warningCount := 0
+	selectedCount := 0
+	active := cartItems[:0:0]
+	saved := cartItems[:0:0]
 	for i := range cartItems {
 		// Process first image
 		if cartItems[i].ProductImage != "" {
 			var imageList []string
 			if json.Unmarshal([]byte(cartItems[i].ProductImage), &imageList) == nil && len(imageList) > 0 {
-				cartItems[i].ProductImage = imageList[0]
+				cartItems[i].ProductImage = utils.ThumbnailURL(imageList[0])
 			}
 		}
 
-		// Only count active products
-		if cartItems[i].IsActive {
+		if line, ok := lines[cartItems[i].ID]; ok {
+			cartItems[i].Pricing = line
+			cartItems[i].TotalPrice = line.Total
 			totalItems += cartItems[i].Quantity
-			totalPrice += cartItems[i].TotalPrice
 		}
+
+		// Perubahan sejak item ditambahkan, diterima lewat POST /user/cart/refresh
+		cartItems[i].Warnings = utils.CartLineWarnings(utils.CartLineState{
+			Quantity:   cartItems[i].Quantity,
+			PriceAtAdd: cartItems[i].PriceAtAdd,
+			Price:      cartItems[i].ProductPrice,
+			Stock:      cartItems[i].Stock,
+			IsActive:   cartItems[i].IsActive,
+			OnHoliday:  cartItems[i].OnHoliday,
+		})
+		warningCount += len(cartItems[i].Warnings)
+
+		if cartItems[i].SavedForLater {
+			saved = append(saved, cartItems[i])
+			continue
+		}
+		if cartItems[i].Selected {
+			selectedCount++
+		}
+		active = append(active, cartItems[i])
 	}
 
+	// Total hanya untuk item aktif yang dicentang, item saved_for_later ditampilkan terpisah
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Cart berhasil diambil",
-		"data":    cartItems,
+		"message":         "Cart berhasil diambil",
+		"data":            active,
+		"saved_for_later": saved,
 		"summary": gin.H{
-			"total_items": totalItems,
-			"total_price": totalPrice,
-			"item_count":  len(cartItems),
+			"total_items":    totalItems,
+			"total_price":    result.Subtotal,
+			"item_count":     len(active),
+			"selected_count": selectedCount,
+			"saved_count":    len(saved),
+			"warning_count":  warningCount,
+			"discount":       result.Discount,
+			"tax":            result.Tax,
+			"tax_included":   result.TaxIncluded,
+			"grand_total":    result.GrandTotal,
+			"voucher":        result.Voucher,
+			"sellers":        result.Sellers,
+			"trace":          result.Trace,
 		},
 	})
 }
@@ -588,11 +606,39 @@ func UpdateCartItem(c *gin.Context) {
 			"cart_id":      cartItem.ID,
 			"product_id":   cartItem.ProductID,
 			"new_quantity": cartItem.Quantity,
-			"total_price":  float64(cartItem.Quantity) * product.Price,
+			"total_price":  utils.PriceProductLine(db, userID, product, cartItem.Quantity),
 		},
 	})
 }
 
+// RefreshCart - menerima perubahan harga dan stok yang dilaporkan di warnings GetUserCart.
+// {"remove_unavailable": true} sekaligus menghapus produk tidak aktif, stok habis dan toko libur.
+func RefreshCart(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	var input struct {
+		RemoveUnavailable bool `json:"remove_unavailable"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	changes, err := utils.RefreshUserCart(db, userID, input.RemoveUnavailable)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh cart"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Cart refreshed",
+		"data":    changes,
+	})
+}
+
 func RemoveFromCart(c *gin.Context) {
 	db := c.MustGet("db").(*gorm.DB)
 	userID := c.MustGet("id").(uint)
@@ -633,3 +679,70 @@ func ClearCart(c *gin.Context) {
 		"message": "Cart cleared successfully",
 	})
 }
+
+// userDestination - tujuan pengiriman user, menulis response error jika alamat tidak ada
+func userDestination(c *gin.Context, db *gorm.DB, userID, addressID uint) (utils.Destination, bool) {
+	destination, err := utils.GetUserDestination(db, userID, addressID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Address not found"})
+		} else if errors.Is(err, utils.ErrNoDestination) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tambahkan alamat pengiriman terlebih dahulu"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		}
+		return destination, false
+	}
+	return destination, true
+}
+
+// GetCartShippingQuotes - opsi ongkir per seller untuk isi keranjang user.
+// Tujuan memakai alamat default, atau ?address_id= untuk alamat lain.
+func GetCartShippingQuotes(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	var addressID uint
+	if raw := c.Query("address_id"); raw != "" {
+		parsed, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid address ID"})
+			return
+		}
+		addressID = uint(parsed)
+	}
+
+	destination, ok := userDestination(c, db, userID, addressID)
+	if !ok {
+		return
+	}
+
+	quotes, err := utils.GetCartShippingQuotes(c.Request.Context(), db, shipping.DefaultProvider(), userID, destination.City)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get shipping quotes"})
+		return
+	}
+
+	// Estimasi total memakai opsi ongkir termurah tiap seller
+	cheapest := make(map[uint]int64)
+	for _, quote := range quotes {
+		if len(quote.Options) > 0 {
+			cheapest[quote.SellerID] = quote.Options[0].Price.Amount
+		}
+	}
+	estimate, err := utils.PriceUserCart(db, userID, cheapest)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to price cart"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Shipping quotes berhasil diambil",
+		"data":    quotes,
+		"summary": gin.H{
+			"destination":  destination,
+			"seller_count": len(quotes),
+			"estimate":     estimate,
+		},
+	})
+}
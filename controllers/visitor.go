@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// Pengunjung anonim dikenali dari header X-Visitor-ID (aplikasi mobile) atau cookie visitor_id
+const (
+	visitorHeader = "X-Visitor-ID"
+	visitorCookie = "visitor_id"
+)
+
+// visitorID - ID pengunjung anonim. Jika belum ada dan create true, dibuatkan cookie baru,
+// kecuali browser mengirim Do Not Track / Global Privacy Control.
+func visitorID(c *gin.Context, create bool) string {
+	if id := c.GetHeader(visitorHeader); id != "" && len(id) <= 64 {
+		return id
+	}
+	if id, err := c.Cookie(visitorCookie); err == nil && id != "" && len(id) <= 64 {
+		return id
+	}
+	if !create || !trackingAllowed(c) {
+		return ""
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	id := hex.EncodeToString(buf)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(visitorCookie, id, 365*24*60*60, "/", "", c.Request.TLS != nil, true)
+	return id
+}
+
+// trackingAllowed - false jika browser meminta tidak dilacak (DNT: 1 atau Sec-GPC: 1)
+func trackingAllowed(c *gin.Context) bool {
+	return c.GetHeader("DNT") != "1" && c.GetHeader("Sec-GPC") != "1"
+}
+
+// optionalUserID - ID user dari OptionalAuthMiddleware, hanya untuk token user (bukan seller)
+func optionalUserID(c *gin.Context) (uint, bool) {
+	value, exists := c.Get("id")
+	if !exists || c.GetString("user_type") != "user" {
+		return 0, false
+	}
+	switch v := value.(type) {
+	case uint:
+		return v, true
+	case int:
+		return uint(v), true
+	case float64:
+		return uint(v), true
+	}
+	return 0, false
+}
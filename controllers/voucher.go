@@ -0,0 +1,215 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/money"
+	"ecommerce-golang/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+type voucherInput struct {
+	Code         string    `json:"code" binding:"required,max=50"`
+	Name         string    `json:"name" binding:"required,max=255"`
+	Description  string    `json:"description"`
+	Type         string    `json:"type" binding:"required"`
+	Value        float64   `json:"value"`
+	MinSpend     float64   `json:"min_spend"`
+	MaxDiscount  float64   `json:"max_discount"`
+	Scope        string    `json:"scope"`
+	SellerID     *uint     `json:"seller_id"`
+	Category     string    `json:"category"`
+	StartsAt     time.Time `json:"starts_at" binding:"required"`
+	EndsAt       time.Time `json:"ends_at" binding:"required"`
+	UsageLimit   uint      `json:"usage_limit"`
+	PerUserLimit *uint     `json:"per_user_limit"`
+	IsActive     *bool     `json:"is_active"`
+}
+
+func (input voucherInput) toVoucher() models.Voucher {
+	voucher := models.Voucher{
+		Code:         input.Code,
+		Name:         input.Name,
+		Description:  input.Description,
+		Type:         input.Type,
+		Value:        input.Value,
+		MinSpend:     money.FromFloat(input.MinSpend),
+		MaxDiscount:  money.FromFloat(input.MaxDiscount),
+		Scope:        input.Scope,
+		SellerID:     input.SellerID,
+		Category:     input.Category,
+		StartsAt:     input.StartsAt,
+		EndsAt:       input.EndsAt,
+		UsageLimit:   input.UsageLimit,
+		PerUserLimit: 1,
+		IsActive:     true,
+	}
+	if input.PerUserLimit != nil {
+		voucher.PerUserLimit = *input.PerUserLimit
+	}
+	if input.IsActive != nil {
+		voucher.IsActive = *input.IsActive
+	}
+	return voucher
+}
+
+// CreateSellerVoucher - voucher toko, hanya berlaku untuk produk seller tersebut
+// (bisa dibatasi lagi ke satu kategori)
+func CreateSellerVoucher(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var input voucherInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	voucher := input.toVoucher()
+	voucher.Scope = models.VoucherScopeSeller
+	voucher.SellerID = &sellerID
+
+	saveVoucher(c, db, &voucher)
+}
+
+func GetSellerVouchers(c *gin.Context) {
+	sellerID := c.MustGet("id").(uint)
+	db := c.MustGet("db").(*gorm.DB)
+
+	var vouchers []models.Voucher
+	if err := db.Where("seller_id = ?", sellerID).Order("created_at DESC").Find(&vouchers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil voucher"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Voucher berhasil diambil",
+		"data":    vouchers,
+		"total":   len(vouchers),
+	})
+}
+
+// CreatePlatformVoucher - voucher dari platform (semua produk, atau per kategori)
+func CreatePlatformVoucher(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	var input voucherInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if input.Scope == "" {
+		input.Scope = models.VoucherScopePlatform
+	}
+
+	voucher := input.toVoucher()
+	saveVoucher(c, db, &voucher)
+}
+
+func GetPlatformVouchers(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+
+	query := db.Model(&models.Voucher{})
+	if scope := c.Query("scope"); scope != "" {
+		query = query.Where("scope = ?", scope)
+	}
+
+	var vouchers []models.Voucher
+	if err := query.Order("created_at DESC").Find(&vouchers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil voucher"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Voucher berhasil diambil",
+		"data":    vouchers,
+		"total":   len(vouchers),
+	})
+}
+
+func saveVoucher(c *gin.Context, db *gorm.DB, voucher *models.Voucher) {
+	if err := utils.ValidateVoucher(voucher); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var count int64
+	db.Model(&models.Voucher{}).Where("code = ?", voucher.Code).Count(&count)
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Voucher code already exists"})
+		return
+	}
+
+	if err := db.Create(voucher).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat voucher"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Voucher created",
+		"data":    voucher,
+	})
+}
+
+// ApplyCartVoucher - pasang voucher ke keranjang, kuota voucher langsung diklaim
+func ApplyCartVoucher(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	var input struct {
+		Code string `json:"code" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	voucher, pricing, err := utils.ApplyVoucherToCart(db, userID, input.Code, time.Now())
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, utils.ErrVoucherNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, utils.ErrVoucherExhausted), errors.Is(err, utils.ErrVoucherUserLimit):
+			status = http.StatusConflict
+		case errors.Is(err, utils.ErrVoucherInactive), errors.Is(err, utils.ErrVoucherNotStarted),
+			errors.Is(err, utils.ErrVoucherExpired), errors.Is(err, utils.ErrVoucherNotApplicable),
+			errors.Is(err, utils.ErrVoucherMinSpend):
+			status = http.StatusUnprocessableEntity
+		}
+		if status == http.StatusInternalServerError {
+			c.JSON(status, gin.H{"error": "Gagal memasang voucher"})
+		} else {
+			c.JSON(status, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Voucher applied",
+		"data": gin.H{
+			"voucher": voucher,
+			"pricing": pricing,
+		},
+	})
+}
+
+// RemoveCartVoucher - lepas voucher dari keranjang, kuota dikembalikan
+func RemoveCartVoucher(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	if err := utils.RemoveVoucherFromCart(db, userID); err != nil {
+		if errors.Is(err, utils.ErrVoucherNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No voucher applied"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal melepas voucher"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Voucher removed"})
+}
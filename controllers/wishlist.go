@@ -0,0 +1,417 @@
+package controllers
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// GetUserWishlist - semua produk di wishlist, bisa difilter ?collection_id= (0 = tanpa koleksi)
+func GetUserWishlist(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	query := db.Where("user_id = ?", userID)
+	if collection := c.Query("collection_id"); collection != "" {
+		if collection == "0" {
+			query = query.Where("collection_id IS NULL")
+		} else {
+			query = query.Where("collection_id = ?", collection)
+		}
+	}
+
+	var items []models.WishlistItem
+	if err := query.Order("created_at DESC").Find(&items).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil wishlist"})
+		return
+	}
+
+	productIDs := make([]uint, len(items))
+	for i, item := range items {
+		productIDs[i] = item.ProductID
+	}
+
+	var products []models.ProductListView
+	var availability []struct {
+		ID       uint
+		IsActive bool
+		Stock    uint
+	}
+	if len(productIDs) > 0 {
+		if err := db.Table("products").
+			Select(`products.id, products.name, products.price, products.rating,
+                products.total_sold, products.images as image,
+                seller_profiles.shop_name, seller_profiles.city`).
+			Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
+			Where("products.id IN ?", productIDs).
+			Find(&products).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil wishlist"})
+			return
+		}
+		utils.ProcessProductImages(&products)
+		utils.ApplyFlashSalePrices(db, products)
+		db.Table("products").Select("id, is_active, stock").Where("id IN ?", productIDs).Scan(&availability)
+	}
+
+	productByID := make(map[uint]models.ProductListView, len(products))
+	for _, product := range products {
+		productByID[product.ID] = product
+	}
+	availableByID := make(map[uint]bool, len(availability))
+	for _, row := range availability {
+		availableByID[row.ID] = row.IsActive && row.Stock > 0
+	}
+
+	data := make([]gin.H, 0, len(items))
+	for _, item := range items {
+		product, ok := productByID[item.ProductID]
+		if !ok {
+			continue
+		}
+		data = append(data, gin.H{
+			"id":            item.ID,
+			"collection_id": item.CollectionID,
+			"added_at":      item.CreatedAt,
+			"available":     availableByID[item.ProductID],
+			"product":       product,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Wishlist berhasil diambil",
+		"data":    data,
+		"total":   len(data),
+	})
+}
+
+// AddToWishlist - menyimpan produk ke wishlist. Jika produk sudah ada, koleksinya dipindah.
+func AddToWishlist(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	var input struct {
+		ProductID    uint  `json:"product_id" binding:"required"`
+		CollectionID *uint `json:"collection_id"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var product models.Product
+	if err := db.Where("id = ? AND is_active = ?", input.ProductID, true).First(&product).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if !checkWishlistCollection(c, db, userID, input.CollectionID) {
+		return
+	}
+
+	var item models.WishlistItem
+	err := db.Where("user_id = ? AND product_id = ?", userID, input.ProductID).First(&item).Error
+	if err == nil {
+		item.CollectionID = input.CollectionID
+		err = db.Model(&item).Update("collection_id", input.CollectionID).Error
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		item = models.WishlistItem{
+			UserID:       userID,
+			ProductID:    input.ProductID,
+			CollectionID: input.CollectionID,
+		}
+		err = db.Create(&item).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan wishlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Product saved to wishlist",
+		"data":    item,
+	})
+}
+
+// MoveWishlistItem - memindahkan item ke koleksi lain (collection_id null = keluarkan dari koleksi)
+func MoveWishlistItem(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	var input struct {
+		CollectionID *uint `json:"collection_id"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !checkWishlistCollection(c, db, userID, input.CollectionID) {
+		return
+	}
+
+	var item models.WishlistItem
+	if err := db.Where("id = ? AND user_id = ?", c.Param("item_id"), userID).First(&item).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wishlist item not found"})
+		return
+	}
+	if err := db.Model(&item).Update("collection_id", input.CollectionID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memindahkan wishlist"})
+		return
+	}
+	item.CollectionID = input.CollectionID
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Wishlist item moved",
+		"data":    item,
+	})
+}
+
+func RemoveFromWishlist(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	result := db.Where("id = ? AND user_id = ?", c.Param("item_id"), userID).Delete(&models.WishlistItem{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus wishlist"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Wishlist item not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Product removed from wishlist"})
+}
+
+// GetWishlistCollections - koleksi wishlist beserta jumlah item
+func GetWishlistCollections(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	var collections []struct {
+		models.WishlistCollection
+		ItemCount int64 `json:"item_count"`
+	}
+	err := db.Table("wishlist_collections").
+		Select("wishlist_collections.*, COUNT(wishlist_items.id) as item_count").
+		Joins("LEFT JOIN wishlist_items ON wishlist_items.collection_id = wishlist_collections.id").
+		Where("wishlist_collections.user_id = ?", userID).
+		Group("wishlist_collections.id").
+		Order("wishlist_collections.created_at ASC").
+		Find(&collections).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil koleksi wishlist"})
+		return
+	}
+
+	var uncollected int64
+	db.Model(&models.WishlistItem{}).Where("user_id = ? AND collection_id IS NULL", userID).Count(&uncollected)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Koleksi wishlist berhasil diambil",
+		"data":              collections,
+		"uncollected_count": uncollected,
+	})
+}
+
+func CreateWishlistCollection(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	var input struct {
+		Name string `json:"name" binding:"required,max=100"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection := models.WishlistCollection{UserID: userID, Name: strings.TrimSpace(input.Name)}
+	if collection.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+	if wishlistCollectionNameTaken(db, userID, collection.Name, 0) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Collection name already used"})
+		return
+	}
+	if err := db.Create(&collection).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat koleksi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Collection created",
+		"data":    collection,
+	})
+}
+
+func RenameWishlistCollection(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	var input struct {
+		Name string `json:"name" binding:"required,max=100"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var collection models.WishlistCollection
+	if err := db.Where("id = ? AND user_id = ?", c.Param("collection_id"), userID).First(&collection).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+	if wishlistCollectionNameTaken(db, userID, name, collection.ID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Collection name already used"})
+		return
+	}
+	if err := db.Model(&collection).Update("name", name).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengubah koleksi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Collection renamed",
+		"data":    collection,
+	})
+}
+
+// DeleteWishlistCollection - menghapus koleksi, item di dalamnya tetap ada di wishlist tanpa koleksi
+func DeleteWishlistCollection(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	var collection models.WishlistCollection
+	if err := db.Where("id = ? AND user_id = ?", c.Param("collection_id"), userID).First(&collection).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.WishlistItem{}).
+			Where("collection_id = ?", collection.ID).
+			Update("collection_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&collection).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus koleksi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collection deleted"})
+}
+
+func checkWishlistCollection(c *gin.Context, db *gorm.DB, userID uint, collectionID *uint) bool {
+	if err := utils.CheckUserCollection(db, userID, collectionID); err != nil {
+		if errors.Is(err, utils.ErrCollectionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		}
+		return false
+	}
+	return true
+}
+
+func wishlistCollectionNameTaken(db *gorm.DB, userID uint, name string, exceptID uint) bool {
+	var count int64
+	db.Model(&models.WishlistCollection{}).
+		Where("user_id = ? AND name = ? AND id <> ?", userID, name, exceptID).
+		Count(&count)
+	return count > 0
+}
+
+// FollowShop - mengikuti toko
+func FollowShop(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	sellerID, err := strconv.ParseUint(c.Param("seller_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid seller ID"})
+		return
+	}
+
+	created, err := utils.FollowShop(db, userID, uint(sellerID))
+	if err != nil {
+		if errors.Is(err, utils.ErrShopNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Shop not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal follow toko"})
+		}
+		return
+	}
+
+	message := "Shop followed"
+	if !created {
+		message = "Already following this shop"
+	}
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+func UnfollowShop(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	sellerID, err := strconv.ParseUint(c.Param("seller_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid seller ID"})
+		return
+	}
+
+	removed, err := utils.UnfollowShop(db, userID, uint(sellerID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal unfollow toko"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not following this shop"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Shop unfollowed"})
+}
+
+// GetFollowedShops - toko yang diikuti user
+func GetFollowedShops(c *gin.Context) {
+	db := c.MustGet("db").(*gorm.DB)
+	userID := c.MustGet("id").(uint)
+
+	var shops []struct {
+		SellerID      uint   `json:"seller_id"`
+		ShopName      string `json:"shop_name"`
+		ShopLogo      string `json:"shop_logo"`
+		City          string `json:"city"`
+		FollowerCount uint   `json:"follower_count"`
+	}
+	err := db.Table("shop_follows").
+		Select(`seller_profiles.seller_id, seller_profiles.shop_name, seller_profiles.shop_logo,
+				seller_profiles.city, seller_profiles.follower_count`).
+		Joins("JOIN seller_profiles ON seller_profiles.seller_id = shop_follows.seller_id").
+		Where("shop_follows.user_id = ?", userID).
+		Order("shop_follows.created_at DESC").
+		Scan(&shops).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil toko"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Toko yang diikuti berhasil diambil",
+		"data":    shops,
+		"total":   len(shops),
+	})
+}
@@ -0,0 +1,77 @@
+// invoice/document.go
+package invoice
+
+import (
+	"ecommerce-golang/money"
+	"time"
+)
+
+// Document - isi invoice yang siap dirender ke HTML atau PDF.
+// Package ini tidak bergantung ke database, pemanggil yang menyusun Document dari data invoice.
+type Document struct {
+	Title    string
+	Number   string
+	IssuedAt time.Time
+	Seller   Party
+	Buyer    Party
+	Items    []Item
+	TaxLines []TaxLine
+
+	Subtotal         money.Money
+	Discount         money.Money // potongan voucher
+	Shipping         money.Money // ongkos kirim
+	ShippingDiscount money.Money // subsidi ongkir dari voucher
+	TaxBase          money.Money // DPP
+	Tax              money.Money
+	TaxIncluded      money.Money // bagian PPN yang sudah termasuk di harga
+	Total            money.Money
+
+	Notes []string
+}
+
+// Party - penjual atau pembeli
+type Party struct {
+	Name    string
+	Address string
+	Email   string
+	NPWP    string
+}
+
+type Item struct {
+	Name         string
+	Quantity     uint
+	UnitPrice    money.Money
+	Subtotal     money.Money
+	TaxRate      string // contoh "11%", kosong jika tidak kena PPN
+	TaxInclusive bool
+	Tax          money.Money
+}
+
+// TaxLine - ringkasan PPN per tarif
+type TaxLine struct {
+	Rate      string
+	Inclusive bool
+	Base      money.Money
+	Tax       money.Money
+}
+
+// Filename - nama file download, contoh INV-12-2026-000001.pdf
+func (d Document) Filename(ext string) string {
+	name := make([]byte, 0, len(d.Number))
+	for i := 0; i < len(d.Number); i++ {
+		ch := d.Number[i]
+		switch {
+		case ch >= '0' && ch <= '9', ch >= 'A' && ch <= 'Z', ch >= 'a' && ch <= 'z', ch == '-':
+			name = append(name, ch)
+		default:
+			name = append(name, '-')
+		}
+	}
+	return string(name) + "." + ext
+}
+
+func formatDate(t time.Time) string {
+	months := [...]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli",
+		"Agustus", "September", "Oktober", "November", "Desember"}
+	return t.Format("2") + " " + months[t.Month()-1] + " " + t.Format("2006")
+}
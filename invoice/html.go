@@ -0,0 +1,92 @@
+// invoice/html.go
+package invoice
+
+import (
+	"html/template"
+	"io"
+)
+
+var htmlTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"date": formatDate,
+	"inc":  func(i int) int { return i + 1 },
+}).Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} {{.Number}}</title>
+<style>
+  body { font-family: Helvetica, Arial, sans-serif; font-size: 13px; color: #222; margin: 32px; }
+  h1 { font-size: 22px; margin: 0 0 4px; }
+  .meta { color: #555; margin-bottom: 24px; }
+  .parties { display: flex; gap: 48px; margin-bottom: 24px; }
+  .parties div { flex: 1; }
+  .label { font-weight: bold; text-transform: uppercase; font-size: 11px; color: #777; }
+  table { width: 100%; border-collapse: collapse; }
+  th, td { padding: 6px 8px; border-bottom: 1px solid #ddd; text-align: left; }
+  th { background: #f4f4f4; }
+  .num { text-align: right; white-space: nowrap; }
+  .totals { width: 50%; margin-left: auto; margin-top: 16px; }
+  .totals td { border: none; }
+  .grand td { font-weight: bold; border-top: 2px solid #222; }
+  .notes { margin-top: 24px; color: #555; font-size: 12px; }
+  @media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="meta">No. {{.Number}} &middot; {{date .IssuedAt}}</div>
+
+<div class="parties">
+  <div>
+    <div class="label">Penjual</div>
+    <div><strong>{{.Seller.Name}}</strong></div>
+    {{with .Seller.Address}}<div>{{.}}</div>{{end}}
+    {{with .Seller.NPWP}}<div>NPWP: {{.}}</div>{{end}}
+  </div>
+  <div>
+    <div class="label">Pembeli</div>
+    <div><strong>{{.Buyer.Name}}</strong></div>
+    {{with .Buyer.Email}}<div>{{.}}</div>{{end}}
+    {{with .Buyer.Address}}<div>{{.}}</div>{{end}}
+  </div>
+</div>
+
+<table>
+  <thead>
+    <tr><th>No</th><th>Produk</th><th class="num">Qty</th><th class="num">Harga</th><th class="num">PPN</th><th class="num">Jumlah</th></tr>
+  </thead>
+  <tbody>
+  {{range $i, $item := .Items}}
+    <tr>
+      <td>{{inc $i}}</td>
+      <td>{{$item.Name}}</td>
+      <td class="num">{{$item.Quantity}}</td>
+      <td class="num">{{$item.UnitPrice}}</td>
+      <td class="num">{{if $item.TaxRate}}{{$item.TaxRate}}{{if $item.TaxInclusive}} (termasuk){{end}}{{else}}-{{end}}</td>
+      <td class="num">{{$item.Subtotal}}</td>
+    </tr>
+  {{end}}
+  </tbody>
+</table>
+
+<table class="totals">
+  <tr><td>Subtotal</td><td class="num">{{.Subtotal}}</td></tr>
+  {{if .Discount.Amount}}<tr><td>Diskon voucher</td><td class="num">-{{.Discount}}</td></tr>{{end}}
+  {{if .Shipping.Amount}}<tr><td>Ongkos kirim</td><td class="num">{{.Shipping}}</td></tr>{{end}}
+  {{if .ShippingDiscount.Amount}}<tr><td>Subsidi ongkir</td><td class="num">-{{.ShippingDiscount}}</td></tr>{{end}}
+  {{range .TaxLines}}
+  <tr><td>DPP PPN {{.Rate}}{{if .Inclusive}} (termasuk dalam harga){{end}}</td><td class="num">{{.Base}}</td></tr>
+  <tr><td>PPN {{.Rate}}</td><td class="num">{{.Tax}}</td></tr>
+  {{end}}
+  <tr class="grand"><td>Total</td><td class="num">{{.Total}}</td></tr>
+</table>
+
+{{if .Notes}}<div class="notes">{{range .Notes}}<div>{{.}}</div>{{end}}</div>{{end}}
+</body>
+</html>
+`))
+
+// RenderHTML - invoice dalam bentuk halaman HTML yang bisa dicetak dari browser
+func RenderHTML(w io.Writer, doc Document) error {
+	return htmlTemplate.Execute(w, doc)
+}
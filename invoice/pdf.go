@@ -0,0 +1,299 @@
+// invoice/pdf.go
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RenderPDF - invoice dalam bentuk PDF A4. PDF ditulis langsung (PDF 1.4, font standar
+// Helvetica dengan WinAnsiEncoding) tanpa library atau layanan eksternal.
+func RenderPDF(doc Document) ([]byte, error) {
+	p := newPDF(doc.Title + " " + doc.Number)
+	p.newPage()
+
+	p.text(pdfMargin, p.y, fontBold, 20, doc.Title)
+	p.textRight(pdfRight, p.y, fontBold, 11, doc.Number)
+	p.y -= 16
+	p.textRight(pdfRight, p.y, fontRegular, 10, formatDate(doc.IssuedAt))
+	p.y -= 28
+
+	top := p.y
+	sellerBottom := p.party(pdfMargin, top, "PENJUAL", doc.Seller)
+	buyerBottom := p.party(310, top, "PEMBELI", doc.Buyer)
+	p.y = min(sellerBottom, buyerBottom) - 16
+
+	p.tableHeader()
+	for i, item := range doc.Items {
+		if p.y < pdfMargin+40 {
+			p.newPage()
+			p.tableHeader()
+		}
+		tax := "-"
+		if item.TaxRate != "" {
+			tax = item.TaxRate
+			if item.TaxInclusive {
+				tax += " (incl)"
+			}
+		}
+		p.text(pdfMargin+4, p.y, fontRegular, 9, strconv.Itoa(i+1))
+		p.text(colProduct, p.y, fontRegular, 9, truncate(item.Name, fontRegular, 9, colQty-colProduct-40))
+		p.textRight(colQty, p.y, fontRegular, 9, strconv.FormatUint(uint64(item.Quantity), 10))
+		p.textRight(colPrice, p.y, fontRegular, 9, item.UnitPrice.String())
+		p.textRight(colTax, p.y, fontRegular, 9, tax)
+		p.textRight(pdfRight, p.y, fontRegular, 9, item.Subtotal.String())
+		p.y -= 6
+		p.line(pdfMargin, p.y, pdfRight, p.y, 0.3)
+		p.y -= 12
+	}
+
+	// Ringkasan total di kanan bawah
+	rows := [][2]string{{"Subtotal", doc.Subtotal.String()}}
+	if doc.Discount.Amount > 0 {
+		rows = append(rows, [2]string{"Diskon voucher", "-" + doc.Discount.String()})
+	}
+	if doc.Shipping.Amount > 0 {
+		rows = append(rows, [2]string{"Ongkos kirim", doc.Shipping.String()})
+	}
+	if doc.ShippingDiscount.Amount > 0 {
+		rows = append(rows, [2]string{"Subsidi ongkir", "-" + doc.ShippingDiscount.String()})
+	}
+	for _, tax := range doc.TaxLines {
+		label := "DPP PPN " + tax.Rate
+		if tax.Inclusive {
+			label += " (termasuk dalam harga)"
+		}
+		rows = append(rows, [2]string{label, tax.Base.String()}, [2]string{"PPN " + tax.Rate, tax.Tax.String()})
+	}
+	if p.y < pdfMargin+float64(len(rows)+2)*16 {
+		p.newPage()
+	}
+	p.y -= 8
+	for _, row := range rows {
+		p.text(320, p.y, fontRegular, 10, row[0])
+		p.textRight(pdfRight, p.y, fontRegular, 10, row[1])
+		p.y -= 16
+	}
+	p.line(320, p.y+11, pdfRight, p.y+11, 1)
+	p.y -= 2
+	p.text(320, p.y, fontBold, 11, "Total")
+	p.textRight(pdfRight, p.y, fontBold, 11, doc.Total.String())
+	p.y -= 28
+
+	for _, note := range doc.Notes {
+		if p.y < pdfMargin {
+			p.newPage()
+		}
+		p.text(pdfMargin, p.y, fontRegular, 8, note)
+		p.y -= 12
+	}
+
+	for i, page := range p.pages {
+		footer := fmt.Sprintf("%s - halaman %d/%d", doc.Number, i+1, len(p.pages))
+		p.page = page
+		p.textRight(pdfRight, pdfMargin-16, fontRegular, 8, footer)
+	}
+
+	return p.bytes(), nil
+}
+
+const (
+	pdfPageWidth  = 595.28 // A4 dalam point
+	pdfPageHeight = 841.89
+	pdfMargin     = 40.0
+	pdfRight      = pdfPageWidth - pdfMargin
+
+	colProduct = pdfMargin + 28
+	colQty     = 340.0
+	colPrice   = 420.0
+	colTax     = 480.0
+
+	fontRegular = "F1"
+	fontBold    = "F2"
+)
+
+func (p *pdfWriter) party(x, y float64, label string, party Party) float64 {
+	p.text(x, y, fontBold, 8, label)
+	y -= 14
+	p.text(x, y, fontBold, 10, party.Name)
+	y -= 13
+	for _, line := range []string{party.Email, party.Address} {
+		for _, wrapped := range wrap(line, fontRegular, 9, 240) {
+			p.text(x, y, fontRegular, 9, wrapped)
+			y -= 12
+		}
+	}
+	if party.NPWP != "" {
+		p.text(x, y, fontRegular, 9, "NPWP: "+party.NPWP)
+		y -= 12
+	}
+	return y
+}
+
+func (p *pdfWriter) tableHeader() {
+	p.fillRect(pdfMargin, p.y-5, pdfRight-pdfMargin, 17, 0.93)
+	p.text(pdfMargin+4, p.y, fontBold, 9, "No")
+	p.text(colProduct, p.y, fontBold, 9, "Produk")
+	p.textRight(colQty, p.y, fontBold, 9, "Qty")
+	p.textRight(colPrice, p.y, fontBold, 9, "Harga")
+	p.textRight(colTax, p.y, fontBold, 9, "PPN")
+	p.textRight(pdfRight, p.y, fontBold, 9, "Jumlah")
+	p.y -= 20
+}
+
+// pdfWriter - penulis PDF minimal: halaman A4, teks, garis dan kotak
+type pdfWriter struct {
+	title string
+	pages []*bytes.Buffer
+	page  *bytes.Buffer
+	y     float64
+}
+
+func newPDF(title string) *pdfWriter {
+	return &pdfWriter{title: title}
+}
+
+func (p *pdfWriter) newPage() {
+	p.page = &bytes.Buffer{}
+	p.pages = append(p.pages, p.page)
+	p.y = pdfPageHeight - pdfMargin - 12
+}
+
+func (p *pdfWriter) text(x, y float64, font string, size float64, s string) {
+	fmt.Fprintf(p.page, "BT /%s %s Tf %s %s Td (%s) Tj ET\n", font, num(size), num(x), num(y), pdfString(s))
+}
+
+func (p *pdfWriter) textRight(right, y float64, font string, size float64, s string) {
+	p.text(right-textWidth(s, font, size), y, font, size, s)
+}
+
+func (p *pdfWriter) line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(p.page, "%s w %s %s m %s %s l S\n", num(width), num(x1), num(y1), num(x2), num(y2))
+}
+
+func (p *pdfWriter) fillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(p.page, "q %s g %s %s %s %s re f Q\n", num(gray), num(x), num(y), num(w), num(h))
+}
+
+// bytes - menyusun object PDF dan tabel xref
+func (p *pdfWriter) bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 catalog, 2 pages, 3-4 font, 5 info, lalu pasangan page + content
+	const firstPage = 6
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+i*2)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (ecommerce-golang) >>", pdfString(p.title)))
+
+	for i, page := range p.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			num(pdfPageWidth), num(pdfPageHeight), firstPage+i*2+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+func num(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// pdfString - teks ke WinAnsi (Latin-1), karakter di luar itu diganti "?"
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n' || r == '\r' || r == '\t':
+			b.WriteByte(' ')
+		case r >= 32 && r < 127:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// helveticaWidths - lebar glyph Helvetica (per 1000 unit) untuk ASCII 32-126.
+// Helvetica-Bold sedikit lebih lebar untuk huruf, tapi angka sama sehingga kolom nominal tetap rata kanan.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+func textWidth(s, font string, size float64) float64 {
+	total := 0
+	for _, r := range s {
+		width := 556
+		if r >= 32 && r < 127 {
+			width = helveticaWidths[r-32]
+		}
+		if font == fontBold && !(r >= '0' && r <= '9') {
+			width = width * 106 / 100
+		}
+		total += width
+	}
+	return float64(total) * size / 1000
+}
+
+func truncate(s, font string, size, maxWidth float64) string {
+	if textWidth(s, font, size) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && textWidth(string(runes)+"...", font, size) > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+func wrap(s, font string, size, maxWidth float64) []string {
+	var lines []string
+	var current string
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if current != "" && textWidth(candidate, font, size) > maxWidth {
+			lines = append(lines, current)
+			candidate = word
+		}
+		current = candidate
+	}
+	if current != "" {
+		lines = append(lines, truncate(current, font, size, maxWidth))
+	}
+	return lines
+}
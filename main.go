@@ -4,6 +4,8 @@ import (
 	"ecommerce-golang/config"
 	"ecommerce-golang/middleware"
 	"ecommerce-golang/routes"
+	"ecommerce-golang/storage"
+	"ecommerce-golang/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"log"
@@ -16,8 +18,30 @@ func main() {
 	}
 
 	db := config.ConnectDB()
+	store := config.ConnectStorage()
+	config.ConnectTrackers(config.ConnectShippingRates())
+	config.ConnectFXRates()
+	limiterStore := config.ConnectLimiterStore()
+	middleware.SetLimiterStore(limiterStore)
+	config.ConnectRateLimitPolicies(limiterStore)
+	config.ConnectLoadShedding()
+	utils.StartShipmentAutoCompleteRoutine(db, config.ShipmentAutoCompleteGrace())
+	utils.StartFlashSaleReservationCleanup(db)
+	utils.StartVoucherClaimCleanup(db)
+	utils.StartGuestCartCleanup(db)
+	utils.StartViewRecorder(db)
+	utils.StartViewHistoryCleanup(db)
+	utils.StartTrendingRefresh(db)
 	r := gin.Default()
+	config.ConfigureTrustedProxies(r)
 	r.Use(middleware.InjectDB(db))
+	r.Use(middleware.InjectStorage(store))
+	r.Use(middleware.DisplayCurrencyMiddleware())
+
+	// Serve file upload kalau memakai local storage
+	if local, ok := store.(*storage.LocalStorage); ok {
+		r.Static(local.URLPrefix, local.BaseDir)
+	}
 
 	routes.AuthRoutes(r, db) // Daftarkan routes, dan pilih tingakatn rate
 	err = r.Run(":8080")     // Jalankan router yang sudah ada routes-nya
@@ -0,0 +1,37 @@
+// middleware/adminAuth.go
+package middleware
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// AdminTokenMiddleware - autentikasi endpoint admin dengan bearer token statis dari ADMIN_TOKEN.
+// Jika ADMIN_TOKEN tidak diset, semua endpoint admin ditolak.
+func AdminTokenMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adminToken := os.Getenv("ADMIN_TOKEN")
+		if adminToken == "" {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": "Admin API is disabled",
+			})
+			c.Abort()
+			return
+		}
+
+		token, found := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid admin token",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_type", "admin")
+		c.Next()
+	}
+}
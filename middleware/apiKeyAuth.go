@@ -0,0 +1,109 @@
+// middleware/apiKeyAuth.go
+package middleware
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/utils"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"time"
+)
+
+// Interval minimal antar update last_used_at supaya tidak menulis ke DB di setiap request
+const apiKeyLastUsedInterval = time.Minute
+
+// APIKeyAuthMiddleware - autentikasi seller lewat header X-API-Key.
+// Jika valid, context diisi seperti AuthMiddleware("seller") sehingga controller seller bisa dipakai ulang.
+func APIKeyAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rawKey := c.GetHeader("X-API-Key")
+		if rawKey == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "X-API-Key header required",
+			})
+			c.Abort()
+			return
+		}
+
+		db := c.MustGet("db").(*gorm.DB)
+		now := time.Now()
+
+		// Key yang sudah diverifikasi requestPrincipal (policy rate limit) tidak dicari ulang
+		var apiKey models.SellerAPIKey
+		var err error
+		if verified, exists := c.Get(verifiedAPIKeyContextKey); exists {
+			apiKey = verified.(models.SellerAPIKey)
+		} else {
+			apiKey, err = verifyAPIKey(db, rawKey, now)
+		}
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": err.Error(),
+			})
+			c.Abort()
+			return
+		}
+
+		if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > apiKeyLastUsedInterval {
+			db.Model(&apiKey).UpdateColumn("last_used_at", now)
+		}
+
+		// Set principal seller ke context
+		c.Set("id", apiKey.SellerID)
+		c.Set("user_type", "seller")
+		c.Set("role", "seller")
+		c.Set("api_key_id", apiKey.ID)
+		c.Set("api_key_scopes", apiKey.Scopes)
+		c.Next()
+	}
+}
+
+// verifiedAPIKeyContextKey - key gin context untuk SellerAPIKey yang sudah lolos verifyAPIKey di request ini
+const verifiedAPIKeyContextKey = "verified_api_key"
+
+var (
+	errInvalidAPIKey  = errors.New("Invalid API key")
+	errAPIKeyUnusable = errors.New("API key revoked or expired")
+)
+
+// verifyAPIKey - mencari key berdasarkan prefix lalu memverifikasi secret dan masa berlakunya
+func verifyAPIKey(db *gorm.DB, rawKey string, now time.Time) (models.SellerAPIKey, error) {
+	var apiKey models.SellerAPIKey
+
+	prefix, secret, err := utils.ParseAPIKey(rawKey)
+	if err != nil {
+		return apiKey, errInvalidAPIKey
+	}
+	if err := db.Where("prefix = ?", prefix).First(&apiKey).Error; err != nil {
+		return apiKey, errInvalidAPIKey
+	}
+	if !utils.VerifyAPIKeySecret(secret, apiKey.SecretHash) {
+		return apiKey, errInvalidAPIKey
+	}
+	if !apiKey.IsUsable(now) {
+		return apiKey, errAPIKeyUnusable
+	}
+	return apiKey, nil
+}
+
+// RequireAPIKeyScope - memastikan API key yang dipakai punya scope yang dibutuhkan
+func RequireAPIKeyScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scopes, _ := c.Get("api_key_scopes")
+		scopeList, _ := scopes.([]string)
+
+		for _, s := range scopeList {
+			if s == scope {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "API key missing required scope: " + scope,
+		})
+		c.Abort()
+	}
+}
@@ -0,0 +1,194 @@
+// middleware/currency.go
+package middleware
+
+import (
+	"bytes"
+	"ecommerce-golang/money"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// DisplayCurrencyMiddleware - menampilkan harga dalam mata uang pilihan user.
+// Mata uang dipilih lewat query ?currency=USD atau header Accept-Currency.
+// Transaksi tetap dalam IDR: setiap nilai uang {"amount", "currency": "IDR"} di response JSON
+// mendapat field "display" hasil konversi memakai tabel kurs money.DefaultRates().
+func DisplayCurrencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Vary", "Accept-Currency")
+
+		code := money.Settlement
+		if query := strings.TrimSpace(c.Query("currency")); query != "" {
+			// Pilihan eksplisit di query harus valid
+			if _, ok := money.DefaultRates().Rate(query); !ok {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error":      "Unsupported currency",
+					"currencies": money.DefaultRates().Currencies(),
+				})
+				c.Abort()
+				return
+			}
+			code = strings.ToUpper(query)
+		} else if header := strings.TrimSpace(c.GetHeader("Accept-Currency")); header != "" {
+			// Header dari client diabaikan jika tidak didukung
+			if _, ok := money.DefaultRates().Rate(header); ok {
+				code = strings.ToUpper(header)
+			}
+		}
+
+		c.Set("display_currency", code)
+		c.Header("X-Display-Currency", code)
+		if code == money.Settlement {
+			c.Next()
+			return
+		}
+
+		writer := &currencyWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.passthrough {
+			return
+		}
+		body := writer.body.Bytes()
+		if converted, err := convertMoneyJSON(body, code); err == nil {
+			body = converted
+		}
+		c.Writer.Header().Del("Content-Length")
+		c.Writer.WriteHeader(writer.status)
+		_, _ = c.Writer.Write(body)
+	}
+}
+
+// currencyWriter - menahan body JSON sampai handler selesai supaya bisa ditambah harga tampilan.
+// Response selain JSON (file, redirect, stream) langsung diteruskan.
+type currencyWriter struct {
+	gin.ResponseWriter
+	body        bytes.Buffer
+	status      int
+	passthrough bool
+	decided     bool
+}
+
+func (w *currencyWriter) WriteHeader(code int) {
+	w.status = code
+	if w.passthrough {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *currencyWriter) WriteHeaderNow() {
+	w.decide()
+	if w.passthrough {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *currencyWriter) Write(data []byte) (int, error) {
+	w.decide()
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *currencyWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *currencyWriter) Status() int {
+	if w.passthrough {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+func (w *currencyWriter) Size() int {
+	if w.passthrough {
+		return w.ResponseWriter.Size()
+	}
+	return w.body.Len()
+}
+
+func (w *currencyWriter) Written() bool {
+	if w.passthrough {
+		return w.ResponseWriter.Written()
+	}
+	return w.body.Len() > 0
+}
+
+func (w *currencyWriter) Flush() {
+	w.decide()
+	if w.passthrough {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// decide - dipanggil saat byte pertama ditulis, Content-Type sudah diset oleh handler
+func (w *currencyWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+// convertMoneyJSON - menambahkan "display" ke setiap object uang IDR di dalam body
+func convertMoneyJSON(body []byte, code string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	addDisplayAmounts(doc, code)
+	return json.Marshal(doc)
+}
+
+func addDisplayAmounts(node interface{}, code string) {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if m, ok := moneyObject(v); ok {
+			if display, err := money.DefaultRates().Convert(m, code); err == nil {
+				v["display"] = display
+			}
+			return
+		}
+		for _, child := range v {
+			addDisplayAmounts(child, code)
+		}
+	case []interface{}:
+		for _, child := range v {
+			addDisplayAmounts(child, code)
+		}
+	}
+}
+
+// moneyObject - object hasil money.Money.MarshalJSON dengan mata uang IDR
+func moneyObject(v map[string]interface{}) (money.Money, bool) {
+	if len(v) != 3 {
+		return money.Money{}, false
+	}
+	currency, ok := v["currency"].(string)
+	if !ok || currency != money.Settlement {
+		return money.Money{}, false
+	}
+	if _, ok := v["formatted"].(string); !ok {
+		return money.Money{}, false
+	}
+	number, ok := v["amount"].(json.Number)
+	if !ok {
+		return money.Money{}, false
+	}
+	amount, err := number.Int64()
+	if err != nil {
+		return money.Money{}, false
+	}
+	return money.IDR(amount), true
+}
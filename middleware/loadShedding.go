@@ -0,0 +1,56 @@
+// middleware/loadShedding.go
+package middleware
+
+import (
+	"ecommerce-golang/ratelimit"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// LoadSheddingMiddleware - membatasi jumlah request yang diproses bersamaan (ratelimit.ActiveConcurrencyLimiter)
+// supaya lonjakan trafik total tidak menumpuk di database. Kelas prioritas diambil dari bagian
+// priorities di file policy; saat penuh request menunggu sesuai kelasnya, dan yang tidak kebagian
+// slot ditolak 503 dengan Retry-After. Dipasang setelah PolicyRateLimitMiddleware supaya request yang
+// sudah kena rate limit tidak ikut memakai slot.
+func LoadSheddingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limiter := ratelimit.ActiveConcurrencyLimiter()
+		if limiter == nil {
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		priority := ratelimit.PriorityBrowse
+		if registry := ratelimit.ActivePolicies(); registry != nil {
+			priority = registry.Current().Priority(c.Request.Method, route)
+		}
+
+		ticket, err := limiter.Acquire(c.Request.Context(), priority)
+		if err != nil {
+			var shed *ratelimit.ShedError
+			if !errors.As(err, &shed) {
+				log.Printf("Load shedding error, request allowed: %v", err)
+				c.Next()
+				return
+			}
+			c.Header("Retry-After", strconv.Itoa(ratelimit.RetryAfterSeconds(shed.RetryAfter)))
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":       "Service overloaded",
+				"message":     "Server is busy, please retry later",
+				"retry_after": ratelimit.RetryAfterSeconds(shed.RetryAfter),
+			})
+			c.Abort()
+			return
+		}
+		defer ticket.Release()
+
+		c.Next()
+	}
+}
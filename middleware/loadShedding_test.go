@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"ecommerce-golang/ratelimit"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Request yang tidak dapat slot sampai QueueTimeout dijawab 503 dengan Retry-After,
+// setelah slot dilepas request berikutnya diproses lagi
+func TestLoadSheddingQueueTimeout(t *testing.T) {
+	clock := ratelimit.NewFakeClock(time.Unix(1_700_000_000, 0))
+	config := ratelimit.DefaultConcurrencyConfig()
+	config.InitialLimit, config.MinLimit, config.MaxLimit = 1, 1, 1
+	config.TargetLatency = time.Minute
+	config.QueueTimeout[ratelimit.PriorityBrowse] = 20 * time.Millisecond
+	limiter := ratelimit.NewConcurrencyLimiter(config, clock)
+	ratelimit.SetActiveConcurrencyLimiter(limiter)
+	t.Cleanup(func() { ratelimit.SetActiveConcurrencyLimiter(nil) })
+
+	// Latency rata-rata 3 detik dengan limit 1: Retry-After 3
+	warmup, err := limiter.Acquire(context.Background(), ratelimit.PriorityBrowse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(3 * time.Second)
+	warmup.Release()
+
+	r := gin.New()
+	r.Use(LoadSheddingMiddleware())
+	r.GET("/shed-test", func(c *gin.Context) { c.Status(http.StatusOK) })
+	request := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/shed-test", nil))
+		return w
+	}
+
+	busy, err := limiter.Acquire(context.Background(), ratelimit.PriorityBrowse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := request()
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "3" {
+		t.Fatalf("busy = %d, Retry-After %q; want 503 and 3", w.Code, w.Header().Get("Retry-After"))
+	}
+	var body struct {
+		RetryAfter int `json:"retry_after"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.RetryAfter != 3 {
+		t.Errorf("body = %s, want retry_after 3", w.Body)
+	}
+
+	busy.Release()
+	if w := request(); w.Code != http.StatusOK {
+		t.Errorf("after release = %d, want 200", w.Code)
+	}
+}
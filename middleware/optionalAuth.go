@@ -36,6 +36,9 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 		if ok2 {
 			c.Set("role", role)
 		}
+		if userType, ok := claims["user_type"].(string); ok {
+			c.Set("user_type", userType)
+		}
 
 		c.Next()
 	}
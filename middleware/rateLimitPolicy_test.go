@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/ratelimit"
+	"ecommerce-golang/utils"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newAPIKeyDB - database SQLite in-memory berisi satu API key aktif dan satu yang sudah dicabut
+func newAPIKeyDB(t *testing.T) (db *gorm.DB, validKey, revokedKey string, validID uint) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&models.Seller{}, &models.SellerAPIKey{}); err != nil {
+		t.Fatal(err)
+	}
+
+	seller := models.Seller{Email: "seller@example.com", Password: "x"}
+	if err := db.Create(&seller).Error; err != nil {
+		t.Fatal(err)
+	}
+	create := func(revokedAt *time.Time) (string, uint) {
+		fullKey, prefix, secretHash, err := utils.GenerateAPIKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		apiKey := models.SellerAPIKey{SellerID: seller.ID, Prefix: prefix, SecretHash: secretHash, RevokedAt: revokedAt}
+		if err := db.Create(&apiKey).Error; err != nil {
+			t.Fatal(err)
+		}
+		return fullKey, apiKey.ID
+	}
+	revoked := time.Now().Add(-time.Hour)
+	validKey, validID = create(nil)
+	revokedKey, _ = create(&revoked)
+	return db, validKey, revokedKey, validID
+}
+
+// Tier api-key hanya untuk key yang lolos verifikasi, selain itu dihitung anonymous per IP
+func TestRequestPrincipalAPIKey(t *testing.T) {
+	db, validKey, revokedKey, validID := newAPIKeyDB(t)
+	prefix, _, _ := utils.ParseAPIKey(validKey)
+
+	tests := []struct {
+		name          string
+		key           string
+		wantTier      string
+		wantPrincipal string
+	}{
+		{"key valid", validKey, ratelimit.TierAPIKey, fmt.Sprintf("apikey:%d", validID)},
+		{"secret salah", "sk_" + prefix + "_forged", ratelimit.TierAnonymous, "ip:192.0.2.1"},
+		{"prefix tidak dikenal", "sk_0000000000000000_forged", ratelimit.TierAnonymous, "ip:192.0.2.1"},
+		{"format tidak valid", "forged", ratelimit.TierAnonymous, "ip:192.0.2.1"},
+		{"key dicabut", revokedKey, ratelimit.TierAnonymous, "ip:192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/integration/products", nil)
+			c.Request.RemoteAddr = "192.0.2.1:1234"
+			c.Request.Header.Set("X-API-Key", tt.key)
+			c.Set("db", db)
+
+			tier, principal := requestPrincipal(c)
+			if tier != tt.wantTier || principal != tt.wantPrincipal {
+				t.Errorf("requestPrincipal = (%s, %s), want (%s, %s)", tier, principal, tt.wantTier, tt.wantPrincipal)
+			}
+			// Hanya key yang lolos verifikasi disimpan untuk APIKeyAuthMiddleware
+			if _, stored := c.Get(verifiedAPIKeyContextKey); stored != (tt.wantTier == ratelimit.TierAPIKey) {
+				t.Errorf("verified key stored = %v, want %v", stored, tt.wantTier == ratelimit.TierAPIKey)
+			}
+		})
+	}
+}
+
+// Policy bawaan harus membatasi /integration/** untuk semua tier, termasuk key palsu (anonymous)
+func TestDefaultPoliciesLimitIntegrationForAllTiers(t *testing.T) {
+	config, err := ratelimit.DefaultPolicyConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	policies, err := ratelimit.NewPolicySet(config, ratelimit.NewMemoryStore(), ratelimit.SystemClock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tier := range []string{ratelimit.TierAnonymous, ratelimit.TierUser, ratelimit.TierSeller, ratelimit.TierAPIKey} {
+		limitedByIP := false
+		for _, match := range policies.Match(http.MethodGet, "/integration/products", tier) {
+			if match.Rule.Key == ratelimit.KeyByIP {
+				limitedByIP = true
+			}
+		}
+		if !limitedByIP {
+			t.Errorf("tier %s: no ip-keyed policy for /integration/**", tier)
+		}
+	}
+}
@@ -1,35 +1,60 @@
 package middleware
 
 import (
+	"context"
+	"ecommerce-golang/ratelimit"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"log"
 	"net/http"
-	"strconv"
 	"sync"
 	"time"
 )
 
 type RateLimiterConfig struct {
+	// Name - namespace key di store, limiter berbeda harus punya nama berbeda
+	// supaya tidak berbagi bucket untuk key (IP) yang sama
+	Name             string
 	RequestPerMinute int
 	BurstSize        int
 	WindowSize       time.Duration
-	KeyFunc          func(*gin.Context) string
+	// Algorithm - algoritma untuk Middleware(), default token bucket
+	Algorithm ratelimit.Algorithm
+	KeyFunc   func(*gin.Context) string
+	// Store - default store yang dipasang SetLimiterStore (memori jika belum diatur)
+	Store ratelimit.Store
+	// Clock - default jam sistem, diganti ratelimit.FakeClock untuk simulasi
+	Clock ratelimit.Clock
 }
 
-type Client struct {
-	Tokens     int
-	LastRefill time.Time
-	Request    []time.Time
-	mu         sync.Mutex
+type RateLimiter struct {
+	config   RateLimiterConfig
+	store    ratelimit.Store
+	limiters map[ratelimit.Algorithm]*ratelimit.Limiter
 }
 
-type RateLimiter struct {
-	clients map[string]*Client
-	config  RateLimiterConfig
-	mu      sync.RWMutex
+var (
+	limiterStoreMu sync.RWMutex
+	limiterStore   ratelimit.Store = ratelimit.NewMemoryStore()
+)
+
+// SetLimiterStore - store bersama untuk semua limiter yang dibuat setelahnya (lihat config.ConnectLimiterStore)
+func SetLimiterStore(store ratelimit.Store) {
+	limiterStoreMu.Lock()
+	defer limiterStoreMu.Unlock()
+	limiterStore = store
+}
+
+func defaultLimiterStore() ratelimit.Store {
+	limiterStoreMu.RLock()
+	defer limiterStoreMu.RUnlock()
+	return limiterStore
 }
 
 func NewRateLimiter(config RateLimiterConfig) *RateLimiter {
+	if config.Name == "" {
+		config.Name = "default"
+	}
 	if config.RequestPerMinute == 0 {
 		config.RequestPerMinute = 60
 	}
@@ -44,139 +69,116 @@ func NewRateLimiter(config RateLimiterConfig) *RateLimiter {
 			return c.ClientIP()
 		}
 	}
-	return &RateLimiter{
-		clients: make(map[string]*Client),
-		config:  config,
+	algorithm, err := ratelimit.ParseAlgorithm(string(config.Algorithm))
+	if err != nil {
+		log.Fatalf("Rate limiter %s: %v", config.Name, err)
+	}
+	config.Algorithm = algorithm
+	if config.Clock == nil {
+		config.Clock = ratelimit.SystemClock{}
+	}
+	store := config.Store
+	if store == nil {
+		store = defaultLimiterStore()
 	}
+
+	rl := &RateLimiter{
+		config:   config,
+		store:    store,
+		limiters: make(map[ratelimit.Algorithm]*ratelimit.Limiter),
+	}
+	// Token bucket dan GCRA: RequestPerMinute dengan burst BurstSize.
+	// Sliding window: RequestPerMinute per WindowSize.
+	bucketRate := ratelimit.Rate{Limit: config.RequestPerMinute, Period: time.Minute, Burst: config.BurstSize}
+	windowRate := ratelimit.Rate{Limit: config.RequestPerMinute, Period: config.WindowSize}
+	for algorithm, rate := range map[ratelimit.Algorithm]ratelimit.Rate{
+		ratelimit.AlgorithmTokenBucket:   bucketRate,
+		ratelimit.AlgorithmSlidingWindow: windowRate,
+		ratelimit.AlgorithmGCRA:          bucketRate,
+	} {
+		limiter, err := ratelimit.NewLimiter(ratelimit.Policy{Name: config.Name, Algorithm: algorithm, Rate: rate}, store, config.Clock)
+		if err != nil {
+			log.Fatalf("Rate limiter %s: %v", config.Name, err)
+		}
+		rl.limiters[algorithm] = limiter
+	}
+	return rl
 }
 
+// TokenBucketMiddleware - burst BurstSize lalu diisi ulang RequestPerMinute per menit
 func (rl *RateLimiter) TokenBucketMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		key := rl.config.KeyFunc(c)
-
-		rl.mu.Lock()
-		client, exists := rl.clients[key]
-		if !exists {
-			client = &Client{
-				Tokens:     rl.config.BurstSize,
-				LastRefill: time.Now(),
-			}
-			rl.clients[key] = client
-		}
-		rl.mu.Unlock()
-		client.mu.Lock()
-		defer client.mu.Unlock()
-
-		now := time.Now()
-		timePassed := now.Sub(client.LastRefill)
-		tokensToAdd := int(timePassed.Minutes() * float64(rl.config.RequestPerMinute))
-
-		if tokensToAdd > 0 {
-			client.Tokens += tokensToAdd
-			if client.Tokens > rl.config.BurstSize {
-				client.Tokens = rl.config.BurstSize
-			}
-			client.LastRefill = now
-		}
+	return rl.handler(ratelimit.AlgorithmTokenBucket)
+}
 
-		if client.Tokens <= 0 {
-			retryAfter := time.Minute / time.Duration(rl.config.RequestPerMinute)
+// SlidingWindowMiddleware - maksimal RequestPerMinute request per WindowSize terakhir
+func (rl *RateLimiter) SlidingWindowMiddleware() gin.HandlerFunc {
+	return rl.handler(ratelimit.AlgorithmSlidingWindow)
+}
 
-			c.Header("X-RateLimit-Limit", strconv.Itoa(rl.config.RequestPerMinute))
-			c.Header("X-RateLimit-Remaining", "0")
-			c.Header("X-RateLimit-Reset", strconv.FormatInt(now.Add(retryAfter).Unix(), 10))
-			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+// GCRAMiddleware - laju sama dengan token bucket tetapi request tersebar rata
+// (satu request per 60s/RequestPerMinute setelah burst habis)
+func (rl *RateLimiter) GCRAMiddleware() gin.HandlerFunc {
+	return rl.handler(ratelimit.AlgorithmGCRA)
+}
 
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":       "Rate limit exceeded",
-				"message":     fmt.Sprintf("Too many requests. Limit: %d requests per minute", rl.config.RequestPerMinute),
-				"retry_after": int(retryAfter.Seconds()),
-			})
-			c.Abort()
-			return
-		}
+// Middleware - memakai algoritma dari config.Algorithm
+func (rl *RateLimiter) Middleware() gin.HandlerFunc {
+	return rl.handler(rl.config.Algorithm)
+}
 
-		client.Tokens--
-		c.Header("X-RateLimit-Limit", strconv.Itoa(rl.config.RequestPerMinute))
-		c.Header("X-RateLimit-Remaining", strconv.Itoa(client.Tokens))
-		c.Header("X-RateLimit-Reset", strconv.FormatInt(now.Add(time.Minute).Unix(), 10))
+// Inspect - sisa kuota key (hasil KeyFunc) tanpa memakai kuota
+func (rl *RateLimiter) Inspect(ctx context.Context, key string) (ratelimit.Result, error) {
+	return rl.limiters[rl.config.Algorithm].Peek(ctx, key)
+}
 
-		c.Next()
+// Reset - mengembalikan kuota key menjadi penuh di semua algoritma
+func (rl *RateLimiter) Reset(ctx context.Context, key string) error {
+	for _, limiter := range rl.limiters {
+		if err := limiter.Reset(ctx, key); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-func (rl *RateLimiter) SlidingWindowMiddleware() gin.HandlerFunc {
+func (rl *RateLimiter) handler(algorithm ratelimit.Algorithm) gin.HandlerFunc {
+	limiter := rl.limiters[algorithm]
 	return func(c *gin.Context) {
-		key := rl.config.KeyFunc(c)
-		now := time.Now()
-
-		rl.mu.Lock()
-		client, exists := rl.clients[key]
-		if !exists {
-			client = &Client{
-				Request: make([]time.Time, 0),
-			}
-			rl.clients[key] = client
-		}
-		rl.mu.Unlock()
-		client.mu.Lock()
-		defer client.mu.Unlock()
-
-		windoStart := now.Add(-rl.config.WindowSize)
-		var validRequests []time.Time
-		for _, reqTime := range client.Request {
-			if reqTime.After(windoStart) {
-				validRequests = append(validRequests, reqTime)
-			}
-		}
-		client.Request = validRequests
-
-		if len(client.Request) >= rl.config.RequestPerMinute {
-			retryAfter := client.Request[0].Add(rl.config.WindowSize).Sub(now)
-			c.Header("X-RateLimit-Limit", strconv.Itoa(rl.config.RequestPerMinute))
-			c.Header("X-RateLimit-Remaining", "0")
-			c.Header("X-RateLimit-Reset", strconv.FormatInt(now.Add(retryAfter).Unix(), 10))
-			c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
-
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":       "Rate limit exceeded",
-				"message":     fmt.Sprintf("Too many requests. Limit: %d requests per %v", rl.config.RequestPerMinute, rl.config.WindowSize),
-				"retry_after": int(retryAfter.Seconds()),
-			})
-			c.Abort()
+		result, err := limiter.Allow(c.Request.Context(), rl.config.KeyFunc(c))
+		if err != nil {
+			// Error store (misalnya Redis mati) tidak boleh menjatuhkan API, request diloloskan
+			log.Printf("Rate limiter %s store error, request allowed: %v", rl.config.Name, err)
+			c.Next()
 			return
 		}
-		client.Request = append(client.Request, now)
 
-		remaining := rl.config.RequestPerMinute - len(client.Request)
-		c.Header("X-RateLimit-Limit", strconv.Itoa(rl.config.RequestPerMinute))
-		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
-		c.Header("X-RateLimit-Reset", strconv.FormatInt(now.Add(rl.config.WindowSize).Unix(), 10))
+		writeRateLimitHeaders(c, limiter, result)
+		if !result.Allowed {
+			rejectRateLimited(c, limiter, result)
+			return
+		}
 
 		c.Next()
 	}
 }
-func (rl *RateLimiter) Cleanup() {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-
-	now := time.Now()
-	for key, client := range rl.clients {
-		client.mu.Lock()
-		// Hapus client yang tidak aktif lebih dari 1 jam
-		if now.Sub(client.LastRefill) > time.Hour {
-			delete(rl.clients, key)
-		}
-		client.mu.Unlock()
+
+func writeRateLimitHeaders(c *gin.Context, limiter *ratelimit.Limiter, result ratelimit.Result) {
+	for name, value := range limiter.Headers(result) {
+		c.Header(name, value)
 	}
 }
 
-// StartCleanupRoutine - memulai routine untuk cleanup otomatis
-func (rl *RateLimiter) StartCleanupRoutine() {
-	ticker := time.NewTicker(30 * time.Minute)
-	go func() {
-		for range ticker.C {
-			rl.Cleanup()
-		}
-	}()
+// rejectRateLimited - respons 429 (header sudah ditulis writeRateLimitHeaders)
+func rejectRateLimited(c *gin.Context, limiter *ratelimit.Limiter, result ratelimit.Result) {
+	rate := limiter.Policy().Rate
+	period := rate.Period.String()
+	if rate.Period == time.Minute {
+		period = "minute"
+	}
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Rate limit exceeded",
+		"message":     fmt.Sprintf("Too many requests. Limit: %d requests per %s", rate.Limit, period),
+		"retry_after": ratelimit.RetryAfterSeconds(result.RetryAfter),
+	})
+	c.Abort()
 }
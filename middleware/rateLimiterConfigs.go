@@ -12,6 +12,7 @@ import (
 // IPBasedRateLimiter - rate limiter berdasarkan IP
 func IPBasedRateLimiter(requestsPerMinute, burstSize int) *RateLimiter {
 	return NewRateLimiter(RateLimiterConfig{
+		Name:             fmt.Sprintf("ip-%d-%d", requestsPerMinute, burstSize),
 		RequestPerMinute: requestsPerMinute,
 		BurstSize:        burstSize,
 		WindowSize:       time.Minute,
@@ -24,6 +25,7 @@ func IPBasedRateLimiter(requestsPerMinute, burstSize int) *RateLimiter {
 // UserBasedRateLimiter - rate limiter berdasarkan user ID (untuk authenticated users)
 func UserBasedRateLimiter(requestsPerMinute, burstSize int) *RateLimiter {
 	return NewRateLimiter(RateLimiterConfig{
+		Name:             fmt.Sprintf("user-%d-%d", requestsPerMinute, burstSize),
 		RequestPerMinute: requestsPerMinute,
 		BurstSize:        burstSize,
 		WindowSize:       time.Minute,
@@ -49,6 +51,7 @@ func UserBasedRateLimiter(requestsPerMinute, burstSize int) *RateLimiter {
 // EndpointBasedRateLimiter - rate limiter berdasarkan endpoint + IP
 func EndpointBasedRateLimiter(requestsPerMinute, burstSize int) *RateLimiter {
 	return NewRateLimiter(RateLimiterConfig{
+		Name:             fmt.Sprintf("endpoint-%d-%d", requestsPerMinute, burstSize),
 		RequestPerMinute: requestsPerMinute,
 		BurstSize:        burstSize,
 		WindowSize:       time.Minute,
@@ -61,6 +64,7 @@ func EndpointBasedRateLimiter(requestsPerMinute, burstSize int) *RateLimiter {
 // StrictRateLimiter - rate limiter ketat untuk endpoint sensitif
 func StrictRateLimiter() *RateLimiter {
 	return NewRateLimiter(RateLimiterConfig{
+		Name:             "strict",
 		RequestPerMinute: 10, // Hanya 10 requests per menit
 		BurstSize:        2,  // Burst kecil
 		WindowSize:       time.Minute,
@@ -73,6 +77,7 @@ func StrictRateLimiter() *RateLimiter {
 // ModerateRateLimiter - rate limiter sedang untuk API umum
 func ModerateRateLimiter() *RateLimiter {
 	return NewRateLimiter(RateLimiterConfig{
+		Name:             "moderate",
 		RequestPerMinute: 60, // 60 requests per menit
 		BurstSize:        10, // Burst sedang
 		WindowSize:       time.Minute,
@@ -85,6 +90,7 @@ func ModerateRateLimiter() *RateLimiter {
 // RelaxedRateLimiter - rate limiter longgar untuk read operations
 func RelaxedRateLimiter() *RateLimiter {
 	return NewRateLimiter(RateLimiterConfig{
+		Name:             "relaxed",
 		RequestPerMinute: 120, // 120 requests per menit
 		BurstSize:        20,  // Burst besar
 		WindowSize:       time.Minute,
@@ -97,6 +103,7 @@ func RelaxedRateLimiter() *RateLimiter {
 // AuthRateLimiter - rate limiter khusus untuk authentication endpoints
 func AuthRateLimiter() *RateLimiter {
 	return NewRateLimiter(RateLimiterConfig{
+		Name:             "auth",
 		RequestPerMinute: 5, // Hanya 5 attempts per menit
 		BurstSize:        3, // Burst sangat kecil
 		WindowSize:       time.Minute,
@@ -110,6 +117,7 @@ func AuthRateLimiter() *RateLimiter {
 // SearchRateLimiter - rate limiter untuk search endpoints
 func SearchRateLimiter() *RateLimiter {
 	return NewRateLimiter(RateLimiterConfig{
+		Name:             "search",
 		RequestPerMinute: 30,
 		BurstSize:        5,
 		WindowSize:       time.Minute,
@@ -134,12 +142,14 @@ func SearchRateLimiter() *RateLimiter {
 // UploadRateLimiter - rate limiter untuk file uploads
 func UploadRateLimiter() *RateLimiter {
 	return NewRateLimiter(RateLimiterConfig{
+		Name:             "upload",
 		RequestPerMinute: 10, // 10 uploads per menit
 		BurstSize:        2,  // Burst sangat kecil
 		WindowSize:       time.Minute,
 		KeyFunc: func(c *gin.Context) string {
 			if userID, exists := c.Get("id"); exists {
-				return "upload:user:" + string(rune(userID.(uint)))
+				userType, _ := c.Get("user_type")
+				return fmt.Sprintf("upload:%v:%v", userType, userID)
 			}
 			return "upload:ip:" + c.ClientIP()
 		},
@@ -149,6 +159,7 @@ func UploadRateLimiter() *RateLimiter {
 // Tiered Rate Limiting berdasarkan role
 func TieredRateLimiter() *RateLimiter {
 	return NewRateLimiter(RateLimiterConfig{
+		Name:             "tiered",
 		RequestPerMinute: 60, // Default
 		BurstSize:        10,
 		WindowSize:       time.Minute,
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"ecommerce-golang/ratelimit"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// Reset harus mengembalikan kuota di semua middleware RateLimiter, bukan hanya config.Algorithm
+func TestRateLimiterResetAllAlgorithms(t *testing.T) {
+	rl := NewRateLimiter(RateLimiterConfig{
+		Name:             "test",
+		RequestPerMinute: 1,
+		BurstSize:        1,
+		Store:            ratelimit.NewMemoryStore(),
+		Clock:            ratelimit.NewFakeClock(time.Unix(1_700_000_000, 0)),
+		KeyFunc:          func(c *gin.Context) string { return "client" },
+	})
+
+	r := gin.New()
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	r.GET("/token-bucket", rl.TokenBucketMiddleware(), ok)
+	r.GET("/sliding-window", rl.SlidingWindowMiddleware(), ok)
+	r.GET("/gcra", rl.GCRAMiddleware(), ok)
+	paths := []string{"/token-bucket", "/sliding-window", "/gcra"}
+
+	request := func(path string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+	for _, path := range paths {
+		if code := request(path); code != http.StatusOK {
+			t.Fatalf("%s first request = %d", path, code)
+		}
+		if code := request(path); code != http.StatusTooManyRequests {
+			t.Fatalf("%s second request = %d, want 429", path, code)
+		}
+	}
+
+	if err := rl.Reset(context.Background(), "client"); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range paths {
+		if code := request(path); code != http.StatusOK {
+			t.Errorf("%s after reset = %d, want 200", path, code)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package middleware
+
+import (
+	"ecommerce-golang/storage"
+	"github.com/gin-gonic/gin"
+)
+
+func InjectStorage(store storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("storage", store)
+		c.Next()
+	}
+}
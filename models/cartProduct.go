@@ -1,16 +1,19 @@
 package models
 
-import "time"
+import (
+	"ecommerce-golang/money"
+	"time"
+)
 
 type CartProduct struct {
-	ID        uint      `json:"id" gorm:"primary_key"`
-	UserID    uint      `json:"user_id" gorm:"not null;index"`
-	ProductID uint      `json:"product_id" gorm:"not null;index"`
-	Category  string    `json:"category" gorm:"size:255"` // Copy kategori saat pembelian
-	Quantity  uint      `json:"quantity" gorm:"not null"`
-	Price     float64   `json:"price" gorm:"not null"` // Harga saat beli
-	Photo     string    `json:"photo" gorm:"size:255"`
-	AddedAt   time.Time `json:"created_at"`
+	ID        uint        `json:"id" gorm:"primary_key"`
+	UserID    uint        `json:"user_id" gorm:"not null;index"`
+	ProductID uint        `json:"product_id" gorm:"not null;index"`
+	Category  string      `json:"category" gorm:"size:255"` // Copy kategori saat pembelian
+	Quantity  uint        `json:"quantity" gorm:"not null"`
+	Price     money.Money `json:"price" gorm:"not null"` // Harga saat beli
+	Photo     string      `json:"photo" gorm:"size:255"`
+	AddedAt   time.Time   `json:"created_at"`
 
 	Product Product `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"product,omitempty"`
 	User    User    `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user,omitempty"`
@@ -0,0 +1,73 @@
+// models/flashSale.go
+package models
+
+import (
+	"ecommerce-golang/money"
+	"time"
+)
+
+// Status reservasi kuota flash sale
+const (
+	FlashSaleReserved  = "reserved"  // kuota dipegang user sampai ExpiresAt
+	FlashSalePurchased = "purchased" // sudah dibayar, kuota terpakai permanen
+	FlashSaleReleased  = "released"  // kedaluwarsa/dibatalkan, kuota dikembalikan
+)
+
+// FlashSale - kampanye harga khusus untuk satu produk dalam jangka waktu terbatas
+type FlashSale struct {
+	ID           uint        `gorm:"primaryKey" json:"id"`
+	ProductID    uint        `gorm:"not null;index" json:"product_id"`
+	SellerID     uint        `gorm:"not null;index" json:"seller_id"`
+	SalePrice    money.Money `gorm:"not null" json:"sale_price"`
+	Quota        uint        `gorm:"not null" json:"quota"`
+	Sold         uint        `gorm:"not null;default:0" json:"sold"` // termasuk kuota yang sedang direservasi
+	PerUserLimit uint        `gorm:"not null" json:"per_user_limit"` // 0 = tanpa batas
+	StartsAt     time.Time   `gorm:"not null;index" json:"starts_at"`
+	EndsAt       time.Time   `gorm:"not null;index" json:"ends_at"`
+	IsActive     bool        `gorm:"not null" json:"is_active"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+
+	Product Product `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"product,omitempty"`
+}
+
+// Remaining - sisa kuota
+func (f FlashSale) Remaining() uint {
+	if f.Sold >= f.Quota {
+		return 0
+	}
+	return f.Quota - f.Sold
+}
+
+// IsLive - kampanye aktif dan sedang berjalan
+func (f FlashSale) IsLive(now time.Time) bool {
+	return f.IsActive && !now.Before(f.StartsAt) && now.Before(f.EndsAt)
+}
+
+// FlashSaleReservation - kuota flash sale yang diklaim user
+type FlashSaleReservation struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	FlashSaleID uint      `gorm:"not null;index" json:"flash_sale_id"`
+	UserID      uint      `gorm:"not null;index" json:"user_id"`
+	ProductID   uint      `gorm:"not null" json:"product_id"`
+	Quantity    uint      `gorm:"not null" json:"quantity"`
+	Status      string    `gorm:"size:20;not null;index" json:"status"`
+	ExpiresAt   time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	FlashSale FlashSale `gorm:"foreignKey:FlashSaleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+// FlashSaleInfo - info flash sale yang ditampilkan di listing, detail produk dan keranjang
+type FlashSaleInfo struct {
+	ID            uint        `json:"id"`
+	SalePrice     money.Money `json:"sale_price"`
+	OriginalPrice money.Money `json:"original_price"`
+	Quota         uint        `json:"quota"`
+	Remaining     uint        `json:"remaining"`
+	PerUserLimit  uint        `json:"per_user_limit"`
+	StartsAt      time.Time   `json:"starts_at"`
+	EndsAt        time.Time   `json:"ends_at"`
+}
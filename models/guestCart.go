@@ -0,0 +1,28 @@
+// models/guestCart.go
+package models
+
+import "time"
+
+// GuestCart - keranjang pengunjung yang belum login, dikenali dari token bertanda tangan
+// (cookie guest_cart atau header X-Guest-Cart). Yang disimpan hanya key, signature dicek di utils.
+type GuestCart struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CartKey   string    `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Items []GuestCartItem `gorm:"foreignKey:GuestCartID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"items,omitempty"`
+}
+
+// GuestCartItem - satu produk per guest cart, sama seperti ProductUserCart
+type GuestCartItem struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	GuestCartID uint      `gorm:"not null;uniqueIndex:idx_guest_cart_product" json:"guest_cart_id"`
+	ProductID   uint      `gorm:"not null;uniqueIndex:idx_guest_cart_product;index" json:"product_id"`
+	Quantity    uint      `gorm:"not null" json:"quantity"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Product *Product `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"product,omitempty"`
+}
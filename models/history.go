@@ -1,27 +1,51 @@
 package models
 
-import "time"
+import (
+	"ecommerce-golang/money"
+	"time"
+)
 
 type ProductUserHistory struct {
-	ID        uint      `json:"id" gorm:"primary_key"`
-	UserID    uint      `json:"user_id" gorm:"not null;index"`
-	ProductID uint      `json:"product_id" gorm:"not null;index"`
-	Category  string    `json:"category" gorm:"size:255"` // Copy kategori saat pembelian
-	Quantity  uint      `json:"quantity" gorm:"not null"`
-	Price     float64   `json:"price" gorm:"not null"` // Harga saat beli
-	CreatedAt time.Time `json:"created_at"`
+	ID         uint        `json:"id" gorm:"primary_key"`
+	UserID     uint        `json:"user_id" gorm:"not null;index"`
+	ProductID  uint        `json:"product_id" gorm:"not null;index"`
+	Category   string      `json:"category" gorm:"size:255"` // Copy kategori saat pembelian
+	Quantity   uint        `json:"quantity" gorm:"not null"`
+	Price      money.Money `json:"price" gorm:"not null"` // Harga saat beli
+	ShipmentID *uint       `json:"shipment_id" gorm:"index"`
+	Note       string      `json:"note" gorm:"size:500"` // Catatan pembeli untuk seller, disalin dari keranjang
+	CreatedAt  time.Time   `json:"created_at"`
+
+	// Rincian harga saat checkout (pricing engine), dipakai invoice. Potongan voucher, ongkir dan
+	// subsidi ongkir paket seller dibagi ke item. Subtotal 0 untuk data sebelum rincian ini dicatat.
+	Subtotal         money.Money `json:"subtotal" gorm:"not null;default:0"`
+	Discount         money.Money `json:"discount" gorm:"not null;default:0"`
+	Shipping         money.Money `json:"shipping" gorm:"not null;default:0"`
+	ShippingDiscount money.Money `json:"shipping_discount" gorm:"not null;default:0"`
+	TaxBasisPoints   int64       `json:"tax_basis_points" gorm:"not null;default:0"`
+	TaxInclusive     bool        `json:"tax_inclusive" gorm:"not null;default:false"`
+	TaxBase          money.Money `json:"tax_base" gorm:"not null;default:0"` // DPP
+	Tax              money.Money `json:"tax" gorm:"not null;default:0"`
+	Total            money.Money `json:"total" gorm:"not null;default:0"` // subtotal - diskon + ongkir - subsidi ongkir + PPN di luar harga
+	VoucherCode      string      `json:"voucher_code,omitempty" gorm:"size:50"`
 
 	Product Product `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"product,omitempty"`
 	User    User    `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user,omitempty"`
 }
 
 type ProductUserCart struct {
-	ID        uint      `json:"id" gorm:"primary_key"`
-	UserID    uint      `json:"user_id" gorm:"not null;index"`
-	ProductID uint      `json:"product_id" gorm:"not null;index"`
-	Quantity  uint      `json:"quantity" gorm:"not null;default:1"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         uint        `json:"id" gorm:"primary_key"`
+	UserID     uint        `json:"user_id" gorm:"not null;index"`
+	ProductID  uint        `json:"product_id" gorm:"not null;index"`
+	Quantity   uint        `json:"quantity" gorm:"not null;default:1"`
+	PriceAtAdd money.Money `json:"price_at_add" gorm:"not null"` // Harga normal saat ditambahkan / terakhir di-refresh, 0 untuk data lama
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+
+	// Item yang disimpan untuk nanti dan item yang tidak dicentang tidak ikut dihitung di total
+	SavedForLater bool   `json:"saved_for_later" gorm:"not null;default:false;index"`
+	Selected      bool   `json:"selected" gorm:"not null;default:true"`
+	Note          string `json:"note" gorm:"size:500"` // Catatan untuk seller, contoh "warna merah ya"
 
 	Product *Product `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"product,omitempty"`
 	User    *User    `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user,omitempty"`
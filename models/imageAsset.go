@@ -0,0 +1,21 @@
+// models/imageAsset.go
+package models
+
+import "time"
+
+// ImageAsset - gambar yang sudah di-upload beserta variannya.
+// Hash adalah sha256 dari file asli, dipakai untuk deduplikasi upload yang sama.
+type ImageAsset struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Hash         string    `gorm:"size:64;uniqueIndex;not null" json:"hash"`
+	ContentType  string    `gorm:"size:50" json:"content_type"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	Size         int64     `json:"size"` // ukuran file asli dalam byte
+	OriginalURL  string    `gorm:"size:512" json:"original_url"`
+	MediumURL    string    `gorm:"size:512" json:"medium_url"`
+	ThumbnailURL string    `gorm:"size:512" json:"thumbnail_url"`
+	UploaderType string    `gorm:"size:20" json:"uploader_type"` // user / seller
+	UploaderID   uint      `gorm:"index" json:"uploader_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
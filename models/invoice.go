@@ -0,0 +1,71 @@
+// models/invoice.go
+package models
+
+import (
+	"ecommerce-golang/money"
+	"time"
+)
+
+// InvoiceSequence - counter nomor invoice per seller. Baris dikunci (SELECT ... FOR UPDATE)
+// di transaksi yang sama dengan pembuatan invoice, sehingga nomor berurutan tanpa celah:
+// jika pembuatan invoice gagal, kenaikan counter ikut di-rollback.
+type InvoiceSequence struct {
+	SellerID   uint      `gorm:"primaryKey;autoIncrement:false" json:"seller_id"`
+	LastNumber uint      `gorm:"not null" json:"last_number"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Invoice - faktur untuk satu shipment. Data seller, buyer, harga dan pajak disalin saat
+// invoice diterbitkan, sehingga invoice tidak berubah walaupun profil atau tarif pajak berubah.
+type Invoice struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Number     string    `gorm:"size:50;not null;uniqueIndex" json:"number"`
+	SellerID   uint      `gorm:"not null;uniqueIndex:idx_seller_sequence" json:"seller_id"`
+	Sequence   uint      `gorm:"not null;uniqueIndex:idx_seller_sequence" json:"sequence"`
+	UserID     uint      `gorm:"not null;index" json:"user_id"`
+	ShipmentID uint      `gorm:"not null;uniqueIndex" json:"shipment_id"`
+	IssuedAt   time.Time `gorm:"not null" json:"issued_at"`
+
+	SellerName    string `gorm:"size:255" json:"seller_name"`
+	SellerAddress string `gorm:"size:500" json:"seller_address"`
+	SellerNPWP    string `gorm:"size:30" json:"seller_npwp"`
+	SellerIsPKP   bool   `gorm:"not null" json:"seller_is_pkp"`
+	BuyerName     string `gorm:"size:255" json:"buyer_name"`
+	BuyerEmail    string `gorm:"size:255" json:"buyer_email"`
+	BuyerAddress  string `gorm:"size:500" json:"buyer_address"`
+
+	Subtotal         money.Money `gorm:"not null" json:"subtotal"`
+	Discount         money.Money `gorm:"not null;default:0" json:"discount"`
+	Shipping         money.Money `gorm:"not null;default:0" json:"shipping"`
+	ShippingDiscount money.Money `gorm:"not null;default:0" json:"shipping_discount"`
+	TaxBase          money.Money `gorm:"not null" json:"tax_base"` // DPP
+	Tax              money.Money `gorm:"not null" json:"tax"`
+	TaxIncluded      money.Money `gorm:"not null" json:"tax_included"`
+	Total            money.Money `gorm:"not null" json:"total"`
+	CreatedAt        time.Time   `json:"created_at"`
+
+	Items    []InvoiceItem `gorm:"foreignKey:InvoiceID" json:"items,omitempty"`
+	Shipment Shipment      `gorm:"foreignKey:ShipmentID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"-"`
+	Seller   Seller        `gorm:"foreignKey:SellerID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"-"`
+	User     User          `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"-"`
+}
+
+// InvoiceItem - satu baris invoice
+type InvoiceItem struct {
+	ID           uint        `gorm:"primaryKey" json:"id"`
+	InvoiceID    uint        `gorm:"not null;index" json:"invoice_id"`
+	HistoryID    uint        `gorm:"not null" json:"history_id"`
+	ProductID    uint        `gorm:"not null" json:"product_id"`
+	Name         string      `gorm:"size:255;not null" json:"name"`
+	Category     string      `gorm:"size:255" json:"category"`
+	Quantity     uint        `gorm:"not null" json:"quantity"`
+	UnitPrice    money.Money `gorm:"not null" json:"unit_price"`
+	Subtotal     money.Money `gorm:"not null" json:"subtotal"`
+	Discount     money.Money `gorm:"not null;default:0" json:"discount"`
+	TaxRate      float64     `gorm:"not null" json:"tax_rate"` // persen
+	TaxInclusive bool        `gorm:"not null" json:"tax_inclusive"`
+	TaxBase      money.Money `gorm:"not null" json:"tax_base"`
+	Tax          money.Money `gorm:"not null" json:"tax"`
+
+	Invoice *Invoice `gorm:"foreignKey:InvoiceID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
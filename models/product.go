@@ -1,39 +1,95 @@
 package models
 
-import "time"
+import (
+	"crypto/rand"
+	"ecommerce-golang/money"
+	"encoding/hex"
+	"fmt"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// AutoSKUPrefix - awalan SKU buatan sistem ("#P<id>") untuk produk yang dibuat tanpa SKU.
+// Seller tidak boleh memakai awalan ini, jadi SKU otomatis tidak bisa bentrok dengan SKU seller.
+const AutoSKUPrefix = "#"
+
+// SKU sementara selama insert, sebelum ID produk diketahui
+const pendingSKUPrefix = AutoSKUPrefix + "new-"
 
 type Product struct {
-	ID           uint      `json:"id" gorm:"primary_key"`
-	SellerID     uint      `json:"seller_id" gorm:"not null;index"`
-	Name         string    `json:"name" gorm:"not null;size:255"`
-	Description  string    `json:"description" gorm:"type:text"`
-	Price        float64   `json:"price" gorm:"not null"`
-	Stock        uint      `json:"stock" gorm:"default:0"`
-	Category     string    `json:"category" gorm:"size:255"`
-	Images       []string  `json:"images" gorm:"type:json;serializer:json"` //json array of image url
-	Weight       float64   `json:"weight" gorm:"not null"`                  //dalam gram
-	Dimensions   string    `json:"dimensions" gorm:"type:text"`             //panjang lebar tinggi
-	Brand        string    `json:"brand" gorm:"size:255"`
-	IsActive     bool      `json:"is_active" gorm:"default:false"`
-	Rating       float64   `json:"rating" gorm:"default:0"`
-	TotalReviews uint      `json:"total_reviews" gorm:"default:0"`
-	TotalSold    uint      `json:"total_sold" gorm:"default:0"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           uint        `json:"id" gorm:"primary_key"`
+	SellerID     uint        `json:"seller_id" gorm:"not null;index;uniqueIndex:idx_products_seller_sku,priority:1"`
+	SKU          string      `json:"sku" gorm:"size:100;uniqueIndex:idx_products_seller_sku,priority:2"` // SKU milik seller, unik per seller
+	Name         string      `json:"name" gorm:"not null;size:255"`
+	Description  string      `json:"description" gorm:"type:text"`
+	Price        money.Money `json:"price" gorm:"not null"`
+	Stock        uint        `json:"stock" gorm:"default:0"`
+	Category     string      `json:"category" gorm:"size:255"`
+	Images       []string    `json:"images" gorm:"type:json;serializer:json"` //json array of image url
+	Weight       float64     `json:"weight" gorm:"not null"`                  //dalam gram
+	Dimensions   string      `json:"dimensions" gorm:"type:text"`             //panjang lebar tinggi
+	Length       float64     `json:"length" gorm:"default:0"`                 //cm
+	Width        float64     `json:"width" gorm:"default:0"`                  //cm
+	Height       float64     `json:"height" gorm:"default:0"`                 //cm
+	Brand        string      `json:"brand" gorm:"size:255"`
+	IsActive     bool        `json:"is_active" gorm:"default:false"`
+	Rating       float64     `json:"rating" gorm:"default:0"`
+	TotalReviews uint        `json:"total_reviews" gorm:"default:0"`
+	TotalSold    uint        `json:"total_sold" gorm:"default:0"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
 
 	Seller Seller `gorm:"foreignKey:SellerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
 }
 
+// AutoSKU - SKU otomatis untuk produk tanpa SKU
+func AutoSKU(productID uint) string {
+	return fmt.Sprintf("%sP%d", AutoSKUPrefix, productID)
+}
+
+// IsAutoSKU - SKU memakai awalan yang dicadangkan untuk SKU otomatis
+func IsAutoSKU(sku string) bool {
+	return strings.HasPrefix(sku, AutoSKUPrefix)
+}
+
+// BeforeCreate - produk tanpa SKU diberi SKU sementara yang acak supaya tidak bentrok di
+// unique index (seller_id, sku); AfterCreate menggantinya dengan AutoSKU dalam transaksi yang sama
+func (p *Product) BeforeCreate(tx *gorm.DB) error {
+	if p.SKU != "" {
+		return nil
+	}
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	p.SKU = pendingSKUPrefix + hex.EncodeToString(suffix)
+	return nil
+}
+
+func (p *Product) AfterCreate(tx *gorm.DB) error {
+	if !strings.HasPrefix(p.SKU, pendingSKUPrefix) {
+		return nil
+	}
+	p.SKU = AutoSKU(p.ID)
+	return tx.Session(&gorm.Session{NewDB: true}).
+		Model(&Product{ID: p.ID}).
+		UpdateColumn("sku", p.SKU).Error
+}
+
 // ProductListView - For search/browse (lightweight)
 type ProductListView struct {
-	ID        uint    `json:"id"`
-	Name      string  `json:"name"`
-	Price     float64 `json:"price"`
-	Image     string  `json:"image"` // First image only
-	Rating    float64 `json:"rating"`
-	TotalSold uint    `json:"total_sold"`
-	ShopName  string  `json:"shop_name"`
-	City      string  `json:"city"`
+	ID        uint        `json:"id"`
+	Name      string      `json:"name"`
+	Price     money.Money `json:"price"`
+	Image     string      `json:"image"` // First image only
+	Rating    float64     `json:"rating"`
+	TotalSold uint        `json:"total_sold"`
+	ShopName  string      `json:"shop_name"`
+	City      string      `json:"city"`
+	Category  string      `json:"category,omitempty"` // hanya diisi query rekomendasi (untuk batas keragaman)
+
+	FlashSale *FlashSaleInfo `json:"flash_sale,omitempty" gorm:"-"` // diisi jika flash sale sedang berjalan, Price = harga flash sale
 }
 
 // ProductDetailView - For product detail page
@@ -43,4 +99,6 @@ type ProductDetailView struct {
 	ShopLogo   string  `json:"shop_logo"`
 	ShopCity   string  `json:"shop_city"`
 	ShopRating float64 `json:"shop_rating"`
+
+	FlashSale *FlashSaleInfo `json:"flash_sale,omitempty" gorm:"-"`
 }
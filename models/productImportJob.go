@@ -0,0 +1,39 @@
+// models/productImportJob.go
+package models
+
+import "time"
+
+// Status job import produk
+const (
+	ImportJobPending    = "pending"
+	ImportJobProcessing = "processing"
+	ImportJobCompleted  = "completed"
+	ImportJobFailed     = "failed"
+)
+
+// ImportRowError - error validasi per baris file import
+type ImportRowError struct {
+	Row    int      `json:"row"` // nomor baris di file (header = baris 1)
+	SKU    string   `json:"sku"`
+	Errors []string `json:"errors"`
+}
+
+// ProductImportJob - job import produk yang diproses secara asynchronous
+type ProductImportJob struct {
+	ID           uint             `gorm:"primaryKey" json:"id"`
+	SellerID     uint             `gorm:"not null;index" json:"seller_id"`
+	FileName     string           `gorm:"size:255" json:"file_name"`
+	Format       string           `gorm:"size:10" json:"format"`
+	Status       string           `gorm:"size:20;not null;default:pending" json:"status"`
+	TotalRows    int              `json:"total_rows"`
+	CreatedCount int              `json:"created_count"`
+	UpdatedCount int              `json:"updated_count"`
+	FailedCount  int              `json:"failed_count"`
+	RowErrors    []ImportRowError `gorm:"type:json;serializer:json" json:"row_errors"`
+	ErrorMessage string           `gorm:"type:text" json:"error_message,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+	FinishedAt   *time.Time       `json:"finished_at"`
+
+	Seller Seller `gorm:"foreignKey:SellerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,17 @@
+// models/productNeighbor.go
+package models
+
+import "time"
+
+// ProductNeighbor - produk yang sering dibeli / dimasukkan keranjang bersama ProductID
+// (item-to-item collaborative filtering). Diisi ulang oleh job cmd/itemneighbors,
+// Rank 1 = paling mirip.
+type ProductNeighbor struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ProductID  uint      `gorm:"not null;uniqueIndex:idx_product_neighbor;index:idx_product_neighbor_rank,priority:1" json:"product_id"`
+	NeighborID uint      `gorm:"not null;uniqueIndex:idx_product_neighbor;index" json:"neighbor_id"`
+	Rank       uint      `gorm:"not null;index:idx_product_neighbor_rank,priority:2" json:"rank"`
+	Score      float64   `gorm:"not null" json:"score"`   // cosine similarity 0..1
+	Support    uint      `gorm:"not null" json:"support"` // jumlah user yang berinteraksi dengan kedua produk
+	ComputedAt time.Time `gorm:"not null" json:"computed_at"`
+}
@@ -0,0 +1,14 @@
+// models/productView.go
+package models
+
+import "time"
+
+// ProductView - satu kali halaman produk dibuka. UserID nil untuk pengunjung anonim yang
+// dikenali dari VisitorID; saat login view milik visitor tersebut dipindahkan ke user.
+type ProductView struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    *uint     `gorm:"index:idx_product_view_user,priority:1" json:"user_id"`
+	VisitorID string    `gorm:"size:64;index" json:"-"`
+	ProductID uint      `gorm:"not null;index" json:"product_id"`
+	ViewedAt  time.Time `gorm:"not null;index;index:idx_product_view_user,priority:2" json:"viewed_at"`
+}
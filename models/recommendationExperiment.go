@@ -0,0 +1,31 @@
+// models/recommendationExperiment.go
+package models
+
+import "time"
+
+// RecommendationExperiment - A/B test strategi rekomendasi. Hanya satu experiment yang aktif,
+// user dibagi ke arm secara deterministik berdasarkan nama experiment dan ID user/visitor.
+type RecommendationExperiment struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	Name      string          `gorm:"size:100;not null;uniqueIndex" json:"name"`
+	Active    bool            `gorm:"not null;default:false;index" json:"active"`
+	Arms      []ExperimentArm `gorm:"type:json;serializer:json" json:"arms"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// ExperimentArm - satu varian: campuran strategi berbobot dan batas keragaman hasil
+type ExperimentArm struct {
+	Name           string           `json:"name"`
+	Traffic        uint             `json:"traffic"` // bobot traffic relatif terhadap arm lain
+	Strategies     []StrategyWeight `json:"strategies"`
+	MaxPerShop     int              `json:"max_per_shop,omitempty"`     // 0 = tanpa batas
+	MaxPerCategory int              `json:"max_per_category,omitempty"` // 0 = tanpa batas
+}
+
+// StrategyWeight - strategi dari registry recommend beserta bobotnya di komposisi
+type StrategyWeight struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+	Limit  int     `json:"limit,omitempty"` // maksimal produk dari strategi ini, 0 = tanpa batas
+}
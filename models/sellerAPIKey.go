@@ -0,0 +1,47 @@
+// models/sellerAPIKey.go
+package models
+
+import "time"
+
+// Scope yang bisa diberikan ke API key seller
+const (
+	APIKeyScopeProductsRead  = "products:read"
+	APIKeyScopeProductsWrite = "products:write"
+	APIKeyScopeOrdersRead    = "orders:read"
+)
+
+// ValidAPIKeyScopes - daftar scope yang dikenali
+var ValidAPIKeyScopes = []string{
+	APIKeyScopeProductsRead,
+	APIKeyScopeProductsWrite,
+	APIKeyScopeOrdersRead,
+}
+
+// SellerAPIKey - API key untuk integrasi seller (ERP, inventory sync).
+// Secret hanya disimpan dalam bentuk hash, prefix dipakai untuk lookup.
+type SellerAPIKey struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	SellerID   uint       `gorm:"not null;index" json:"seller_id"`
+	Name       string     `gorm:"size:100" json:"name"`
+	Prefix     string     `gorm:"size:16;uniqueIndex;not null" json:"prefix"`
+	SecretHash string     `gorm:"size:64;not null" json:"-"`
+	Scopes     []string   `gorm:"type:json;serializer:json" json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	Seller Seller `gorm:"foreignKey:SellerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+// IsUsable - key belum di-revoke dan belum expired
+func (k *SellerAPIKey) IsUsable(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	if k.ExpiresAt != nil && now.After(*k.ExpiresAt) {
+		return false
+	}
+	return true
+}
@@ -14,5 +14,15 @@ type SellerProfile struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 
+	// Perpajakan: hanya seller PKP (Pengusaha Kena Pajak) yang memungut PPN
+	IsPKP        bool   `gorm:"not null;default:false" json:"is_pkp"`
+	NPWP         string `gorm:"size:30" json:"npwp"`
+	PriceTaxMode string `gorm:"size:20;not null;default:inclusive" json:"price_tax_mode"`
+
+	FollowerCount uint `gorm:"not null;default:0" json:"follower_count"`
+
+	// Toko sedang libur, pembeli mendapat peringatan di keranjang
+	OnHoliday bool `gorm:"not null;default:false" json:"on_holiday"`
+
 	Seller Seller `gorm:"foreignKey:SellerID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
 }
@@ -0,0 +1,50 @@
+// models/shipment.go
+package models
+
+import "time"
+
+// Status shipment, urut sesuai alur pengiriman
+const (
+	ShipmentPending        = "pending"
+	ShipmentPickedUp       = "picked_up"
+	ShipmentInTransit      = "in_transit"
+	ShipmentOutForDelivery = "out_for_delivery"
+	ShipmentDelivered      = "delivered"
+	ShipmentFailed         = "failed"
+	ShipmentReturned       = "returned"
+	ShipmentCompleted      = "completed"
+)
+
+// Shipment - paket yang dikirim seller ke user, berisi satu atau lebih item pembelian
+type Shipment struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	SellerID    uint       `gorm:"not null;index" json:"seller_id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	Courier     string     `gorm:"size:50;not null;uniqueIndex:idx_courier_awb" json:"courier"`
+	AWB         string     `gorm:"size:100;not null;uniqueIndex:idx_courier_awb" json:"awb"` // nomor resi
+	Status      string     `gorm:"size:30;not null;default:pending" json:"status"`
+	DeliveredAt *time.Time `json:"delivered_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	Events []ShipmentEvent      `gorm:"foreignKey:ShipmentID" json:"events,omitempty"`
+	Items  []ProductUserHistory `gorm:"foreignKey:ShipmentID" json:"items,omitempty"`
+	Seller Seller               `gorm:"foreignKey:SellerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	User   User                 `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+// ShipmentEvent - satu titik di timeline tracking. ExternalID unik per shipment
+// sehingga webhook yang dikirim ulang oleh kurir tidak membuat event ganda.
+type ShipmentEvent struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ShipmentID  uint      `gorm:"not null;uniqueIndex:idx_shipment_event" json:"shipment_id"`
+	ExternalID  string    `gorm:"size:100;not null;uniqueIndex:idx_shipment_event" json:"external_id"`
+	Status      string    `gorm:"size:30;not null" json:"status"`
+	Description string    `gorm:"size:255" json:"description"`
+	Location    string    `gorm:"size:100" json:"location"`
+	OccurredAt  time.Time `gorm:"index" json:"occurred_at"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	Shipment *Shipment `gorm:"foreignKey:ShipmentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
@@ -0,0 +1,21 @@
+// models/tax.go
+package models
+
+import "time"
+
+// Mode harga seller terhadap PPN
+const (
+	PriceTaxInclusive = "inclusive" // harga produk sudah termasuk PPN
+	PriceTaxExclusive = "exclusive" // PPN ditambahkan di atas harga produk
+)
+
+// TaxRule - tarif PPN per kategori produk. Category kosong adalah tarif default untuk
+// kategori yang tidak punya aturan sendiri. Rate 0 untuk barang bebas PPN.
+type TaxRule struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Category    string    `gorm:"size:255;not null;uniqueIndex" json:"category"`
+	Rate        float64   `gorm:"not null" json:"rate"` // persen, contoh 11
+	Description string    `gorm:"size:255" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,27 @@
+// models/trendingProduct.go
+package models
+
+import "time"
+
+// Window ranking trending
+const (
+	TrendingWindowDay  = "24h"
+	TrendingWindowWeek = "7d"
+)
+
+// TrendingProduct - ranking trending yang dimaterialisasi oleh utils.RefreshTrendingProducts.
+// City kosong = ranking nasional, selain itu nama kota yang sudah dinormalisasi
+// (shipping.NormalizeCity) dan hanya menghitung pembeli dari kota tersebut.
+// Kolom window disimpan sebagai time_window karena WINDOW reserved word di MySQL 8.
+type TrendingProduct struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Window     string    `gorm:"column:time_window;size:8;not null;uniqueIndex:idx_trending_product,priority:1;index:idx_trending_rank,priority:1" json:"window"`
+	City       string    `gorm:"size:100;not null;uniqueIndex:idx_trending_product,priority:2;index:idx_trending_rank,priority:2" json:"city"`
+	ProductID  uint      `gorm:"not null;uniqueIndex:idx_trending_product,priority:3;index" json:"product_id"`
+	Rank       uint      `gorm:"not null;index:idx_trending_rank,priority:3" json:"rank"`
+	Score      float64   `gorm:"not null" json:"score"`
+	Sales      uint      `gorm:"not null" json:"sales"` // jumlah unit terjual di dalam window
+	Carts      uint      `gorm:"not null" json:"carts"`
+	Views      uint      `gorm:"not null" json:"views"`
+	ComputedAt time.Time `gorm:"not null" json:"computed_at"`
+}
@@ -0,0 +1,26 @@
+// models/userAddress.go
+package models
+
+import "time"
+
+// UserAddress - alamat pengiriman user (rumah, kantor, keluarga, dll)
+type UserAddress struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"not null;index" json:"user_id"`
+	Label         string    `gorm:"size:50" json:"label"` // contoh: Rumah, Kantor
+	RecipientName string    `gorm:"size:100;not null" json:"recipient_name"`
+	Phone         string    `gorm:"size:20;not null" json:"phone"`
+	Province      string    `gorm:"size:100;not null" json:"province"`
+	City          string    `gorm:"size:100;not null" json:"city"` // kota / kabupaten
+	District      string    `gorm:"size:100;not null" json:"district"`
+	PostalCode    string    `gorm:"size:5;not null" json:"postal_code"`
+	AddressLine   string    `gorm:"type:text" json:"address_line"` // jalan, nomor rumah, RT/RW
+	Latitude      *float64  `json:"latitude"`
+	Longitude     *float64  `json:"longitude"`
+	IsDefault     bool      `gorm:"default:false" json:"is_default"`
+	RegionWarning string    `gorm:"size:255" json:"region_warning,omitempty"` // kota/kode pos belum ada di dataset wilayah
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
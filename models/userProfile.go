@@ -13,4 +13,9 @@ type UserProfile struct {
 	PhotoProfile string    `json:"photo_profile"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	// Riwayat produk yang dilihat tidak dicatat selama dijeda
+	ViewHistoryPaused bool `gorm:"not null;default:false" json:"view_history_paused"`
+	// View yang masih di buffer ViewRecorder dengan waktu sebelum riwayat dihapus tidak ditulis lagi
+	ViewHistoryClearedAt *time.Time `json:"view_history_cleared_at"`
 }
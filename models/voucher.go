@@ -0,0 +1,64 @@
+// models/voucher.go
+package models
+
+import (
+	"ecommerce-golang/money"
+	"time"
+)
+
+// Tipe voucher
+const (
+	VoucherPercentage   = "percentage"    // potongan persen dari subtotal
+	VoucherFixed        = "fixed"         // potongan nominal
+	VoucherFreeShipping = "free_shipping" // subsidi ongkir
+)
+
+// Cakupan voucher
+const (
+	VoucherScopePlatform = "platform" // semua produk
+	VoucherScopeSeller   = "seller"   // produk dari satu toko
+	VoucherScopeCategory = "category" // produk dengan kategori tertentu
+)
+
+// Status redemption
+const (
+	RedemptionApplied  = "applied"  // sedang dipasang di keranjang, kuota dipegang sampai ExpiresAt
+	RedemptionRedeemed = "redeemed" // sudah dipakai di pesanan
+)
+
+// Voucher - kode promo. Nilai 0 pada MaxDiscount, UsageLimit dan PerUserLimit berarti tanpa batas.
+type Voucher struct {
+	ID           uint        `gorm:"primaryKey" json:"id"`
+	Code         string      `gorm:"size:50;not null;uniqueIndex" json:"code"`
+	Name         string      `gorm:"size:255;not null" json:"name"`
+	Description  string      `gorm:"type:text" json:"description"`
+	Type         string      `gorm:"size:20;not null" json:"type"`
+	Value        float64     `gorm:"not null" json:"value"` // persen untuk percentage, rupiah untuk fixed/free_shipping
+	MinSpend     money.Money `gorm:"default:0" json:"min_spend"`
+	MaxDiscount  money.Money `gorm:"default:0" json:"max_discount"`
+	Scope        string      `gorm:"size:20;not null;default:platform" json:"scope"`
+	SellerID     *uint       `gorm:"index" json:"seller_id"`   // wajib untuk scope seller, voucher toko juga bisa dibatasi kategori
+	Category     string      `gorm:"size:255" json:"category"` // wajib untuk scope category
+	StartsAt     time.Time   `gorm:"not null" json:"starts_at"`
+	EndsAt       time.Time   `gorm:"not null" json:"ends_at"`
+	UsageLimit   uint        `gorm:"default:0" json:"usage_limit"`
+	PerUserLimit uint        `gorm:"not null" json:"per_user_limit"`
+	UsedCount    uint        `gorm:"default:0" json:"used_count"`
+	IsActive     bool        `gorm:"not null" json:"is_active"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// VoucherRedemption - pemakaian voucher oleh user, dihitung untuk batas global dan per user
+type VoucherRedemption struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	VoucherID uint       `gorm:"not null;index" json:"voucher_id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	Status    string     `gorm:"size:20;not null;default:applied" json:"status"`
+	ExpiresAt *time.Time `gorm:"index" json:"expires_at"` // batas klaim applied, nil setelah redeemed
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+
+	Voucher Voucher `gorm:"foreignKey:VoucherID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"voucher,omitempty"`
+	User    User    `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
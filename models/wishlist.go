@@ -0,0 +1,41 @@
+// models/wishlist.go
+package models
+
+import "time"
+
+// WishlistCollection - kelompok wishlist buatan user, contoh "Birthday ideas"
+type WishlistCollection struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_user_collection_name" json:"user_id"`
+	Name      string    `gorm:"size:100;not null;uniqueIndex:idx_user_collection_name" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	User User `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+// WishlistItem - produk yang disimpan user. Satu produk hanya sekali per user,
+// CollectionID nil berarti belum dimasukkan ke koleksi manapun.
+type WishlistItem struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	UserID       uint      `gorm:"not null;uniqueIndex:idx_user_wishlist_product" json:"user_id"`
+	ProductID    uint      `gorm:"not null;uniqueIndex:idx_user_wishlist_product;index" json:"product_id"`
+	CollectionID *uint     `gorm:"index" json:"collection_id"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	Product    *Product            `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"product,omitempty"`
+	Collection *WishlistCollection `gorm:"foreignKey:CollectionID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"-"`
+	User       User                `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+// ShopFollow - user mengikuti toko. Jumlah follower disimpan di SellerProfile.FollowerCount.
+type ShopFollow struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_user_shop_follow" json:"user_id"`
+	SellerID  uint      `gorm:"not null;uniqueIndex:idx_user_shop_follow;index" json:"seller_id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	User   User   `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Seller Seller `gorm:"foreignKey:SellerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
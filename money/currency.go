@@ -0,0 +1,74 @@
+// money/currency.go
+package money
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Currency - informasi mata uang untuk konversi dan format tampilan
+type Currency struct {
+	Code      string
+	Exponent  int // jumlah digit minor unit (IDR 0, USD 2)
+	Symbol    string
+	Thousands string
+	Decimal   string
+}
+
+var currencies = map[string]Currency{
+	"IDR": {Code: "IDR", Exponent: 0, Symbol: "Rp", Thousands: ".", Decimal: ","},
+	"USD": {Code: "USD", Exponent: 2, Symbol: "$", Thousands: ",", Decimal: "."},
+	"SGD": {Code: "SGD", Exponent: 2, Symbol: "S$", Thousands: ",", Decimal: "."},
+	"MYR": {Code: "MYR", Exponent: 2, Symbol: "RM", Thousands: ",", Decimal: "."},
+	"EUR": {Code: "EUR", Exponent: 2, Symbol: "€", Thousands: ".", Decimal: ","},
+	"JPY": {Code: "JPY", Exponent: 0, Symbol: "¥", Thousands: ",", Decimal: "."},
+	"AUD": {Code: "AUD", Exponent: 2, Symbol: "A$", Thousands: ",", Decimal: "."},
+}
+
+// LookupCurrency - info mata uang, kode yang tidak dikenal diperlakukan seperti 2 digit desimal
+func LookupCurrency(code string) Currency {
+	if c, ok := currencies[strings.ToUpper(code)]; ok {
+		return c
+	}
+	return Currency{Code: code, Exponent: 2, Symbol: code + " ", Thousands: ",", Decimal: "."}
+}
+
+// IsKnownCurrency - kode mata uang ada di tabel
+func IsKnownCurrency(code string) bool {
+	_, ok := currencies[strings.ToUpper(code)]
+	return ok
+}
+
+// Format - format tampilan, contoh "Rp15.000", "-Rp2.500", "$1,234.56"
+func Format(m Money) string {
+	c := LookupCurrency(m.Code())
+
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	if c.Exponent > 0 {
+		for len(digits) <= c.Exponent {
+			digits = "0" + digits
+		}
+	}
+	whole, fraction := digits[:len(digits)-c.Exponent], digits[len(digits)-c.Exponent:]
+
+	var grouped strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(c.Thousands)
+		}
+		grouped.WriteRune(r)
+	}
+
+	result := sign + c.Symbol + grouped.String()
+	if fraction != "" {
+		result += c.Decimal + fraction
+	}
+	return result
+}
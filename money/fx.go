@@ -0,0 +1,169 @@
+// money/fx.go
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrUnsupportedCurrency = errors.New("unsupported display currency")
+
+// RateTable - kurs untuk tampilan: berapa rupiah untuk 1 unit mata uang lain (contoh USD=16250).
+// Hanya dipakai untuk menampilkan harga, transaksi tetap dalam IDR.
+type RateTable struct {
+	mu    sync.RWMutex
+	rates map[string]float64
+}
+
+func NewRateTable(rates map[string]float64) *RateTable {
+	t := &RateTable{}
+	t.Set(rates)
+	return t
+}
+
+// Set - mengganti seluruh isi tabel kurs. Kurs <= 0 dan mata uang tidak dikenal diabaikan.
+func (t *RateTable) Set(rates map[string]float64) {
+	clean := map[string]float64{Settlement: 1}
+	for code, rate := range rates {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if rate > 0 && IsKnownCurrency(code) && code != Settlement {
+			clean[code] = rate
+		}
+	}
+	t.mu.Lock()
+	t.rates = clean
+	t.mu.Unlock()
+}
+
+// Rate - rupiah per 1 unit mata uang
+func (t *RateTable) Rate(code string) (float64, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	rate, ok := t.rates[strings.ToUpper(code)]
+	return rate, ok
+}
+
+// Currencies - kode mata uang yang bisa dipakai untuk tampilan
+func (t *RateTable) Currencies() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	codes := make([]string, 0, len(t.rates))
+	for code := range t.rates {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Convert - konversi nominal rupiah ke mata uang tampilan, dibulatkan half-up ke minor unit tujuan
+func (t *RateTable) Convert(m Money, to string) (Money, error) {
+	to = strings.ToUpper(to)
+	if m.Code() != Settlement {
+		return Money{}, fmt.Errorf("money: can only convert from %s", Settlement)
+	}
+	if to == Settlement {
+		return IDR(m.Amount), nil
+	}
+
+	rate, ok := t.Rate(to)
+	if !ok {
+		return Money{}, ErrUnsupportedCurrency
+	}
+	major := float64(m.Amount) / rate
+	minor := major * math.Pow10(LookupCurrency(to).Exponent)
+	return Money{Amount: int64(math.Floor(minor + 0.5)), Currency: to}, nil
+}
+
+// ParseRates - format "USD=16250,SGD=12100"
+func ParseRates(s string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		code, value, found := strings.Cut(part, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid FX rate %q, expected CODE=rate", part)
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid FX rate %q", part)
+		}
+		rates[strings.ToUpper(strings.TrimSpace(code))] = rate
+	}
+	return rates, ValidateRates(rates)
+}
+
+// DefaultRatesIndicative - kurs indikatif untuk development, production sebaiknya memakai FX_RATES/FX_RATES_FILE
+func DefaultRatesIndicative() map[string]float64 {
+	return map[string]float64{
+		"USD": 16250,
+		"SGD": 12100,
+		"MYR": 3450,
+		"EUR": 17600,
+		"JPY": 108,
+		"AUD": 10600,
+	}
+}
+
+// LoadRates - membaca kurs dari file JSON {"USD": 16250}
+func LoadRates(path string) (map[string]float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rates map[string]float64
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, err
+	}
+	return rates, ValidateRates(rates)
+}
+
+// ValidateRates - semua kode harus mata uang yang dikenal dengan kurs > 0, minimal satu kurs.
+// Set mengabaikan entri yang tidak valid, jadi konfigurasi dicek di sini supaya salah ketik
+// tidak diam-diam menghilangkan mata uang.
+func ValidateRates(rates map[string]float64) error {
+	if len(rates) == 0 {
+		return errors.New("no FX rates")
+	}
+	for code, rate := range rates {
+		normalized := strings.ToUpper(strings.TrimSpace(code))
+		if !IsKnownCurrency(normalized) || normalized == Settlement {
+			return fmt.Errorf("unknown display currency %q", code)
+		}
+		if rate <= 0 {
+			return fmt.Errorf("invalid FX rate for %s: %v", normalized, rate)
+		}
+	}
+	return nil
+}
+
+var (
+	defaultRates     *RateTable
+	defaultRatesOnce sync.Once
+)
+
+// DefaultRates - tabel kurs yang dipakai server. FX_RATES_FILE/FX_RATES dipasang saat startup
+// lewat SetDefaultRates (config.ConnectFXRates), selain itu memakai DefaultRatesIndicative.
+func DefaultRates() *RateTable {
+	defaultRatesOnce.Do(func() {
+		if defaultRates != nil {
+			return
+		}
+		defaultRates = NewRateTable(DefaultRatesIndicative())
+	})
+	return defaultRates
+}
+
+// SetDefaultRates - mengganti tabel kurs default, dipanggil saat startup sebelum server menerima request
+func SetDefaultRates(rates *RateTable) {
+	defaultRates = rates
+}
@@ -0,0 +1,149 @@
+// money/money.go
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Settlement - mata uang transaksi dan penyimpanan. Semua nilai di database dalam rupiah penuh,
+// mata uang lain hanya untuk tampilan.
+const Settlement = "IDR"
+
+// Money - nominal dalam satuan terkecil (minor unit) mata uang ISO 4217.
+// Untuk IDR satuan terkecil adalah rupiah penuh. Currency kosong dianggap IDR.
+//
+// Di database disimpan sebagai BIGINT rupiah, di JSON sebagai
+// {"amount": 15000, "currency": "IDR", "formatted": "Rp15.000"}.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// IDR - nominal rupiah
+func IDR(amount int64) Money {
+	return Money{Amount: amount, Currency: Settlement}
+}
+
+// FromFloat - konversi nominal rupiah float64 (data lama/input form), dibulatkan ke rupiah terdekat
+func FromFloat(amount float64) Money {
+	return IDR(int64(math.Round(amount)))
+}
+
+// Code - kode mata uang, default IDR
+func (m Money) Code() string {
+	if m.Currency == "" {
+		return Settlement
+	}
+	return m.Currency
+}
+
+// Major - nominal dalam satuan utama (contoh USD 12.34), hanya untuk tampilan dan perhitungan kasar
+func (m Money) Major() float64 {
+	return float64(m.Amount) / math.Pow10(LookupCurrency(m.Code()).Exponent)
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) String() string {
+	return Format(m)
+}
+
+// Value - disimpan sebagai BIGINT rupiah. Nilai non-IDR tidak boleh masuk database.
+func (m Money) Value() (driver.Value, error) {
+	if m.Code() != Settlement {
+		return nil, fmt.Errorf("money: cannot store %s amount, settlement currency is %s", m.Code(), Settlement)
+	}
+	return m.Amount, nil
+}
+
+// Scan - membaca kolom BIGINT, juga DOUBLE/DECIMAL dari kolom lama sebelum migrasi
+func (m *Money) Scan(src interface{}) error {
+	m.Currency = Settlement
+	switch v := src.(type) {
+	case nil:
+		m.Amount = 0
+	case int64:
+		m.Amount = v
+	case float64:
+		m.Amount = int64(math.Round(v))
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+	return nil
+}
+
+func (m *Money) scanString(s string) error {
+	if amount, err := strconv.ParseInt(s, 10, 64); err == nil {
+		m.Amount = amount
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("money: cannot scan %q", s)
+	}
+	m.Amount = int64(math.Round(f))
+	return nil
+}
+
+// GormDataType - tipe kolom untuk AutoMigrate
+func (Money) GormDataType() string {
+	return "bigint"
+}
+
+type moneyJSON struct {
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Formatted string `json:"formatted,omitempty"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{
+		Amount:    m.Amount,
+		Currency:  m.Code(),
+		Formatted: Format(m),
+	})
+}
+
+// UnmarshalJSON - menerima object {"amount", "currency"} atau angka biasa (rupiah)
+// supaya client dan file konfigurasi lama tetap bisa dipakai. Mata uang selain IDR ditolak
+// di sini karena nilainya tidak bisa disimpan (lihat Value).
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var v moneyJSON
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		code := strings.ToUpper(v.Currency)
+		if code == "" {
+			code = Settlement
+		}
+		if _, ok := currencies[code]; !ok {
+			return fmt.Errorf("money: unknown currency %q", v.Currency)
+		}
+		if code != Settlement {
+			return fmt.Errorf("money: %s amounts are not accepted, settlement currency is %s", code, Settlement)
+		}
+		m.Amount, m.Currency = v.Amount, code
+		return nil
+	}
+
+	var f float64
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("money: expected number or object, got %s", data)
+	}
+	*m = FromFloat(f)
+	return nil
+}
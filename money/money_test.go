@@ -0,0 +1,82 @@
+package money
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{input: `15000`, want: IDR(15000)},
+		{input: `15000.5`, want: IDR(15001)},
+		{input: `{"amount": 15000, "currency": "IDR"}`, want: IDR(15000)},
+		{input: `{"amount": 15000, "currency": "idr"}`, want: IDR(15000)},
+		{input: `{"amount": 15000}`, want: IDR(15000)},
+		{input: `{"amount": 1234, "currency": "USD"}`, wantErr: true}, // hanya untuk tampilan, tidak bisa disimpan
+		{input: `{"amount": 1234, "currency": "XXX"}`, wantErr: true},
+		{input: `"15000"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		var got Money
+		err := json.Unmarshal([]byte(tt.input), &got)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("Unmarshal(%s) = %+v, want %+v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates(" usd=16250, SGD = 12100 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rates["USD"] != 16250 || rates["SGD"] != 12100 || len(rates) != 2 {
+		t.Errorf("ParseRates = %v", rates)
+	}
+
+	for _, input := range []string{"", "USD", "USD=abc", "USD=0", "USX=16250", "IDR=1"} {
+		if _, err := ParseRates(input); err == nil {
+			t.Errorf("ParseRates(%q) should fail", input)
+		}
+	}
+}
+
+func TestLoadRates(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	rates, err := LoadRates(write("ok.json", `{"USD": 16250, "JPY": 108}`))
+	if err != nil || rates["USD"] != 16250 || rates["JPY"] != 108 {
+		t.Errorf("LoadRates = %v, %v", rates, err)
+	}
+
+	for name, content := range map[string]string{
+		"invalid.json": `{"USD": }`,
+		"empty.json":   `{}`,
+		"unknown.json": `{"USD": 16250, "US": 1}`,
+		"zero.json":    `{"USD": 0}`,
+	} {
+		if _, err := LoadRates(write(name, content)); err == nil {
+			t.Errorf("LoadRates(%s) should fail", name)
+		}
+	}
+	if _, err := LoadRates(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("LoadRates of a missing file should fail")
+	}
+}
@@ -0,0 +1,413 @@
+// pricing/engine.go
+package pricing
+
+import (
+	"ecommerce-golang/money"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrVoucherNotApplicable = errors.New("voucher does not apply to any item in the cart")
+	ErrVoucherMinSpend      = errors.New("cart does not reach the minimum spend for this voucher")
+	ErrVoucherUnknownType   = errors.New("unknown voucher type")
+)
+
+// IsVoucherRejected - error Calculate karena isi keranjang tidak memenuhi syarat voucher.
+// Error jenis lain berarti data voucher sendiri tidak valid.
+func IsVoucherRejected(err error) bool {
+	return errors.Is(err, ErrVoucherNotApplicable) || errors.Is(err, ErrVoucherMinSpend)
+}
+
+// Tipe voucher, sama dengan models.Voucher*
+const (
+	VoucherPercentage   = "percentage"
+	VoucherFixed        = "fixed"
+	VoucherFreeShipping = "free_shipping"
+)
+
+// Line - satu baris keranjang
+type Line struct {
+	CartID    uint
+	ProductID uint
+	SellerID  uint
+	Category  string
+	Quantity  int64
+	UnitPrice int64
+
+	// Sebagian quantity bisa mendapat harga flash sale sesuai kuota yang dipegang user
+	FlashSaleQuantity int64
+	FlashSalePrice    int64
+
+	// Tax - PPN baris ini, ditentukan dari status PKP seller dan tarif kategori produk
+	Tax Tax
+}
+
+// Tax - aturan PPN satu baris. BasisPoints 0 berarti tidak dipungut PPN
+// (seller bukan PKP atau barang bebas PPN).
+type Tax struct {
+	BasisPoints int64
+	Inclusive   bool // harga produk sudah termasuk PPN
+}
+
+// Subtotal - harga baris sebelum diskon
+func (l Line) Subtotal() int64 {
+	flashQuantity := min(max(l.FlashSaleQuantity, 0), l.Quantity)
+	return flashQuantity*l.FlashSalePrice + (l.Quantity-flashQuantity)*l.UnitPrice
+}
+
+// Voucher - aturan voucher yang sudah dicek masa berlakunya oleh pemanggil
+type Voucher struct {
+	Code        string
+	Type        string
+	BasisPoints int64 // untuk percentage
+	Amount      int64 // untuk fixed; untuk free_shipping batas subsidi ongkir (0 = gratis penuh)
+	MinSpend    int64
+	MaxDiscount int64 // 0 = tanpa batas
+	SellerID    *uint
+	Category    string
+}
+
+// AppliesTo - apakah baris keranjang masuk cakupan voucher
+func (v Voucher) AppliesTo(line Line) bool {
+	if v.SellerID != nil && line.SellerID != *v.SellerID {
+		return false
+	}
+	if v.Category != "" && !strings.EqualFold(v.Category, line.Category) {
+		return false
+	}
+	return true
+}
+
+// Input - isi keranjang dan komponen harga lain
+type Input struct {
+	Lines   []Line
+	Voucher *Voucher
+	// Shipping - ongkir per seller yang sudah dipilih user, key = seller ID
+	Shipping map[uint]int64
+}
+
+// LineResult - rincian harga per baris
+type LineResult struct {
+	CartID            uint        `json:"cart_id"`
+	ProductID         uint        `json:"product_id"`
+	SellerID          uint        `json:"seller_id"`
+	Quantity          int64       `json:"quantity"`
+	UnitPrice         money.Money `json:"unit_price"`
+	FlashSaleQuantity int64       `json:"flash_sale_quantity,omitempty"`
+	FlashSalePrice    money.Money `json:"flash_sale_price,omitempty"`
+	Subtotal          money.Money `json:"subtotal"`
+	VoucherEligible   bool        `json:"voucher_eligible"`
+	Discount          money.Money `json:"discount"`
+	Total             money.Money `json:"total"`
+	TaxBasisPoints    int64       `json:"tax_basis_points"`
+	TaxInclusive      bool        `json:"tax_inclusive"`
+	TaxBase           money.Money `json:"tax_base"` // DPP
+	Tax               money.Money `json:"tax"`
+}
+
+// SellerResult - rincian harga per seller (satu paket kiriman)
+type SellerResult struct {
+	SellerID         uint        `json:"seller_id"`
+	Subtotal         money.Money `json:"subtotal"`
+	Discount         money.Money `json:"discount"`
+	Shipping         money.Money `json:"shipping"`
+	ShippingDiscount money.Money `json:"shipping_discount"`
+	Tax              money.Money `json:"tax"`          // seluruh PPN paket ini
+	TaxIncluded      money.Money `json:"tax_included"` // bagian PPN yang sudah termasuk di harga produk
+	TaxLines         []TaxLine   `json:"tax_lines,omitempty"`
+	Total            money.Money `json:"total"`
+}
+
+// TaxLine - ringkasan PPN per tarif dalam satu paket seller, untuk struk dan faktur
+type TaxLine struct {
+	BasisPoints int64       `json:"basis_points"`
+	Rate        string      `json:"rate"`
+	Inclusive   bool        `json:"inclusive"`
+	Base        money.Money `json:"base"` // DPP
+	Tax         money.Money `json:"tax"`
+}
+
+// VoucherResult - status voucher dalam perhitungan
+type VoucherResult struct {
+	Code             string      `json:"code"`
+	Type             string      `json:"type"`
+	Applied          bool        `json:"applied"`
+	Error            string      `json:"error,omitempty"`
+	EligibleSubtotal money.Money `json:"eligible_subtotal"`
+	Discount         money.Money `json:"discount"`
+	ShippingDiscount money.Money `json:"shipping_discount"`
+}
+
+// TraceStep - satu langkah perhitungan untuk penjelasan ke user/CS
+type TraceStep struct {
+	Step   string      `json:"step"`
+	Detail string      `json:"detail"`
+	Amount money.Money `json:"amount"`
+}
+
+// Result - hasil perhitungan keranjang
+type Result struct {
+	Lines            []LineResult   `json:"lines"`
+	Sellers          []SellerResult `json:"sellers"`
+	Subtotal         money.Money    `json:"subtotal"`
+	Discount         money.Money    `json:"discount"`
+	Shipping         money.Money    `json:"shipping"`
+	ShippingDiscount money.Money    `json:"shipping_discount"`
+	Tax              money.Money    `json:"tax"`
+	TaxIncluded      money.Money    `json:"tax_included"`
+	GrandTotal       money.Money    `json:"grand_total"`
+	Voucher          *VoucherResult `json:"voucher,omitempty"`
+	Trace            []TraceStep    `json:"trace"`
+}
+
+// Calculate - menghitung total keranjang:
+//  1. subtotal per baris (harga flash sale untuk quantity yang kuotanya dipegang user)
+//  2. potongan voucher, dibagi ke baris eligible secara proporsional (largest remainder)
+//  3. ongkir per seller dan subsidi ongkir dari voucher free_shipping
+//  4. PPN per seller per tarif dari harga setelah diskon, dibulatkan half-up lalu dibagi ke baris.
+//     PPN exclusive ditambahkan ke total, PPN inclusive hanya dipisahkan dari harga (DPP + PPN).
+//
+// Error hanya dikembalikan jika voucher tidak bisa dipakai; Result tetap lengkap tanpa potongan voucher.
+func Calculate(in Input) (Result, error) {
+	var result Result
+	result.Lines = make([]LineResult, len(in.Lines))
+
+	sellers := make(map[uint]*SellerResult)
+	var sellerIDs []uint
+	sellerOf := func(id uint) *SellerResult {
+		seller, ok := sellers[id]
+		if !ok {
+			seller = &SellerResult{SellerID: id}
+			sellers[id] = seller
+			sellerIDs = append(sellerIDs, id)
+		}
+		return seller
+	}
+
+	for i, line := range in.Lines {
+		subtotal := line.Subtotal()
+		result.Lines[i] = LineResult{
+			CartID:            line.CartID,
+			ProductID:         line.ProductID,
+			SellerID:          line.SellerID,
+			Quantity:          line.Quantity,
+			UnitPrice:         money.IDR(line.UnitPrice),
+			FlashSaleQuantity: min(max(line.FlashSaleQuantity, 0), line.Quantity),
+			Subtotal:          money.IDR(subtotal),
+			Total:             money.IDR(subtotal),
+			TaxBasisPoints:    max(line.Tax.BasisPoints, 0),
+			TaxInclusive:      line.Tax.Inclusive,
+		}
+		if result.Lines[i].FlashSaleQuantity > 0 {
+			result.Lines[i].FlashSalePrice = money.IDR(line.FlashSalePrice)
+		}
+		result.Subtotal.Amount += subtotal
+		sellerOf(line.SellerID).Subtotal.Amount += subtotal
+	}
+	result.trace("subtotal", fmt.Sprintf("%d baris", len(in.Lines)), result.Subtotal.Amount)
+
+	for sellerID := range in.Shipping {
+		sellerOf(sellerID)
+	}
+	sort.Slice(sellerIDs, func(i, j int) bool { return sellerIDs[i] < sellerIDs[j] })
+
+	var voucherErr error
+	if in.Voucher != nil {
+		voucherErr = result.applyVoucher(*in.Voucher, in.Lines, in.Shipping, sellerOf)
+	}
+
+	for _, line := range result.Lines {
+		sellers[line.SellerID].Discount.Amount += line.Discount.Amount
+	}
+
+	for _, id := range sellerIDs {
+		seller := sellers[id]
+		seller.Shipping = money.IDR(in.Shipping[id])
+		if seller.ShippingDiscount.Amount > seller.Shipping.Amount {
+			seller.ShippingDiscount = seller.Shipping
+		}
+		result.applyTax(seller)
+		seller.Total = money.IDR(seller.Subtotal.Amount - seller.Discount.Amount + seller.Shipping.Amount -
+			seller.ShippingDiscount.Amount + seller.Tax.Amount - seller.TaxIncluded.Amount)
+
+		result.Discount.Amount += seller.Discount.Amount
+		result.Shipping.Amount += seller.Shipping.Amount
+		result.ShippingDiscount.Amount += seller.ShippingDiscount.Amount
+		result.Tax.Amount += seller.Tax.Amount
+		result.TaxIncluded.Amount += seller.TaxIncluded.Amount
+		result.GrandTotal.Amount += seller.Total.Amount
+		result.Sellers = append(result.Sellers, *seller)
+	}
+
+	if result.Shipping.Amount > 0 {
+		result.trace("shipping", fmt.Sprintf("ongkir %d seller", len(in.Shipping)), result.Shipping.Amount)
+	}
+	if added := result.Tax.Amount - result.TaxIncluded.Amount; added > 0 {
+		result.trace("tax", "PPN di luar harga produk", added)
+	}
+	if result.TaxIncluded.Amount > 0 {
+		result.trace("tax_included", fmt.Sprintf("PPN %s sudah termasuk di harga produk", result.TaxIncluded), 0)
+	}
+	result.trace("grand_total", "subtotal - diskon + ongkir - subsidi ongkir + PPN di luar harga", result.GrandTotal.Amount)
+
+	return result, voucherErr
+}
+
+func (r *Result) applyVoucher(voucher Voucher, lines []Line, shipping map[uint]int64, sellerOf func(uint) *SellerResult) error {
+	r.Voucher = &VoucherResult{Code: voucher.Code, Type: voucher.Type}
+
+	weights := make([]int64, len(r.Lines))
+	eligibleSellers := make(map[uint]int64)
+	for i := range r.Lines {
+		line := &r.Lines[i]
+		line.VoucherEligible = voucher.AppliesTo(lines[i])
+		if line.VoucherEligible {
+			weights[i] = line.Subtotal.Amount
+			r.Voucher.EligibleSubtotal.Amount += line.Subtotal.Amount
+			eligibleSellers[line.SellerID] += line.Subtotal.Amount
+		}
+	}
+
+	fail := func(err error) error {
+		r.Voucher.Error = err.Error()
+		r.trace("voucher", fmt.Sprintf("voucher %s tidak dipakai: %s", voucher.Code, err), 0)
+		return err
+	}
+	if r.Voucher.EligibleSubtotal.Amount <= 0 {
+		return fail(ErrVoucherNotApplicable)
+	}
+	if r.Voucher.EligibleSubtotal.Amount < voucher.MinSpend {
+		return fail(ErrVoucherMinSpend)
+	}
+
+	switch voucher.Type {
+	case VoucherPercentage:
+		discount := PercentFloor(r.Voucher.EligibleSubtotal.Amount, voucher.BasisPoints)
+		detail := fmt.Sprintf("voucher %s %s dari %s", voucher.Code, formatBasisPoints(voucher.BasisPoints), r.Voucher.EligibleSubtotal)
+		if voucher.MaxDiscount > 0 && discount > voucher.MaxDiscount {
+			discount = voucher.MaxDiscount
+			detail += fmt.Sprintf(", maksimal %s", money.IDR(voucher.MaxDiscount))
+		}
+		r.allocateDiscount(discount, weights)
+		r.trace("voucher", detail, -discount)
+	case VoucherFixed:
+		discount := min(voucher.Amount, r.Voucher.EligibleSubtotal.Amount)
+		r.allocateDiscount(discount, weights)
+		r.trace("voucher", fmt.Sprintf("voucher %s potongan %s", voucher.Code, money.IDR(voucher.Amount)), -discount)
+	case VoucherFreeShipping:
+		// Subsidi ongkir hanya untuk paket seller yang punya item eligible
+		ids := make([]uint, 0, len(eligibleSellers))
+		for id := range eligibleSellers {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+		shippingWeights := make([]int64, len(ids))
+		var eligibleShipping int64
+		for i, id := range ids {
+			shippingWeights[i] = shipping[id]
+			eligibleShipping += shipping[id]
+		}
+		subsidy := eligibleShipping
+		if voucher.Amount > 0 && subsidy > voucher.Amount {
+			subsidy = voucher.Amount
+		}
+		for i, share := range Allocate(subsidy, shippingWeights) {
+			sellerOf(ids[i]).ShippingDiscount = money.IDR(share)
+		}
+		r.Voucher.ShippingDiscount = money.IDR(subsidy)
+		r.trace("voucher", fmt.Sprintf("voucher %s subsidi ongkir", voucher.Code), -subsidy)
+	default:
+		return fail(fmt.Errorf("%w %q", ErrVoucherUnknownType, voucher.Type))
+	}
+
+	r.Voucher.Applied = true
+	return nil
+}
+
+func (r *Result) allocateDiscount(discount int64, weights []int64) {
+	for i, share := range Allocate(discount, weights) {
+		r.Lines[i].Discount = money.IDR(share)
+		r.Lines[i].Total = money.IDR(r.Lines[i].Subtotal.Amount - share)
+	}
+	r.Voucher.Discount = money.IDR(discount)
+}
+
+// applyTax - PPN satu seller. Baris dikelompokkan per tarif dan jenis (inclusive/exclusive),
+// pajak dihitung dari total kelompok supaya sama dengan faktur, lalu dibagi ke baris.
+func (r *Result) applyTax(seller *SellerResult) {
+	groups := make(map[Tax][]int)
+	var keys []Tax
+	for i, line := range r.Lines {
+		if line.SellerID != seller.SellerID || line.TaxBasisPoints == 0 {
+			continue
+		}
+		key := Tax{BasisPoints: line.TaxBasisPoints, Inclusive: line.TaxInclusive}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].BasisPoints != keys[j].BasisPoints {
+			return keys[i].BasisPoints < keys[j].BasisPoints
+		}
+		return !keys[i].Inclusive && keys[j].Inclusive
+	})
+
+	for i := range r.Lines {
+		if r.Lines[i].SellerID == seller.SellerID {
+			r.Lines[i].TaxBase = r.Lines[i].Total
+		}
+	}
+
+	for _, key := range keys {
+		indexes := groups[key]
+		weights := make([]int64, len(indexes))
+		var amount int64
+		for j, idx := range indexes {
+			weights[j] = r.Lines[idx].Total.Amount
+			amount += weights[j]
+		}
+
+		base, tax := amount, PercentHalfUp(amount, key.BasisPoints)
+		if key.Inclusive {
+			base = InclusiveTaxBase(amount, key.BasisPoints)
+			tax = amount - base
+		}
+
+		for j, share := range Allocate(tax, weights) {
+			line := &r.Lines[indexes[j]]
+			line.Tax = money.IDR(share)
+			if key.Inclusive {
+				line.TaxBase = money.IDR(line.Total.Amount - share)
+			}
+		}
+
+		seller.TaxLines = append(seller.TaxLines, TaxLine{
+			BasisPoints: key.BasisPoints,
+			Rate:        formatBasisPoints(key.BasisPoints),
+			Inclusive:   key.Inclusive,
+			Base:        money.IDR(base),
+			Tax:         money.IDR(tax),
+		})
+		seller.Tax.Amount += tax
+		if key.Inclusive {
+			seller.TaxIncluded.Amount += tax
+		}
+	}
+}
+
+func (r *Result) trace(step, detail string, amount int64) {
+	r.Trace = append(r.Trace, TraceStep{Step: step, Detail: detail, Amount: money.IDR(amount)})
+}
+
+func formatBasisPoints(bp int64) string {
+	if bp%100 == 0 {
+		return fmt.Sprintf("%d%%", bp/100)
+	}
+	return fmt.Sprintf("%d.%02d%%", bp/100, bp%100)
+}
@@ -0,0 +1,107 @@
+// pricing/money.go
+package pricing
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Semua nominal di package ini dalam rupiah penuh (int64), tidak ada pecahan.
+// Basis point (bp) dipakai untuk persen: 10000 bp = 100%, 1100 bp = 11%.
+
+const fullBasisPoints = 10000
+
+// FromFloat - konversi harga float64 dari database ke rupiah, dibulatkan ke rupiah terdekat
+func FromFloat(amount float64) int64 {
+	return int64(math.Round(amount))
+}
+
+// PercentToBasisPoints - persen (contoh 12.5) ke basis point (1250)
+func PercentToBasisPoints(percent float64) int64 {
+	return int64(math.Round(percent * 100))
+}
+
+// PercentFloor - amount * bp / 10000 dibulatkan ke bawah. Dipakai untuk potongan persen
+// supaya potongan tidak pernah melebihi persentase yang dijanjikan.
+func PercentFloor(amount, bp int64) int64 {
+	if amount <= 0 || bp <= 0 {
+		return 0
+	}
+	q, _ := mulDiv(amount, bp, fullBasisPoints)
+	return q
+}
+
+// PercentHalfUp - amount * bp / 10000 dibulatkan half-up (0.5 ke atas), dipakai untuk pajak
+func PercentHalfUp(amount, bp int64) int64 {
+	if amount <= 0 || bp <= 0 {
+		return 0
+	}
+	q, r := mulDiv(amount, bp, fullBasisPoints)
+	if r*2 >= fullBasisPoints {
+		q++
+	}
+	return q
+}
+
+// InclusiveTaxBase - DPP (dasar pengenaan pajak) dari harga yang sudah termasuk pajak:
+// amount * 10000 / (10000 + bp) dibulatkan half-up. Pajaknya adalah amount - DPP.
+func InclusiveTaxBase(amount, bp int64) int64 {
+	if amount <= 0 || bp <= 0 {
+		return amount
+	}
+	q, r := mulDiv(amount, fullBasisPoints, fullBasisPoints+bp)
+	if r*2 >= fullBasisPoints+bp {
+		q++
+	}
+	return q
+}
+
+// Allocate - membagi total ke beberapa bagian secara proporsional terhadap weights dengan
+// metode largest remainder: tiap bagian dapat floor(total * w / sum), sisa rupiah dibagikan
+// satu per satu ke bagian dengan sisa pembagian terbesar (seri: index terkecil).
+// Jumlah hasil selalu sama persis dengan total.
+func Allocate(total int64, weights []int64) []int64 {
+	shares := make([]int64, len(weights))
+	var sum int64
+	for _, w := range weights {
+		if w > 0 {
+			sum += w
+		}
+	}
+	if total <= 0 || sum == 0 {
+		return shares
+	}
+
+	remainders := make([]int64, len(weights))
+	var allocated int64
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		shares[i], remainders[i] = mulDiv(total, w, sum)
+		allocated += shares[i]
+	}
+
+	for left := total - allocated; left > 0; left-- {
+		best := -1
+		for i, w := range weights {
+			if w <= 0 {
+				continue
+			}
+			if best < 0 || remainders[i] > remainders[best] {
+				best = i
+			}
+		}
+		shares[best]++
+		remainders[best] = -1
+	}
+	return shares
+}
+
+// mulDiv - a * b / c dan sisanya dengan perkalian 128-bit supaya tidak overflow.
+// Untuk a, b >= 0, c > 0 dan hasil bagi yang muat di int64.
+func mulDiv(a, b, c int64) (quotient, remainder int64) {
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	q, r := bits.Div64(hi, lo, uint64(c))
+	return int64(q), int64(r)
+}
@@ -0,0 +1,222 @@
+package pricing
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		weights []int64
+		want    []int64
+	}{
+		{"habis dibagi", 7, []int64{1, 2, 4}, []int64{1, 2, 4}},
+		{"sisa seri ke index terkecil", 100, []int64{1, 1, 1}, []int64{34, 33, 33}},
+		{"sisa ke remainder terbesar", 10000, []int64{33333, 66667}, []int64{3333, 6667}},
+		{"sisa lebih dari satu", 5, []int64{2, 2, 2, 2, 2, 2}, []int64{1, 1, 1, 1, 1, 0}},
+		{"bobot nol dan negatif dilewati", 10, []int64{3, 0, -4, 7}, []int64{3, 0, 0, 7}},
+		{"semua bobot nol", 5, []int64{0, -2}, []int64{0, 0}},
+		{"total nol", 0, []int64{1, 2}, []int64{0, 0}},
+		{"tanpa overflow", 1_000_000_000_000, []int64{3_000_000_000_000, 1_000_000_000_000}, []int64{750_000_000_000, 250_000_000_000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Allocate(tt.total, tt.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Allocate(%d, %v) = %v, want %v", tt.total, tt.weights, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		amount, bp       int64
+		floor, halfUp    int64
+		inclusiveTaxBase int64
+	}{
+		{amount: 100000, bp: 1100, floor: 11000, halfUp: 11000, inclusiveTaxBase: 90090},
+		{amount: 111000, bp: 1100, floor: 12210, halfUp: 12210, inclusiveTaxBase: 100000},
+		{amount: 150, bp: 1100, floor: 16, halfUp: 17, inclusiveTaxBase: 135}, // 16.5 -> 17
+		{amount: 140, bp: 1100, floor: 15, halfUp: 15, inclusiveTaxBase: 126}, // 15.4 -> 15
+		{amount: 61, bp: 1100, floor: 6, halfUp: 7, inclusiveTaxBase: 55},     // DPP 54.95 -> 55
+		{amount: 50, bp: 100, floor: 0, halfUp: 1, inclusiveTaxBase: 50},      // 0.5 -> 1
+		{amount: 49, bp: 100, floor: 0, halfUp: 0, inclusiveTaxBase: 49},      // 0.49 -> 0
+		{amount: 3, bp: 10000, floor: 3, halfUp: 3, inclusiveTaxBase: 2},      // DPP 1.5 -> 2
+		{amount: 99999, bp: 1250, floor: 12499, halfUp: 12500, inclusiveTaxBase: 88888},
+		{amount: 0, bp: 1100, floor: 0, halfUp: 0, inclusiveTaxBase: 0},
+		{amount: -500, bp: 1100, floor: 0, halfUp: 0, inclusiveTaxBase: -500},
+		{amount: 500, bp: 0, floor: 0, halfUp: 0, inclusiveTaxBase: 500},
+	}
+	for _, tt := range tests {
+		if got := PercentFloor(tt.amount, tt.bp); got != tt.floor {
+			t.Errorf("PercentFloor(%d, %d) = %d, want %d", tt.amount, tt.bp, got, tt.floor)
+		}
+		if got := PercentHalfUp(tt.amount, tt.bp); got != tt.halfUp {
+			t.Errorf("PercentHalfUp(%d, %d) = %d, want %d", tt.amount, tt.bp, got, tt.halfUp)
+		}
+		if got := InclusiveTaxBase(tt.amount, tt.bp); got != tt.inclusiveTaxBase {
+			t.Errorf("InclusiveTaxBase(%d, %d) = %d, want %d", tt.amount, tt.bp, got, tt.inclusiveTaxBase)
+		}
+	}
+}
+
+func TestPercentToBasisPoints(t *testing.T) {
+	for percent, want := range map[float64]int64{11: 1100, 12.5: 1250, 33.33: 3333, 0.01: 1, 0: 0} {
+		if got := PercentToBasisPoints(percent); got != want {
+			t.Errorf("PercentToBasisPoints(%v) = %d, want %d", percent, got, want)
+		}
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	seller2 := uint(2)
+	lines := []Line{
+		{CartID: 1, ProductID: 10, SellerID: 1, Category: "Fashion", Quantity: 1, UnitPrice: 30000},
+		{CartID: 2, ProductID: 11, SellerID: 1, Category: "Fashion", Quantity: 2, UnitPrice: 35000},
+	}
+
+	tests := []struct {
+		name          string
+		input         Input
+		wantErr       error
+		wantDiscounts []int64 // per baris
+		wantTax       []int64 // per baris
+		wantShipDisc  int64
+		wantTotal     int64
+	}{
+		{
+			name:          "persen dibagi proporsional",
+			input:         Input{Lines: lines, Voucher: &Voucher{Code: "HEMAT", Type: VoucherPercentage, BasisPoints: 1250}},
+			wantDiscounts: []int64{3750, 8750}, // 12.5% dari 100.000
+			wantTax:       []int64{0, 0},
+			wantTotal:     87500,
+		},
+		{
+			name:          "persen dengan batas maksimal",
+			input:         Input{Lines: lines, Voucher: &Voucher{Code: "HEMAT", Type: VoucherPercentage, BasisPoints: 1250, MaxDiscount: 10000}},
+			wantDiscounts: []int64{3000, 7000},
+			wantTax:       []int64{0, 0},
+			wantTotal:     90000,
+		},
+		{
+			name:          "persen dibulatkan ke bawah",
+			input:         Input{Lines: []Line{{CartID: 1, SellerID: 1, Quantity: 1, UnitPrice: 99999}}, Voucher: &Voucher{Code: "X", Type: VoucherPercentage, BasisPoints: 1250}},
+			wantDiscounts: []int64{12499},
+			wantTax:       []int64{0},
+			wantTotal:     87500,
+		},
+		{
+			name:          "fixed tidak melebihi subtotal eligible",
+			input:         Input{Lines: lines[:1], Voucher: &Voucher{Code: "POTONG", Type: VoucherFixed, Amount: 50000}},
+			wantDiscounts: []int64{30000},
+			wantTax:       []int64{0},
+			wantTotal:     0,
+		},
+		{
+			name:          "minimal belanja tidak tercapai",
+			input:         Input{Lines: lines, Voucher: &Voucher{Code: "HEMAT", Type: VoucherPercentage, BasisPoints: 1250, MinSpend: 150000}},
+			wantErr:       ErrVoucherMinSpend,
+			wantDiscounts: []int64{0, 0},
+			wantTax:       []int64{0, 0},
+			wantTotal:     100000,
+		},
+		{
+			name:          "voucher seller lain",
+			input:         Input{Lines: lines, Voucher: &Voucher{Code: "TOKO2", Type: VoucherFixed, Amount: 5000, SellerID: &seller2}},
+			wantErr:       ErrVoucherNotApplicable,
+			wantDiscounts: []int64{0, 0},
+			wantTax:       []int64{0, 0},
+			wantTotal:     100000,
+		},
+		{
+			name:          "tipe voucher tidak dikenal",
+			input:         Input{Lines: lines, Voucher: &Voucher{Code: "ANEH", Type: "cashback"}},
+			wantErr:       ErrVoucherUnknownType,
+			wantDiscounts: []int64{0, 0},
+			wantTax:       []int64{0, 0},
+			wantTotal:     100000,
+		},
+		{
+			name: "PPN exclusive half-up per tarif",
+			input: Input{Lines: []Line{
+				{CartID: 1, SellerID: 1, Quantity: 1, UnitPrice: 100, Tax: Tax{BasisPoints: 1100}},
+				{CartID: 2, SellerID: 1, Quantity: 1, UnitPrice: 50, Tax: Tax{BasisPoints: 1100}},
+			}},
+			wantDiscounts: []int64{0, 0},
+			wantTax:       []int64{11, 6}, // 16.5 -> 17, dibagi 100:50
+			wantTotal:     167,
+		},
+		{
+			name: "PPN inclusive setelah diskon",
+			input: Input{
+				Lines:   []Line{{CartID: 1, SellerID: 1, Quantity: 1, UnitPrice: 122100, Tax: Tax{BasisPoints: 1100, Inclusive: true}}},
+				Voucher: &Voucher{Code: "POTONG", Type: VoucherFixed, Amount: 11100},
+			},
+			wantDiscounts: []int64{11100},
+			wantTax:       []int64{11000}, // DPP 100.000 dari 111.000
+			wantTotal:     111000,
+		},
+		{
+			name: "gratis ongkir hanya seller eligible",
+			input: Input{
+				Lines: []Line{
+					{CartID: 1, SellerID: 1, Category: "Fashion", Quantity: 1, UnitPrice: 30000},
+					{CartID: 2, SellerID: 2, Category: "Elektronik", Quantity: 1, UnitPrice: 70000},
+				},
+				Shipping: map[uint]int64{1: 20000, 2: 15000},
+				Voucher:  &Voucher{Code: "ONGKIR", Type: VoucherFreeShipping, Amount: 25000, Category: "fashion"},
+			},
+			wantDiscounts: []int64{0, 0},
+			wantTax:       []int64{0, 0},
+			wantShipDisc:  20000,
+			wantTotal:     115000,
+		},
+		{
+			name: "harga flash sale untuk kuota yang dipegang",
+			input: Input{Lines: []Line{
+				{CartID: 1, SellerID: 1, Quantity: 3, UnitPrice: 50000, FlashSaleQuantity: 2, FlashSalePrice: 20000},
+			}},
+			wantDiscounts: []int64{0},
+			wantTax:       []int64{0},
+			wantTotal:     90000,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Calculate(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if rejected := IsVoucherRejected(err); rejected != (err != nil && tt.wantErr != ErrVoucherUnknownType) {
+				t.Errorf("IsVoucherRejected(%v) = %v", err, rejected)
+			}
+			for i, line := range result.Lines {
+				if line.Discount.Amount != tt.wantDiscounts[i] {
+					t.Errorf("line %d discount = %d, want %d", i, line.Discount.Amount, tt.wantDiscounts[i])
+				}
+				if line.Tax.Amount != tt.wantTax[i] {
+					t.Errorf("line %d tax = %d, want %d", i, line.Tax.Amount, tt.wantTax[i])
+				}
+			}
+			if result.ShippingDiscount.Amount != tt.wantShipDisc {
+				t.Errorf("shipping discount = %d, want %d", result.ShippingDiscount.Amount, tt.wantShipDisc)
+			}
+			if result.GrandTotal.Amount != tt.wantTotal {
+				t.Errorf("grand total = %d, want %d", result.GrandTotal.Amount, tt.wantTotal)
+			}
+
+			// Total per seller selalu sama dengan grand total
+			var sellers int64
+			for _, seller := range result.Sellers {
+				sellers += seller.Total.Amount
+			}
+			if sellers != result.GrandTotal.Amount {
+				t.Errorf("sum of seller totals = %d, grand total = %d", sellers, result.GrandTotal.Amount)
+			}
+		})
+	}
+}
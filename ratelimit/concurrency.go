@@ -0,0 +1,398 @@
+// ratelimit/concurrency.go
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Priority - kelas prioritas request untuk load shedding. Saat server penuh, kelas rendah
+// ditolak lebih dulu dan kelas tinggi boleh memakai bagian limit yang lebih besar.
+type Priority int
+
+const (
+	PriorityRecommendations Priority = iota
+	PriorityBrowse
+	PriorityCart
+	PriorityCheckout
+	priorityCount
+)
+
+var priorityNames = [priorityCount]string{"recommendations", "browse", "cart", "checkout"}
+
+func (p Priority) String() string {
+	if p < 0 || p >= priorityCount {
+		return fmt.Sprintf("priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+// ParsePriority - nama kelas dari file policy
+func ParsePriority(name string) (Priority, error) {
+	for i, priorityName := range priorityNames {
+		if strings.TrimSpace(name) == priorityName {
+			return Priority(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown priority %q (checkout, cart, browse, recommendations)", name)
+}
+
+// ShedError - request ditolak karena server penuh
+type ShedError struct {
+	Priority   Priority
+	RetryAfter time.Duration
+}
+
+func (e *ShedError) Error() string {
+	return fmt.Sprintf("request shed (%s): server overloaded", e.Priority)
+}
+
+// ErrShed - untuk errors.Is terhadap *ShedError
+var ErrShed = errors.New("request shed")
+
+func (e *ShedError) Is(target error) bool { return target == ErrShed }
+
+// ConcurrencyConfig - konfigurasi ConcurrencyLimiter, nilai nol memakai default
+type ConcurrencyConfig struct {
+	InitialLimit int // default 100
+	MinLimit     int // default 10
+	MaxLimit     int // default 1000
+	MaxQueue     int // total request yang boleh menunggu, default 2x InitialLimit
+	// QueueTimeout - lama maksimal menunggu slot per kelas; 0 = langsung ditolak jika penuh
+	QueueTimeout [priorityCount]time.Duration
+	// Share - bagian limit yang boleh dipakai kelas tersebut (0-1), sisanya cadangan kelas di atasnya
+	Share [priorityCount]float64
+	// TargetLatency - latency yang dianggap overload. 0 = adaptif: Tolerance x baseline latency
+	TargetLatency time.Duration
+	Tolerance     float64 // default 2
+	Backoff       float64 // pengali limit saat overload (multiplicative decrease), default 0.9
+}
+
+// DefaultConcurrencyConfig - checkout menunggu paling lama dan memakai seluruh limit,
+// rekomendasi tidak pernah antri dan hanya boleh memakai separuh limit
+func DefaultConcurrencyConfig() ConcurrencyConfig {
+	return ConcurrencyConfig{
+		InitialLimit: 100,
+		MinLimit:     10,
+		MaxLimit:     1000,
+		QueueTimeout: [priorityCount]time.Duration{
+			PriorityRecommendations: 0,
+			PriorityBrowse:          500 * time.Millisecond,
+			PriorityCart:            time.Second,
+			PriorityCheckout:        3 * time.Second,
+		},
+		Share: [priorityCount]float64{
+			PriorityRecommendations: 0.5,
+			PriorityBrowse:          0.75,
+			PriorityCart:            0.9,
+			PriorityCheckout:        1,
+		},
+		Tolerance: 2,
+		Backoff:   0.9,
+	}
+}
+
+func (c *ConcurrencyConfig) applyDefaults() {
+	defaults := DefaultConcurrencyConfig()
+	if c.InitialLimit <= 0 {
+		c.InitialLimit = defaults.InitialLimit
+	}
+	if c.MinLimit <= 0 {
+		c.MinLimit = defaults.MinLimit
+	}
+	if c.MaxLimit <= 0 {
+		c.MaxLimit = defaults.MaxLimit
+	}
+	if c.MinLimit > c.MaxLimit {
+		c.MinLimit = c.MaxLimit
+	}
+	c.InitialLimit = min(max(c.InitialLimit, c.MinLimit), c.MaxLimit)
+	if c.MaxQueue <= 0 {
+		c.MaxQueue = 2 * c.InitialLimit
+	}
+	for p := range c.Share {
+		if c.Share[p] <= 0 || c.Share[p] > 1 {
+			c.Share[p] = defaults.Share[p]
+		}
+	}
+	if c.Tolerance <= 1 {
+		c.Tolerance = defaults.Tolerance
+	}
+	if c.Backoff <= 0 || c.Backoff >= 1 {
+		c.Backoff = defaults.Backoff
+	}
+}
+
+// Bobot EWMA latency: jangka pendek mengikuti kondisi sekarang, baseline berubah pelan
+const (
+	shortLatencyWeight = 0.2
+	baselineWeight     = 0.005
+)
+
+type concurrencyWaiter struct {
+	priority Priority
+	ready    chan struct{}
+	admitted bool // diubah di bawah mu
+	shed     bool
+}
+
+// ConcurrencyLimiter - batas jumlah request yang sedang diproses (global, per proses) dengan
+// limit adaptif (AIMD): limit naik 1/limit setiap request yang selesai di bawah target latency
+// saat limit sedang terpakai, dan dikali Backoff (paling sering sekali per latency) saat
+// latency melewati target. Request yang tidak dapat slot menunggu di antrian per kelas.
+type ConcurrencyLimiter struct {
+	config ConcurrencyConfig
+	clock  Clock
+
+	mu           sync.Mutex
+	limit        float64
+	inFlight     int
+	queues       [priorityCount][]*concurrencyWaiter
+	queued       int
+	shortLatency float64 // ns
+	baseline     float64 // ns
+	lastDecrease time.Time
+	admitted     [priorityCount]uint64
+	shedCount    [priorityCount]uint64
+}
+
+func NewConcurrencyLimiter(config ConcurrencyConfig, clock Clock) *ConcurrencyLimiter {
+	config.applyDefaults()
+	if clock == nil {
+		clock = SystemClock{}
+	}
+	return &ConcurrencyLimiter{config: config, clock: clock, limit: float64(config.InitialLimit)}
+}
+
+// ConcurrencyTicket - slot yang didapat Acquire, wajib di-Release setelah request selesai
+type ConcurrencyTicket struct {
+	limiter   *ConcurrencyLimiter
+	priority  Priority
+	startedAt time.Time
+	once      sync.Once
+}
+
+// capacity - jumlah slot yang boleh dipakai kelas p (minimal 1 supaya kelas apapun tetap jalan)
+func (l *ConcurrencyLimiter) capacity(p Priority) int {
+	return max(1, int(math.Floor(l.limit*l.config.Share[p])))
+}
+
+// waitingAtOrAbove - ada request kelas >= p yang sudah antri lebih dulu
+func (l *ConcurrencyLimiter) waitingAtOrAbove(p Priority) bool {
+	for q := p; q < priorityCount; q++ {
+		if len(l.queues[q]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Acquire - mengambil slot untuk request kelas p, menunggu paling lama QueueTimeout kelas
+// tersebut (atau sampai ctx selesai). Error *ShedError jika request harus ditolak.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context, p Priority) (*ConcurrencyTicket, error) {
+	if p < 0 || p >= priorityCount {
+		p = PriorityBrowse
+	}
+
+	l.mu.Lock()
+	if l.inFlight < l.capacity(p) && !l.waitingAtOrAbove(p) {
+		ticket := l.admitLocked(p)
+		l.mu.Unlock()
+		return ticket, nil
+	}
+
+	timeout := l.config.QueueTimeout[p]
+	if timeout <= 0 || (l.queued >= l.config.MaxQueue && !l.evictLowerLocked(p)) {
+		err := l.shedLocked(p)
+		l.mu.Unlock()
+		return nil, err
+	}
+	waiter := &concurrencyWaiter{priority: p, ready: make(chan struct{})}
+	l.queues[p] = append(l.queues[p], waiter)
+	l.queued++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-waiter.ready:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if waiter.admitted {
+		return &ConcurrencyTicket{limiter: l, priority: p, startedAt: l.clock.Now()}, nil
+	}
+	if !waiter.shed {
+		l.removeWaiterLocked(waiter)
+	}
+	return nil, l.shedLocked(p)
+}
+
+func (l *ConcurrencyLimiter) admitLocked(p Priority) *ConcurrencyTicket {
+	l.inFlight++
+	l.admitted[p]++
+	return &ConcurrencyTicket{limiter: l, priority: p, startedAt: l.clock.Now()}
+}
+
+// evictLowerLocked - antrian penuh: keluarkan waiter terbaru dari kelas terendah yang lebih
+// rendah dari p supaya request yang lebih penting bisa antri
+func (l *ConcurrencyLimiter) evictLowerLocked(p Priority) bool {
+	for q := Priority(0); q < p; q++ {
+		if n := len(l.queues[q]); n > 0 {
+			victim := l.queues[q][n-1]
+			l.queues[q] = l.queues[q][:n-1]
+			l.queued--
+			victim.shed = true
+			close(victim.ready)
+			return true
+		}
+	}
+	return false
+}
+
+func (l *ConcurrencyLimiter) removeWaiterLocked(waiter *concurrencyWaiter) {
+	queue := l.queues[waiter.priority]
+	for i, w := range queue {
+		if w == waiter {
+			l.queues[waiter.priority] = append(queue[:i], queue[i+1:]...)
+			l.queued--
+			return
+		}
+	}
+}
+
+// shedLocked - mencatat penolakan dan memperkirakan kapan slot tersedia: antrian saat ini
+// dibagi limit, dikali latency rata-rata, minimal 1 detik dan maksimal 30 detik
+func (l *ConcurrencyLimiter) shedLocked(p Priority) error {
+	l.shedCount[p]++
+	latency := time.Duration(l.shortLatency)
+	if latency <= 0 {
+		latency = time.Second
+	}
+	retryAfter := time.Duration(float64(latency) * float64(l.queued+1) / l.limit)
+	retryAfter = min(max(retryAfter, time.Second), 30*time.Second)
+	return &ShedError{Priority: p, RetryAfter: retryAfter}
+}
+
+// Release - mengembalikan slot dan memakai durasi request sebagai sampel latency.
+// Aman dipanggil lebih dari sekali.
+func (t *ConcurrencyTicket) Release() {
+	t.once.Do(func() {
+		l := t.limiter
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.inFlight--
+		l.observeLocked(l.clock.Now().Sub(t.startedAt))
+		l.dispatchLocked()
+	})
+}
+
+func (l *ConcurrencyLimiter) observeLocked(latency time.Duration) {
+	sample := float64(max(latency, 0))
+	if l.baseline == 0 {
+		l.shortLatency, l.baseline = sample, sample
+	}
+	l.shortLatency += shortLatencyWeight * (sample - l.shortLatency)
+	l.baseline += baselineWeight * (sample - l.baseline)
+
+	target := float64(l.config.TargetLatency)
+	if target <= 0 {
+		target = l.baseline * l.config.Tolerance
+	}
+
+	now := l.clock.Now()
+	if l.shortLatency > target {
+		// Multiplicative decrease, paling sering sekali per latency supaya satu lonjakan
+		// tidak menurunkan limit berkali-kali oleh request yang dimulai bersamaan
+		if now.Sub(l.lastDecrease) >= time.Duration(l.shortLatency) {
+			l.limit = max(float64(l.config.MinLimit), l.limit*l.config.Backoff)
+			l.lastDecrease = now
+		}
+		return
+	}
+	// Additive increase hanya jika limit benar-benar terpakai, supaya tidak naik terus saat sepi
+	if float64(l.inFlight+1) >= l.limit/2 {
+		l.limit = min(float64(l.config.MaxLimit), l.limit+1/l.limit)
+	}
+}
+
+// dispatchLocked - memberi slot ke waiter, kelas tertinggi dulu, FIFO dalam satu kelas
+func (l *ConcurrencyLimiter) dispatchLocked() {
+	for p := priorityCount - 1; p >= 0; p-- {
+		for len(l.queues[p]) > 0 && l.inFlight < l.capacity(p) {
+			waiter := l.queues[p][0]
+			l.queues[p] = l.queues[p][1:]
+			l.queued--
+			l.inFlight++
+			l.admitted[p]++
+			waiter.admitted = true
+			close(waiter.ready)
+		}
+	}
+}
+
+// ConcurrencyStats - kondisi limiter untuk endpoint admin
+type ConcurrencyStats struct {
+	Limit        int
+	InFlight     int
+	Latency      time.Duration
+	Baseline     time.Duration
+	Queued       map[string]int
+	Admitted     map[string]uint64
+	Shed         map[string]uint64
+	ClassLimits  map[string]int
+	QueueTimeout map[string]time.Duration
+}
+
+func (l *ConcurrencyLimiter) Stats() ConcurrencyStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := ConcurrencyStats{
+		Limit:        int(l.limit),
+		InFlight:     l.inFlight,
+		Latency:      time.Duration(l.shortLatency),
+		Baseline:     time.Duration(l.baseline),
+		Queued:       make(map[string]int),
+		Admitted:     make(map[string]uint64),
+		Shed:         make(map[string]uint64),
+		ClassLimits:  make(map[string]int),
+		QueueTimeout: make(map[string]time.Duration),
+	}
+	for p := Priority(0); p < priorityCount; p++ {
+		name := p.String()
+		stats.Queued[name] = len(l.queues[p])
+		stats.Admitted[name] = l.admitted[p]
+		stats.Shed[name] = l.shedCount[p]
+		stats.ClassLimits[name] = l.capacity(p)
+		stats.QueueTimeout[name] = l.config.QueueTimeout[p]
+	}
+	return stats
+}
+
+var activeConcurrency struct {
+	sync.RWMutex
+	limiter *ConcurrencyLimiter
+}
+
+// SetActiveConcurrencyLimiter - limiter yang dipakai middleware load shedding dan endpoint admin
+func SetActiveConcurrencyLimiter(limiter *ConcurrencyLimiter) {
+	activeConcurrency.Lock()
+	defer activeConcurrency.Unlock()
+	activeConcurrency.limiter = limiter
+}
+
+// ActiveConcurrencyLimiter - nil jika load shedding tidak aktif
+func ActiveConcurrencyLimiter() *ConcurrencyLimiter {
+	activeConcurrency.RLock()
+	defer activeConcurrency.RUnlock()
+	return activeConcurrency.limiter
+}
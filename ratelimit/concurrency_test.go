@@ -0,0 +1,200 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestConcurrencyLimiter - limit 10 dengan share bawaan: recommendations 5, browse 7, cart 9, checkout 10
+func newTestConcurrencyLimiter(t *testing.T, config ConcurrencyConfig) (*ConcurrencyLimiter, *FakeClock) {
+	t.Helper()
+	clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+	if config.InitialLimit == 0 {
+		config.InitialLimit = 10
+	}
+	if config.MinLimit == 0 {
+		config.MinLimit = 2
+	}
+	if config.MaxLimit == 0 {
+		config.MaxLimit = 20
+	}
+	if config.TargetLatency == 0 {
+		config.TargetLatency = 100 * time.Millisecond
+	}
+	return NewConcurrencyLimiter(config, clock), clock
+}
+
+func mustAcquire(t *testing.T, limiter *ConcurrencyLimiter, p Priority, n int) []*ConcurrencyTicket {
+	t.Helper()
+	tickets := make([]*ConcurrencyTicket, n)
+	for i := range tickets {
+		ticket, err := limiter.Acquire(context.Background(), p)
+		if err != nil {
+			t.Fatalf("acquire %s #%d: %v", p, i+1, err)
+		}
+		tickets[i] = ticket
+	}
+	return tickets
+}
+
+// acquireAsync - Acquire di goroutine, hasilnya dikirim ke channel
+func acquireAsync(limiter *ConcurrencyLimiter, p Priority) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		ticket, err := limiter.Acquire(context.Background(), p)
+		if err == nil {
+			ticket.Release()
+		}
+		done <- err
+	}()
+	return done
+}
+
+// waitQueued - menunggu sampai jumlah waiter kelas p di antrian sama dengan n
+func waitQueued(t *testing.T, limiter *ConcurrencyLimiter, p Priority, n int) {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if limiter.Stats().Queued[p.String()] == n {
+			return
+		}
+	}
+	t.Fatalf("queued %s = %d, want %d", p, limiter.Stats().Queued[p.String()], n)
+}
+
+func receive(t *testing.T, done <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(2 * time.Second):
+		t.Fatal("acquire did not return")
+		return nil
+	}
+}
+
+// Kelas rendah yang sudah memakai bagiannya ditolak, kelas tinggi tetap dapat slot cadangan
+func TestConcurrencyShedsLowerClass(t *testing.T) {
+	limiter, _ := newTestConcurrencyLimiter(t, ConcurrencyConfig{})
+
+	mustAcquire(t, limiter, PriorityRecommendations, 5)
+	_, err := limiter.Acquire(context.Background(), PriorityRecommendations)
+	var shed *ShedError
+	if !errors.As(err, &shed) || shed.Priority != PriorityRecommendations || !errors.Is(err, ErrShed) {
+		t.Fatalf("6th recommendations acquire = %v, want ShedError", err)
+	}
+
+	mustAcquire(t, limiter, PriorityBrowse, 2)
+	mustAcquire(t, limiter, PriorityCart, 2)
+	mustAcquire(t, limiter, PriorityCheckout, 1)
+
+	stats := limiter.Stats()
+	if stats.InFlight != 10 || stats.Shed["recommendations"] != 1 || stats.Admitted["checkout"] != 1 {
+		t.Errorf("stats = in flight %d, shed %v, admitted %v; want 10, 1 recommendations, 1 checkout",
+			stats.InFlight, stats.Shed, stats.Admitted)
+	}
+}
+
+// Antrian penuh: waiter kelas rendah dikeluarkan supaya request checkout bisa antri,
+// lalu checkout mendapat slot pertama yang dilepas
+func TestConcurrencyEvictsLowerWaiter(t *testing.T) {
+	limiter, _ := newTestConcurrencyLimiter(t, ConcurrencyConfig{
+		MaxQueue:     1,
+		QueueTimeout: [priorityCount]time.Duration{PriorityBrowse: time.Minute, PriorityCheckout: time.Minute},
+	})
+	tickets := mustAcquire(t, limiter, PriorityCheckout, 10)
+
+	browse := acquireAsync(limiter, PriorityBrowse)
+	waitQueued(t, limiter, PriorityBrowse, 1)
+
+	checkout := acquireAsync(limiter, PriorityCheckout)
+	var shed *ShedError
+	if err := receive(t, browse); !errors.As(err, &shed) || shed.Priority != PriorityBrowse {
+		t.Fatalf("browse waiter = %v, want ShedError", err)
+	}
+	waitQueued(t, limiter, PriorityCheckout, 1)
+
+	tickets[0].Release()
+	if err := receive(t, checkout); err != nil {
+		t.Fatalf("checkout waiter = %v, want admitted", err)
+	}
+	if stats := limiter.Stats(); stats.Admitted["checkout"] != 11 || stats.Shed["browse"] != 1 {
+		t.Errorf("admitted %v, shed %v; want 11 checkout, 1 browse", stats.Admitted, stats.Shed)
+	}
+}
+
+// Waiter yang tidak dapat slot sampai QueueTimeout ditolak dengan RetryAfter dari latency
+// rata-rata dibagi limit (4 detik / 2)
+func TestConcurrencyQueueTimeout(t *testing.T) {
+	limiter, clock := newTestConcurrencyLimiter(t, ConcurrencyConfig{
+		InitialLimit:  2,
+		MinLimit:      1,
+		MaxLimit:      2,
+		TargetLatency: 10 * time.Second,
+		QueueTimeout:  [priorityCount]time.Duration{PriorityCheckout: 20 * time.Millisecond},
+	})
+	ticket := mustAcquire(t, limiter, PriorityCheckout, 1)[0]
+	clock.Advance(4 * time.Second)
+	ticket.Release()
+
+	mustAcquire(t, limiter, PriorityCheckout, 2)
+	start := time.Now()
+	_, err := limiter.Acquire(context.Background(), PriorityCheckout)
+	var shed *ShedError
+	if !errors.As(err, &shed) {
+		t.Fatalf("acquire = %v, want ShedError", err)
+	}
+	if waited := time.Since(start); waited < 20*time.Millisecond {
+		t.Errorf("shed after %v, want after queue timeout", waited)
+	}
+	if shed.RetryAfter != 2*time.Second {
+		t.Errorf("retry after = %v, want 2s", shed.RetryAfter)
+	}
+	if queued := limiter.Stats().Queued["checkout"]; queued != 0 {
+		t.Errorf("queued = %d, want 0 after timeout", queued)
+	}
+}
+
+// Latency di atas target menurunkan limit (sekali per latency), di bawah target limit naik
+// lagi selama limit terpakai
+func TestConcurrencyAdaptiveLimit(t *testing.T) {
+	limiter, clock := newTestConcurrencyLimiter(t, ConcurrencyConfig{Backoff: 0.5})
+
+	// Dua request lambat yang selesai bersamaan hanya menurunkan limit sekali
+	slow := mustAcquire(t, limiter, PriorityCheckout, 2)
+	clock.Advance(500 * time.Millisecond)
+	slow[0].Release()
+	slow[1].Release()
+	if limit := limiter.Stats().Limit; limit != 5 {
+		t.Fatalf("limit after slow requests = %d, want 5", limit)
+	}
+
+	// Tanpa beban limit tidak naik walaupun latency sudah normal
+	for i := 0; i < 20; i++ {
+		ticket := mustAcquire(t, limiter, PriorityCheckout, 1)[0]
+		clock.Advance(10 * time.Millisecond)
+		ticket.Release()
+	}
+	if limit := limiter.Stats().Limit; limit != 5 {
+		t.Fatalf("limit while idle = %d, want 5", limit)
+	}
+
+	// Dengan tiga request lain berjalan limit naik kembali
+	busy := mustAcquire(t, limiter, PriorityCheckout, 3)
+	for i := 0; i < 40; i++ {
+		ticket := mustAcquire(t, limiter, PriorityCheckout, 1)[0]
+		clock.Advance(10 * time.Millisecond)
+		ticket.Release()
+	}
+	stats := limiter.Stats()
+	if stats.Limit <= 5 || stats.Limit > 20 {
+		t.Errorf("limit after recovery = %d, want above 5 and at most 20", stats.Limit)
+	}
+	if stats.Latency > 100*time.Millisecond {
+		t.Errorf("latency = %v, want below target", stats.Latency)
+	}
+	if stats.InFlight != len(busy) {
+		t.Errorf("in flight = %d, want %d", stats.InFlight, len(busy))
+	}
+}
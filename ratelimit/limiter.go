@@ -0,0 +1,198 @@
+// ratelimit/limiter.go
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Algoritma yang didukung Limiter
+type Algorithm string
+
+const (
+	// AlgorithmTokenBucket - Burst request sekaligus, lalu diisi ulang Limit per Period
+	AlgorithmTokenBucket Algorithm = "token_bucket"
+	// AlgorithmSlidingWindow - maksimal Limit request dalam Period terakhir (log per request)
+	AlgorithmSlidingWindow Algorithm = "sliding_window"
+	// AlgorithmGCRA - generic cell rate algorithm: perilaku seperti token bucket tetapi
+	// state hanya satu timestamp, request tersebar rata dengan toleransi Burst
+	AlgorithmGCRA Algorithm = "gcra"
+)
+
+// ParseAlgorithm - nama algoritma dari konfigurasi, kosong = token bucket
+func ParseAlgorithm(name string) (Algorithm, error) {
+	switch Algorithm(strings.TrimSpace(name)) {
+	case "", AlgorithmTokenBucket:
+		return AlgorithmTokenBucket, nil
+	case AlgorithmSlidingWindow:
+		return AlgorithmSlidingWindow, nil
+	case AlgorithmGCRA:
+		return AlgorithmGCRA, nil
+	}
+	return "", fmt.Errorf("unknown rate limit algorithm %q (token_bucket, sliding_window, gcra)", name)
+}
+
+// Clock - sumber waktu Limiter, diganti FakeClock di pengecekan supaya deterministik
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock - jam sistem
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// FakeClock - jam yang hanya maju jika digerakkan
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance - memajukan (atau memundurkan jika d negatif) jam
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Policy - aturan satu limiter. Name dipakai sebagai namespace key di store dan
+// sebagai nama policy di header RateLimit-Policy.
+type Policy struct {
+	Name      string
+	Algorithm Algorithm
+	Rate      Rate
+}
+
+// Limiter - memutuskan request per key memakai Store dan Clock
+type Limiter struct {
+	policy  Policy
+	store   Store
+	clock   Clock
+	metrics *Metrics
+}
+
+func NewLimiter(policy Policy, store Store, clock Clock) (*Limiter, error) {
+	if err := policy.Rate.validate(); err != nil {
+		return nil, err
+	}
+	algorithm, err := ParseAlgorithm(string(policy.Algorithm))
+	if err != nil {
+		return nil, err
+	}
+	policy.Algorithm = algorithm
+	if clock == nil {
+		clock = SystemClock{}
+	}
+	return &Limiter{policy: policy, store: store, clock: clock, metrics: DefaultMetrics}, nil
+}
+
+// WithMetrics - Limiter yang mencatat keputusannya ke metrics lain (nil = tidak dicatat)
+func (l *Limiter) WithMetrics(metrics *Metrics) *Limiter {
+	copied := *l
+	copied.metrics = metrics
+	return &copied
+}
+
+func (l *Limiter) Policy() Policy { return l.policy }
+
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	storeKey := l.storeKey(key)
+	now := l.clock.Now()
+
+	var result Result
+	var err error
+	switch l.policy.Algorithm {
+	case AlgorithmSlidingWindow:
+		result, err = l.store.SlidingWindow(ctx, storeKey, l.policy.Rate, now)
+	case AlgorithmGCRA:
+		result, err = l.store.GCRA(ctx, storeKey, l.policy.Rate, now)
+	default:
+		result, err = l.store.TokenBucket(ctx, storeKey, l.policy.Rate, now)
+	}
+	if l.metrics != nil {
+		l.metrics.Record(l.policy.Name, key, result, err, now)
+	}
+	return result, err
+}
+
+// Peek - state key saat ini tanpa memakai kuota
+func (l *Limiter) Peek(ctx context.Context, key string) (Result, error) {
+	return l.store.Peek(ctx, l.policy.Algorithm, l.storeKey(key), l.policy.Rate, l.clock.Now())
+}
+
+// Reset - mengembalikan kuota key menjadi penuh
+func (l *Limiter) Reset(ctx context.Context, key string) error {
+	if err := l.store.Reset(ctx, l.storeKey(key)); err != nil {
+		return err
+	}
+	if l.metrics != nil {
+		l.metrics.ForgetKey(l.policy.Name, key)
+	}
+	return nil
+}
+
+// storeKey - algoritma ikut di key karena state tiap algoritma berbeda bentuk (di Redis: hash,
+// sorted set, string). Policy yang berganti algoritma (RateLimiter dengan beberapa middleware,
+// reload file policy) memakai state baru, bukan membaca state algoritma lain lalu gagal (WRONGTYPE).
+func (l *Limiter) storeKey(key string) string {
+	return l.policy.Name + ":" + string(l.policy.Algorithm) + ":" + key
+}
+
+// Headers - header respons untuk hasil keputusan:
+//   - RateLimit-Policy dan RateLimit (draft IETF httpapi-ratelimit-headers)
+//   - X-RateLimit-Limit/Remaining/Reset (epoch detik) yang sudah dipakai client lama
+//   - Retry-After jika ditolak
+func (l *Limiter) Headers(result Result) map[string]string {
+	now := l.clock.Now()
+	reset := result.ResetAfter
+	if !result.Allowed {
+		reset = result.RetryAfter
+	}
+
+	headers := map[string]string{
+		"RateLimit-Policy":      fmt.Sprintf("%q;q=%d;w=%d", l.policy.Name, l.policy.Rate.Limit, ceilSeconds(l.policy.Rate.Period)),
+		"RateLimit":             fmt.Sprintf("%q;r=%d;t=%d", l.policy.Name, result.Remaining, ceilSeconds(reset)),
+		"X-RateLimit-Limit":     strconv.Itoa(result.Limit),
+		"X-RateLimit-Remaining": strconv.Itoa(result.Remaining),
+		"X-RateLimit-Reset":     strconv.FormatInt(now.Add(reset).Unix(), 10),
+	}
+	if !result.Allowed {
+		headers["Retry-After"] = strconv.Itoa(RetryAfterSeconds(result.RetryAfter))
+	}
+	return headers
+}
+
+// RetryAfterSeconds - Retry-After dalam detik bulat ke atas, minimal 1
+func RetryAfterSeconds(d time.Duration) int {
+	if seconds := ceilSeconds(d); seconds > 1 {
+		return seconds
+	}
+	return 1
+}
+
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int((d + time.Second - 1) / time.Second)
+}
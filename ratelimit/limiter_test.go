@@ -0,0 +1,159 @@
+package ratelimit
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Setiap langkah memajukan FakeClock lalu memeriksa semua header yang dikirim ke client
+func TestLimiterHeaders(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	epoch := func(offset time.Duration) string {
+		return strconv.FormatInt(start.Add(offset).Unix(), 10)
+	}
+
+	type step struct {
+		advance time.Duration
+		want    map[string]string
+	}
+	// Token bucket dan GCRA dengan rate yang sama: 6/menit (1 token per 10 detik), burst 2
+	bucketSteps := []step{
+		{0, map[string]string{
+			"RateLimit": `"api";r=1;t=10`, "X-RateLimit-Remaining": "1", "X-RateLimit-Reset": epoch(10 * time.Second),
+		}},
+		{0, map[string]string{
+			"RateLimit": `"api";r=0;t=20`, "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": epoch(20 * time.Second),
+		}},
+		// t=4s: token berikutnya baru ada di t=10s
+		{4 * time.Second, map[string]string{
+			"RateLimit": `"api";r=0;t=6`, "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": epoch(10 * time.Second), "Retry-After": "6",
+		}},
+		// t=10s: tepat satu token, langsung dipakai
+		{6 * time.Second, map[string]string{
+			"RateLimit": `"api";r=0;t=20`, "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": epoch(30 * time.Second),
+		}},
+		// t=35s: refill 2.5 token dibatasi burst 2
+		{25 * time.Second, map[string]string{
+			"RateLimit": `"api";r=1;t=10`, "X-RateLimit-Remaining": "1", "X-RateLimit-Reset": epoch(45 * time.Second),
+		}},
+		// t=37.5s: 1.25 token, sisa 0.25 setelah dipakai; penuh lagi 17.5 detik lagi (dibulatkan ke atas)
+		{2500 * time.Millisecond, map[string]string{
+			"RateLimit": `"api";r=0;t=18`, "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": epoch(55 * time.Second),
+		}},
+		// Sisa 0.25 token tidak hilang: token penuh berikutnya 7.5 detik lagi
+		{0, map[string]string{
+			"RateLimit": `"api";r=0;t=8`, "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": epoch(45 * time.Second), "Retry-After": "8",
+		}},
+	}
+
+	tests := []struct {
+		algorithm Algorithm
+		rate      Rate
+		policy    string
+		steps     []step
+	}{
+		{AlgorithmTokenBucket, Rate{Limit: 6, Period: time.Minute, Burst: 2}, `"api";q=6;w=60`, bucketSteps},
+		{AlgorithmGCRA, Rate{Limit: 6, Period: time.Minute, Burst: 2}, `"api";q=6;w=60`, bucketSteps},
+		{AlgorithmSlidingWindow, Rate{Limit: 2, Period: 10 * time.Second}, `"api";q=2;w=10`, []step{
+			{0, map[string]string{
+				"RateLimit": `"api";r=1;t=10`, "X-RateLimit-Remaining": "1", "X-RateLimit-Reset": epoch(10 * time.Second),
+			}},
+			{4 * time.Second, map[string]string{
+				"RateLimit": `"api";r=0;t=10`, "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": epoch(14 * time.Second),
+			}},
+			// t=6s: request t=0 keluar dari window di t=10s
+			{2 * time.Second, map[string]string{
+				"RateLimit": `"api";r=0;t=4`, "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": epoch(10 * time.Second), "Retry-After": "4",
+			}},
+			{4 * time.Second, map[string]string{
+				"RateLimit": `"api";r=0;t=10`, "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": epoch(20 * time.Second),
+			}},
+			// t=10.5s: request berikutnya keluar di t=14s, Retry-After dibulatkan ke atas
+			{500 * time.Millisecond, map[string]string{
+				"RateLimit": `"api";r=0;t=4`, "X-RateLimit-Remaining": "0", "X-RateLimit-Reset": epoch(14 * time.Second), "Retry-After": "4",
+			}},
+			// t=30s: window kosong
+			{19500 * time.Millisecond, map[string]string{
+				"RateLimit": `"api";r=1;t=10`, "X-RateLimit-Remaining": "1", "X-RateLimit-Reset": epoch(40 * time.Second),
+			}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.algorithm), func(t *testing.T) {
+			clock := NewFakeClock(start)
+			limiter, err := NewLimiter(Policy{Name: "api", Algorithm: tt.algorithm, Rate: tt.rate}, NewMemoryStore(), clock)
+			if err != nil {
+				t.Fatal(err)
+			}
+			limiter = limiter.WithMetrics(nil)
+
+			for i, step := range tt.steps {
+				clock.Advance(step.advance)
+				result, err := limiter.Allow(context.Background(), "client")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want := map[string]string{"RateLimit-Policy": tt.policy, "X-RateLimit-Limit": strconv.Itoa(tt.rate.Limit)}
+				for name, value := range step.want {
+					want[name] = value
+				}
+				if got := limiter.Headers(result); !reflect.DeepEqual(got, want) {
+					t.Errorf("step %d (t=%s): headers\n got %v\nwant %v", i+1, clock.Now().Sub(start), got, want)
+				}
+				if _, rejected := want["Retry-After"]; result.Allowed == rejected {
+					t.Errorf("step %d: allowed = %v", i+1, result.Allowed)
+				}
+			}
+		})
+	}
+}
+
+// Policy dengan nama sama tetapi algoritma berbeda (beberapa middleware dari satu RateLimiter,
+// atau file policy di-reload dengan algoritma baru) tidak berbagi state
+func TestLimiterStoreKeyPerAlgorithm(t *testing.T) {
+	for _, backend := range testStores {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := backend.newStore(t)
+			clock := NewFakeClock(time.Unix(1_700_000_000, 0))
+			rate := Rate{Limit: 1, Period: time.Minute, Burst: 1}
+
+			limiters := make(map[Algorithm]*Limiter)
+			for _, algorithm := range []Algorithm{AlgorithmTokenBucket, AlgorithmSlidingWindow, AlgorithmGCRA} {
+				limiter, err := NewLimiter(Policy{Name: "api", Algorithm: algorithm, Rate: rate}, store, clock)
+				if err != nil {
+					t.Fatal(err)
+				}
+				limiters[algorithm] = limiter.WithMetrics(nil)
+			}
+
+			for algorithm, limiter := range limiters {
+				result, err := limiter.Allow(ctx, "client")
+				if err != nil {
+					t.Fatalf("%s: %v", algorithm, err)
+				}
+				if !result.Allowed {
+					t.Errorf("%s: first request rejected, state shared with another algorithm", algorithm)
+				}
+			}
+
+			// Reset satu algoritma tidak menyentuh state algoritma lain
+			if err := limiters[AlgorithmGCRA].Reset(ctx, "client"); err != nil {
+				t.Fatal(err)
+			}
+			for algorithm, limiter := range limiters {
+				result, err := limiter.Peek(ctx, "client")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := algorithm == AlgorithmGCRA; result.Allowed != want {
+					t.Errorf("%s after resetting gcra: allowed = %v, want %v", algorithm, result.Allowed, want)
+				}
+			}
+		})
+	}
+}
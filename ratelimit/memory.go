@@ -0,0 +1,206 @@
+// ratelimit/memory.go
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type bucketState struct {
+	units     int64 // saldo token dalam unit (Rate.tokenUnits)
+	updatedAt int64 // µs
+	expiresAt int64 // µs, sama dengan TTL key di RedisStore
+}
+
+type windowState struct {
+	requests  []int64 // waktu request yang diterima (µs), urut naik
+	expiresAt int64
+}
+
+type gcraState struct {
+	tat       int64 // theoretical arrival time (µs)
+	expiresAt int64
+}
+
+// MemoryStore - state di memori proses. Limit tidak dibagi antar replica dan hilang saat restart.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucketState
+	windows map[string]*windowState
+	gcra    map[string]*gcraState
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucketState),
+		windows: make(map[string]*windowState),
+		gcra:    make(map[string]*gcraState),
+	}
+}
+
+func (s *MemoryStore) TokenBucket(ctx context.Context, key string, rate Rate, now time.Time) (Result, error) {
+	return s.tokenBucket(key, rate, now, true)
+}
+
+func (s *MemoryStore) SlidingWindow(ctx context.Context, key string, rate Rate, now time.Time) (Result, error) {
+	return s.slidingWindow(key, rate, now, true)
+}
+
+func (s *MemoryStore) GCRA(ctx context.Context, key string, rate Rate, now time.Time) (Result, error) {
+	return s.gcraDecide(key, rate, now, true)
+}
+
+func (s *MemoryStore) Peek(ctx context.Context, algorithm Algorithm, key string, rate Rate, now time.Time) (Result, error) {
+	switch algorithm {
+	case AlgorithmSlidingWindow:
+		return s.slidingWindow(key, rate, now, false)
+	case AlgorithmGCRA:
+		return s.gcraDecide(key, rate, now, false)
+	default:
+		return s.tokenBucket(key, rate, now, false)
+	}
+}
+
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.buckets, key)
+	delete(s.windows, key)
+	delete(s.gcra, key)
+	return nil
+}
+
+// tokenBucket - consume=false hanya menghitung state saat ini (Peek), tanpa mengubah store
+func (s *MemoryStore) tokenBucket(key string, rate Rate, now time.Time, consume bool) (Result, error) {
+	if err := rate.validate(); err != nil {
+		return Result{}, err
+	}
+	nowUs := micros(now)
+	capacity := rate.burst() * rate.tokenUnits()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucket := bucketState{units: capacity, updatedAt: nowUs}
+	if stored, ok := s.buckets[key]; ok && nowUs < stored.expiresAt {
+		bucket = *stored
+	}
+	// Jam mundur (beda jam antar replica) tidak menambah token dan tidak memundurkan updatedAt
+	if nowUs > bucket.updatedAt {
+		bucket.units += refillUnits(nowUs-bucket.updatedAt, rate, capacity-bucket.units)
+		bucket.updatedAt = nowUs
+	}
+	// Policy diubah (burst/period mengecil) saat bucket masih penuh
+	if bucket.units > capacity {
+		bucket.units = capacity
+	}
+
+	allowed := bucket.units >= rate.tokenUnits()
+	if !consume {
+		return tokenBucketResult(allowed, bucket.units, rate), nil
+	}
+	if allowed {
+		bucket.units -= rate.tokenUnits()
+	}
+	result := tokenBucketResult(allowed, bucket.units, rate)
+	bucket.expiresAt = nowUs + ttlMillis(result.ResetAfter)*1000
+	s.buckets[key] = &bucket
+	return result, nil
+}
+
+func (s *MemoryStore) slidingWindow(key string, rate Rate, now time.Time, consume bool) (Result, error) {
+	if err := rate.validate(); err != nil {
+		return Result{}, err
+	}
+	nowUs := micros(now)
+	period := rate.Period.Microseconds()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	window, ok := s.windows[key]
+	if !ok || nowUs >= window.expiresAt {
+		window = &windowState{}
+		if consume {
+			s.windows[key] = window
+		}
+	}
+
+	// Buang request yang sudah keluar dari window (<= now - period)
+	valid := window.requests[:0]
+	for _, at := range window.requests {
+		if at > nowUs-period {
+			valid = append(valid, at)
+		}
+	}
+	window.requests = valid
+
+	allowed := len(window.requests) < rate.Limit
+	if allowed && consume {
+		// Urutan dijaga walaupun jam mundur, sama seperti sorted set di Redis
+		i := len(window.requests)
+		for i > 0 && window.requests[i-1] > nowUs {
+			i--
+		}
+		window.requests = append(window.requests, 0)
+		copy(window.requests[i+1:], window.requests[i:])
+		window.requests[i] = nowUs
+	}
+	if consume {
+		window.expiresAt = nowUs + period
+	}
+
+	var oldest, newest int64
+	if count := len(window.requests); count > 0 {
+		oldest, newest = window.requests[0], window.requests[count-1]
+	}
+	return slidingWindowResult(allowed, int64(len(window.requests)), oldest, newest, nowUs, rate), nil
+}
+
+func (s *MemoryStore) gcraDecide(key string, rate Rate, now time.Time, consume bool) (Result, error) {
+	if err := rate.validate(); err != nil {
+		return Result{}, err
+	}
+	nowUs := micros(now)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tat := nowUs
+	if state, ok := s.gcra[key]; ok && nowUs < state.expiresAt && state.tat > nowUs {
+		tat = state.tat
+	}
+	newTat := tat + rate.emission()
+	allowed := nowUs >= newTat-rate.emission()*rate.burst()
+	if !consume || !allowed {
+		return gcraResult(allowed, tat, nowUs, rate), nil
+	}
+	s.gcra[key] = &gcraState{tat: newTat, expiresAt: nowUs + ttlMillis(microDuration(newTat-nowUs))*1000}
+	return gcraResult(true, newTat, nowUs, rate), nil
+}
+
+// Cleanup - membuang key yang sudah kedaluwarsa
+func (s *MemoryStore) Cleanup(now time.Time) {
+	nowUs := micros(now)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, bucket := range s.buckets {
+		if nowUs >= bucket.expiresAt {
+			delete(s.buckets, key)
+		}
+	}
+	for key, window := range s.windows {
+		if nowUs >= window.expiresAt {
+			delete(s.windows, key)
+		}
+	}
+	for key, state := range s.gcra {
+		if nowUs >= state.expiresAt {
+			delete(s.gcra, key)
+		}
+	}
+}
+
+func (s *MemoryStore) Close() error { return nil }
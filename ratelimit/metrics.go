@@ -0,0 +1,231 @@
+// ratelimit/metrics.go
+package ratelimit
+
+import (
+	"container/heap"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Jumlah key per policy yang dilacak untuk daftar top limited. Jika penuh, key dengan
+// hitungan terkecil dibuang sehingga key yang terus-menerus kena limit tetap tercatat.
+const topKeysCapacity = 1000
+
+// Metrics - counter keputusan rate limit per policy, per proses (tiap replica punya sendiri).
+// Nama policy yang sama setelah reload tetap memakai counter yang sama. mu hanya menjaga map
+// policies; counter memakai atomic dan daftar key dijaga lock milik policy masing-masing.
+type Metrics struct {
+	mu          sync.RWMutex
+	policies    map[string]*policyCounters
+	allowlisted atomic.Uint64
+	denylisted  atomic.Uint64
+	since       time.Time
+}
+
+type policyCounters struct {
+	allowed atomic.Uint64
+	limited atomic.Uint64
+	errors  atomic.Uint64
+
+	mu   sync.Mutex
+	keys map[string]*limitedEntry
+	heap limitedHeap // min-heap berdasarkan Limited lalu LastLimited, akar = kandidat dibuang
+}
+
+type limitedEntry struct {
+	LimitedKey
+	index int
+}
+
+// limitedHeap - container/heap untuk key yang ditolak, key dengan hitungan terkecil
+// (seri: paling lama tidak ditolak) di akar
+type limitedHeap []*limitedEntry
+
+func (h limitedHeap) Len() int { return len(h) }
+
+func (h limitedHeap) Less(i, j int) bool {
+	if h[i].Limited != h[j].Limited {
+		return h[i].Limited < h[j].Limited
+	}
+	return h[i].LastLimited.Before(h[j].LastLimited)
+}
+
+func (h limitedHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *limitedHeap) Push(x any) {
+	entry := x.(*limitedEntry)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+
+func (h *limitedHeap) Pop() any {
+	old := *h
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return entry
+}
+
+// LimitedKey - key yang pernah ditolak
+type LimitedKey struct {
+	Key         string
+	Limited     uint64
+	LastLimited time.Time
+}
+
+// PolicyStats - ringkasan counter satu policy
+type PolicyStats struct {
+	Policy     string
+	Allowed    uint64
+	Limited    uint64
+	Errors     uint64
+	TopLimited []LimitedKey
+}
+
+// MetricsSnapshot - isi Metrics pada satu waktu
+type MetricsSnapshot struct {
+	Since       time.Time
+	Allowlisted uint64
+	Denylisted  uint64
+	Policies    []PolicyStats
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{policies: make(map[string]*policyCounters), since: time.Now()}
+}
+
+// DefaultMetrics - counter yang diisi semua Limiter
+var DefaultMetrics = NewMetrics()
+
+func (m *Metrics) counters(policy string) *policyCounters {
+	m.mu.RLock()
+	counters, ok := m.policies[policy]
+	m.mu.RUnlock()
+	if ok {
+		return counters
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if counters, ok = m.policies[policy]; !ok {
+		counters = &policyCounters{keys: make(map[string]*limitedEntry)}
+		m.policies[policy] = counters
+	}
+	return counters
+}
+
+// Record - mencatat satu keputusan Limiter
+func (m *Metrics) Record(policy, key string, result Result, err error, now time.Time) {
+	counters := m.counters(policy)
+	switch {
+	case err != nil:
+		counters.errors.Add(1)
+	case result.Allowed:
+		counters.allowed.Add(1)
+	default:
+		counters.limited.Add(1)
+		counters.recordLimited(key, now)
+	}
+}
+
+// recordLimited - menaikkan hitungan key, membuang key terkecil jika daftar sudah penuh
+func (c *policyCounters) recordLimited(key string, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.keys[key]
+	if !ok {
+		if len(c.keys) >= topKeysCapacity {
+			c.evictSmallest()
+		}
+		entry = &limitedEntry{LimitedKey: LimitedKey{Key: key, Limited: 1, LastLimited: now}}
+		c.keys[key] = entry
+		heap.Push(&c.heap, entry)
+		return
+	}
+	entry.Limited++
+	entry.LastLimited = now
+	heap.Fix(&c.heap, entry.index)
+}
+
+func (c *policyCounters) evictSmallest() {
+	if c.heap.Len() == 0 {
+		return
+	}
+	smallest := heap.Pop(&c.heap).(*limitedEntry)
+	delete(c.keys, smallest.Key)
+}
+
+// RecordAccess - request yang diputuskan allowlist/denylist sebelum policy dihitung
+func (m *Metrics) RecordAccess(access Access) {
+	switch access {
+	case AccessAllow:
+		m.allowlisted.Add(1)
+	case AccessDeny:
+		m.denylisted.Add(1)
+	}
+}
+
+// ForgetKey - menghapus key dari daftar top limited (setelah di-reset admin)
+func (m *Metrics) ForgetKey(policy, key string) {
+	m.mu.RLock()
+	counters, ok := m.policies[policy]
+	m.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	counters.mu.Lock()
+	defer counters.mu.Unlock()
+	if entry, ok := counters.keys[key]; ok {
+		heap.Remove(&counters.heap, entry.index)
+		delete(counters.keys, key)
+	}
+}
+
+// Snapshot - counter semua policy (urut nama) dengan top n key yang paling sering ditolak
+func (m *Metrics) Snapshot(top int) MetricsSnapshot {
+	m.mu.RLock()
+	policies := make(map[string]*policyCounters, len(m.policies))
+	for policy, counters := range m.policies {
+		policies[policy] = counters
+	}
+	m.mu.RUnlock()
+
+	snapshot := MetricsSnapshot{Since: m.since, Allowlisted: m.allowlisted.Load(), Denylisted: m.denylisted.Load()}
+	for policy, counters := range policies {
+		counters.mu.Lock()
+		keys := make([]LimitedKey, 0, len(counters.keys))
+		for _, entry := range counters.keys {
+			keys = append(keys, entry.LimitedKey)
+		}
+		counters.mu.Unlock()
+
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].Limited != keys[j].Limited {
+				return keys[i].Limited > keys[j].Limited
+			}
+			return keys[i].LastLimited.After(keys[j].LastLimited)
+		})
+		if len(keys) > top {
+			keys = keys[:top]
+		}
+		snapshot.Policies = append(snapshot.Policies, PolicyStats{
+			Policy:     policy,
+			Allowed:    counters.allowed.Load(),
+			Limited:    counters.limited.Load(),
+			Errors:     counters.errors.Load(),
+			TopLimited: keys,
+		})
+	}
+	sort.Slice(snapshot.Policies, func(i, j int) bool {
+		return snapshot.Policies[i].Policy < snapshot.Policies[j].Policy
+	})
+	return snapshot
+}
@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMetricsRecord(t *testing.T) {
+	metrics := NewMetrics()
+	now := time.Unix(1_700_000_000, 0)
+
+	metrics.Record("login", "ip:a", Result{Allowed: true}, nil, now)
+	metrics.Record("login", "ip:a", Result{}, nil, now)
+	metrics.Record("login", "ip:b", Result{}, nil, now)
+	metrics.Record("login", "ip:b", Result{}, nil, now.Add(time.Second))
+	metrics.Record("login", "ip:c", Result{}, errors.New("store down"), now)
+	metrics.Record("search", "ip:a", Result{Allowed: true}, nil, now)
+	metrics.RecordAccess(AccessAllow)
+	metrics.RecordAccess(AccessDeny)
+	metrics.RecordAccess(AccessDefault)
+
+	snapshot := metrics.Snapshot(10)
+	if snapshot.Allowlisted != 1 || snapshot.Denylisted != 1 {
+		t.Errorf("allowlisted/denylisted = %d/%d, want 1/1", snapshot.Allowlisted, snapshot.Denylisted)
+	}
+	if len(snapshot.Policies) != 2 || snapshot.Policies[0].Policy != "login" || snapshot.Policies[1].Policy != "search" {
+		t.Fatalf("policies = %+v, want login and search", snapshot.Policies)
+	}
+	login := snapshot.Policies[0]
+	if login.Allowed != 1 || login.Limited != 3 || login.Errors != 1 {
+		t.Errorf("login counters = %d/%d/%d, want 1/3/1", login.Allowed, login.Limited, login.Errors)
+	}
+	want := []LimitedKey{
+		{Key: "ip:b", Limited: 2, LastLimited: now.Add(time.Second)},
+		{Key: "ip:a", Limited: 1, LastLimited: now},
+	}
+	if len(login.TopLimited) != len(want) {
+		t.Fatalf("top limited = %+v, want %+v", login.TopLimited, want)
+	}
+	for i := range want {
+		if login.TopLimited[i] != want[i] {
+			t.Errorf("top limited[%d] = %+v, want %+v", i, login.TopLimited[i], want[i])
+		}
+	}
+
+	metrics.ForgetKey("login", "ip:b")
+	if top := metrics.Snapshot(10).Policies[0].TopLimited; len(top) != 1 || top[0].Key != "ip:a" {
+		t.Errorf("top limited after forget = %+v, want only ip:a", top)
+	}
+}
+
+// Daftar key penuh: key baru menggantikan key dengan hitungan terkecil, seri dibuang yang paling lama
+func TestMetricsEvictSmallest(t *testing.T) {
+	metrics := NewMetrics()
+	start := time.Unix(1_700_000_000, 0)
+
+	for i := 0; i < topKeysCapacity; i++ {
+		metrics.Record("login", fmt.Sprintf("ip:%d", i), Result{}, nil, start.Add(time.Duration(i)*time.Second))
+	}
+	// ip:0 paling lama tetapi sering ditolak, ip:1 menjadi kandidat pertama yang dibuang
+	for i := 0; i < 5; i++ {
+		metrics.Record("login", "ip:0", Result{}, nil, start)
+	}
+	// Key yang di-forget keluar dari heap dan tidak mengganggu urutan eviction
+	metrics.ForgetKey("login", "ip:2")
+
+	later := start.Add(time.Hour)
+	metrics.Record("login", "ip:new-1", Result{}, nil, later)
+	metrics.Record("login", "ip:new-2", Result{}, nil, later)
+
+	keys := make(map[string]LimitedKey)
+	for _, entry := range metrics.Snapshot(2 * topKeysCapacity).Policies[0].TopLimited {
+		keys[entry.Key] = entry
+	}
+	if len(keys) != topKeysCapacity {
+		t.Errorf("tracked keys = %d, want %d", len(keys), topKeysCapacity)
+	}
+	for _, key := range []string{"ip:0", "ip:3", "ip:new-1", "ip:new-2"} {
+		if _, ok := keys[key]; !ok {
+			t.Errorf("%s evicted, want kept", key)
+		}
+	}
+	for _, key := range []string{"ip:1", "ip:2"} {
+		if _, ok := keys[key]; ok {
+			t.Errorf("%s kept, want evicted", key)
+		}
+	}
+	if keys["ip:0"].Limited != 6 {
+		t.Errorf("ip:0 limited = %d, want 6", keys["ip:0"].Limited)
+	}
+}
+
+// Record dari banyak goroutine (jalankan dengan -race) tidak kehilangan hitungan
+func TestMetricsRecordConcurrent(t *testing.T) {
+	metrics := NewMetrics()
+	now := time.Unix(1_700_000_000, 0)
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			policy := fmt.Sprintf("policy-%d", g%2)
+			for i := 0; i < 500; i++ {
+				metrics.Record(policy, fmt.Sprintf("ip:%d", i%50), Result{Allowed: i%2 == 0}, nil, now)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	for _, stats := range metrics.Snapshot(100).Policies {
+		if stats.Allowed != 1000 || stats.Limited != 1000 {
+			t.Errorf("%s = %d allowed, %d limited; want 1000 and 1000", stats.Policy, stats.Allowed, stats.Limited)
+		}
+		var perKey uint64
+		for _, entry := range stats.TopLimited {
+			perKey += entry.Limited
+		}
+		if perKey != 1000 {
+			t.Errorf("%s per-key limited sum = %d, want 1000", stats.Policy, perKey)
+		}
+	}
+}
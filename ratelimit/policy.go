@@ -0,0 +1,410 @@
+// ratelimit/policy.go
+package ratelimit
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// Policy bawaan, sama dengan limit yang sebelumnya di-hardcode di routes.
+// Bisa diganti file lain lewat RATE_LIMIT_POLICY_FILE dengan format yang sama.
+//
+//go:embed data/policies.yaml
+var embeddedPolicies []byte
+
+// Tier principal yang dipakai untuk memilih policy
+const (
+	TierAnonymous = "anonymous"
+	TierUser      = "user"
+	TierSeller    = "seller"
+	TierAPIKey    = "api-key"
+)
+
+// Sumber key limiter
+const (
+	KeyByIP        = "ip"        // IP client
+	KeyByPrincipal = "principal" // user/seller/API key, IP untuk anonymous
+)
+
+var validTiers = map[string]bool{TierAnonymous: true, TierUser: true, TierSeller: true, TierAPIKey: true}
+
+// PolicyRule - satu aturan di file policy. Semua aturan yang cocok dengan request diterapkan
+// (seperti beberapa middleware limiter berurutan), request ditolak jika salah satunya menolak.
+type PolicyRule struct {
+	Name string `yaml:"name"`
+	// Routes - pola path route gin ("/product/:id"), boleh diawali method ("POST /user/login").
+	// "*" cocok dengan satu segmen, "**" di akhir cocok dengan sisa path (termasuk kosong).
+	Routes []string `yaml:"routes"`
+	// Methods - filter method untuk route tanpa method, kosong = semua method
+	Methods []string `yaml:"methods,omitempty"`
+	// Tiers - anonymous, user, seller, api-key. Kosong = semua tier
+	Tiers     []string      `yaml:"tiers,omitempty"`
+	Algorithm Algorithm     `yaml:"algorithm"`
+	Limit     int           `yaml:"limit"`
+	Period    time.Duration `yaml:"period"`
+	Burst     int           `yaml:"burst,omitempty"`
+	// Key - ip atau principal; PerRoute menambahkan route ke key (limit terpisah per endpoint)
+	Key      string `yaml:"key"`
+	PerRoute bool   `yaml:"per_route,omitempty"`
+
+	routes []routePattern
+}
+
+// PriorityRule - kelas prioritas load shedding untuk route. Aturan pertama yang cocok dipakai,
+// route yang tidak cocok dengan aturan manapun masuk kelas browse.
+type PriorityRule struct {
+	// Class - checkout, cart, browse atau recommendations
+	Class   string   `yaml:"class"`
+	Routes  []string `yaml:"routes"`
+	Methods []string `yaml:"methods,omitempty"`
+
+	priority Priority
+	routes   []routePattern
+}
+
+type routePattern struct {
+	method   string // kosong = ikut Methods
+	segments []string
+}
+
+// PolicyConfig - isi file policy
+type PolicyConfig struct {
+	// Allow - IP/CIDR yang tidak dikenai rate limit (monitoring internal, partner)
+	Allow []string `yaml:"allow,omitempty"`
+	// Deny - IP/CIDR yang selalu ditolak, didahulukan dari Allow
+	Deny     []string     `yaml:"deny,omitempty"`
+	Policies []PolicyRule `yaml:"policies"`
+	// Priorities - kelas prioritas untuk concurrency limiter (load shedding)
+	Priorities []PriorityRule `yaml:"priorities,omitempty"`
+
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// Access - keputusan allowlist/denylist untuk IP client
+type Access int
+
+const (
+	AccessDefault Access = iota // tidak ada di daftar, policy dihitung seperti biasa
+	AccessAllow
+	AccessDeny
+)
+
+// parsePrefixes - "10.0.0.0/8" atau IP tunggal ("203.0.113.7", "2001:db8::1")
+func parsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if strings.Contains(value, "/") {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q", value)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP %q", value)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultPolicyConfig - policy bawaan yang di-embed
+func DefaultPolicyConfig() (*PolicyConfig, error) {
+	return ParsePolicyConfig(embeddedPolicies)
+}
+
+// ParsePolicyConfig - membaca dan memvalidasi file policy (YAML)
+func ParsePolicyConfig(data []byte) (*PolicyConfig, error) {
+	var config PolicyConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("invalid policy file: %w", err)
+	}
+	if len(config.Policies) == 0 {
+		return nil, errors.New("invalid policy file: no policies")
+	}
+	var err error
+	if config.allow, err = parsePrefixes(config.Allow); err != nil {
+		return nil, fmt.Errorf("allow: %w", err)
+	}
+	if config.deny, err = parsePrefixes(config.Deny); err != nil {
+		return nil, fmt.Errorf("deny: %w", err)
+	}
+
+	names := make(map[string]bool)
+	for i := range config.Policies {
+		rule := &config.Policies[i]
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("policy %d (%s): %w", i+1, rule.Name, err)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("policy %d: duplicate name %q", i+1, rule.Name)
+		}
+		names[rule.Name] = true
+	}
+	for i := range config.Priorities {
+		rule := &config.Priorities[i]
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("priority %d (%s): %w", i+1, rule.Class, err)
+		}
+	}
+	return &config, nil
+}
+
+func (r *PriorityRule) compile() error {
+	priority, err := ParsePriority(r.Class)
+	if err != nil {
+		return err
+	}
+	r.priority = priority
+	for i, method := range r.Methods {
+		r.Methods[i] = strings.ToUpper(strings.TrimSpace(method))
+	}
+	r.routes, err = compileRoutes(r.Routes)
+	return err
+}
+
+func (r *PolicyRule) compile() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	algorithm, err := ParseAlgorithm(string(r.Algorithm))
+	if err != nil {
+		return err
+	}
+	r.Algorithm = algorithm
+	if err := r.Rate().validate(); err != nil {
+		return err
+	}
+	if r.Burst < 0 {
+		return errors.New("burst must not be negative")
+	}
+
+	switch r.Key {
+	case "":
+		r.Key = KeyByIP
+	case KeyByIP, KeyByPrincipal:
+	default:
+		return fmt.Errorf("unknown key %q (ip, principal)", r.Key)
+	}
+	for i, method := range r.Methods {
+		r.Methods[i] = strings.ToUpper(strings.TrimSpace(method))
+	}
+	for _, tier := range r.Tiers {
+		if !validTiers[tier] {
+			return fmt.Errorf("unknown tier %q (anonymous, user, seller, api-key)", tier)
+		}
+	}
+
+	routes, err := compileRoutes(r.Routes)
+	if err != nil {
+		return err
+	}
+	r.routes = routes
+	return nil
+}
+
+// compileRoutes - pola route dari file policy, boleh diawali method ("POST /user/login")
+func compileRoutes(routes []string) ([]routePattern, error) {
+	if len(routes) == 0 {
+		return nil, errors.New("at least one route is required")
+	}
+	patterns := make([]routePattern, 0, len(routes))
+	for _, route := range routes {
+		pattern := routePattern{}
+		path := strings.TrimSpace(route)
+		if method, rest, found := strings.Cut(path, " "); found {
+			pattern.method = strings.ToUpper(method)
+			path = strings.TrimSpace(rest)
+		}
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("route %q must start with /", route)
+		}
+		pattern.segments = splitPath(path)
+		for i, segment := range pattern.segments {
+			if segment == "**" && i != len(pattern.segments)-1 {
+				return nil, fmt.Errorf("route %q: ** is only allowed at the end", route)
+			}
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// Rate - Rate untuk Limiter aturan ini
+func (r PolicyRule) Rate() Rate {
+	return Rate{Limit: r.Limit, Period: r.Period, Burst: r.Burst}
+}
+
+// Matches - apakah aturan berlaku untuk method, route (pola gin atau path) dan tier
+func (r PolicyRule) Matches(method, route, tier string) bool {
+	if len(r.Tiers) > 0 && !containsString(r.Tiers, tier) {
+		return false
+	}
+	return matchRoutes(r.routes, r.Methods, method, route)
+}
+
+// matchRoutes - apakah salah satu pola cocok; methods berlaku untuk pola tanpa method
+func matchRoutes(patterns []routePattern, methods []string, method, route string) bool {
+	segments := splitPath(route)
+	for _, pattern := range patterns {
+		if pattern.method != "" {
+			if pattern.method != method {
+				continue
+			}
+		} else if len(methods) > 0 && !containsString(methods, method) {
+			continue
+		}
+		if matchSegments(pattern.segments, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, path []string) bool {
+	for i, segment := range pattern {
+		if segment == "**" {
+			return true
+		}
+		if i >= len(path) || (segment != "*" && segment != path[i]) {
+			return false
+		}
+	}
+	return len(pattern) == len(path)
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// PolicySet - PolicyConfig yang sudah punya Limiter per aturan. Namespace key di store adalah
+// nama aturan, jadi setelah reload aturan dengan nama yang sama tetap memakai counter lama.
+type PolicySet struct {
+	rules      []PolicyRule
+	limiters   []*Limiter
+	allow      []netip.Prefix
+	deny       []netip.Prefix
+	priorities []PriorityRule
+}
+
+func NewPolicySet(config *PolicyConfig, store Store, clock Clock) (*PolicySet, error) {
+	set := &PolicySet{allow: config.allow, deny: config.deny, priorities: config.Priorities}
+	for _, rule := range config.Policies {
+		limiter, err := NewLimiter(Policy{Name: rule.Name, Algorithm: rule.Algorithm, Rate: rule.Rate()}, store, clock)
+		if err != nil {
+			return nil, fmt.Errorf("policy %s: %w", rule.Name, err)
+		}
+		set.rules = append(set.rules, rule)
+		set.limiters = append(set.limiters, limiter)
+	}
+	return set, nil
+}
+
+// PolicyMatch - aturan yang berlaku untuk satu request beserta Limiter-nya
+type PolicyMatch struct {
+	Rule    PolicyRule
+	Limiter *Limiter
+}
+
+// Match - semua aturan yang berlaku, urut sesuai file
+func (s *PolicySet) Match(method, route, tier string) []PolicyMatch {
+	var matches []PolicyMatch
+	for i, rule := range s.rules {
+		if rule.Matches(method, route, tier) {
+			matches = append(matches, PolicyMatch{Rule: rule, Limiter: s.limiters[i]})
+		}
+	}
+	return matches
+}
+
+func (s *PolicySet) Rules() []PolicyRule {
+	return append([]PolicyRule(nil), s.rules...)
+}
+
+// Limiter - Limiter aturan dengan nama tersebut (untuk inspeksi admin)
+func (s *PolicySet) Limiter(name string) (*Limiter, bool) {
+	for i, rule := range s.rules {
+		if rule.Name == name {
+			return s.limiters[i], true
+		}
+	}
+	return nil, false
+}
+
+// Access - apakah IP client ada di denylist atau allowlist. IP yang tidak valid
+// diperlakukan seperti IP lain (policy tetap dihitung).
+func (s *PolicySet) Access(ip string) Access {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return AccessDefault
+	}
+	addr = addr.Unmap()
+	if containsAddr(s.deny, addr) {
+		return AccessDeny
+	}
+	if containsAddr(s.allow, addr) {
+		return AccessAllow
+	}
+	return AccessDefault
+}
+
+// AccessLists - allowlist dan denylist yang aktif
+func (s *PolicySet) AccessLists() (allow, deny []string) {
+	for _, prefix := range s.allow {
+		allow = append(allow, prefix.String())
+	}
+	for _, prefix := range s.deny {
+		deny = append(deny, prefix.String())
+	}
+	return allow, deny
+}
+
+// Priority - kelas load shedding untuk method dan route, default browse
+func (s *PolicySet) Priority(method, route string) Priority {
+	for _, rule := range s.priorities {
+		if matchRoutes(rule.routes, rule.Methods, method, route) {
+			return rule.priority
+		}
+	}
+	return PriorityBrowse
+}
+
+// PriorityRules - aturan kelas prioritas yang aktif
+func (s *PolicySet) PriorityRules() []PriorityRule {
+	return append([]PriorityRule(nil), s.priorities...)
+}
@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"strings"
+	"testing"
+)
+
+// Denylist didahulukan dari allowlist walaupun IP ada di keduanya
+func TestPolicySetAccess(t *testing.T) {
+	config, err := ParsePolicyConfig([]byte(`
+allow: [10.0.0.0/8, 192.0.2.10, "2001:db8::/32"]
+deny: [10.1.0.0/16, 203.0.113.7, "2001:db8:dead::/48"]
+policies:
+  - name: api
+    routes: [/**]
+    limit: 60
+    period: 1m
+    key: ip
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	policies, err := NewPolicySet(config, NewMemoryStore(), SystemClock{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ip   string
+		want Access
+	}{
+		{"10.2.3.4", AccessAllow},
+		{"10.1.2.3", AccessDeny}, // di 10.0.0.0/8 dan 10.1.0.0/16
+		{"192.0.2.10", AccessAllow},
+		{"192.0.2.11", AccessDefault},
+		{"203.0.113.7", AccessDeny},
+		{"::ffff:10.1.2.3", AccessDeny}, // IPv4-mapped dibandingkan sebagai IPv4
+		{"::ffff:10.2.3.4", AccessAllow},
+		{"2001:db8:1::1", AccessAllow},
+		{"2001:db8:dead::1", AccessDeny},
+		{"198.51.100.1", AccessDefault},
+		{"bukan-ip", AccessDefault},
+	}
+	for _, tt := range tests {
+		if got := policies.Access(tt.ip); got != tt.want {
+			t.Errorf("Access(%s) = %d, want %d", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestParsePrefixes(t *testing.T) {
+	prefixes, err := parsePrefixes([]string{" 10.0.0.1/8 ", "203.0.113.7", "2001:db8::1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"10.0.0.0/8", "203.0.113.7/32", "2001:db8::1/128"}
+	for i, prefix := range prefixes {
+		if prefix.String() != want[i] {
+			t.Errorf("prefix %d = %s, want %s", i, prefix, want[i])
+		}
+	}
+
+	invalid := []struct {
+		value   string
+		wantErr string
+	}{
+		{"10.0.0.0/33", `invalid CIDR "10.0.0.0/33"`},
+		{"10.0.0/8", `invalid CIDR "10.0.0/8"`},
+		{"10.0.0.0/", `invalid CIDR "10.0.0.0/"`},
+		{"256.0.0.1", `invalid IP "256.0.0.1"`},
+		{"localhost", `invalid IP "localhost"`},
+		{"", `invalid IP ""`},
+	}
+	for _, tt := range invalid {
+		if _, err := parsePrefixes([]string{"10.0.0.0/8", tt.value}); err == nil || err.Error() != tt.wantErr {
+			t.Errorf("parsePrefixes(%q) error = %v, want %s", tt.value, err, tt.wantErr)
+		}
+	}
+
+	// Error di file policy menyebut daftar mana yang salah
+	_, err = ParsePolicyConfig([]byte("deny: [10.0.0.0/40]\npolicies:\n  - name: api\n    routes: [/**]\n    limit: 1\n    period: 1m\n    key: ip\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "deny: ") {
+		t.Errorf("ParsePolicyConfig error = %v, want deny error", err)
+	}
+}
@@ -0,0 +1,310 @@
+// ratelimit/redis.go
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Token bucket: hash {tokens, ts}, tokens dalam unit (satu token = period_us unit) dan ts dalam µs.
+// Refill sama dengan refillUnits di Go; semua nilai bulat dan < 2^53 sehingga tepat di double Lua.
+// KEYS[1] = key, ARGV = limit, period_us, burst, now_us, consume (0 = Peek, state tidak diubah)
+const tokenBucketScript = `
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local capacity = tonumber(ARGV[3]) * period
+local now = tonumber(ARGV[4])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = capacity
+  ts = now
+end
+
+if now > ts then
+  local missing = capacity - tokens
+  if missing > 0 then
+    local elapsed = now - ts
+    if elapsed >= math.ceil(missing / limit) then
+      tokens = capacity
+    else
+      tokens = tokens + elapsed * limit
+    end
+  end
+  ts = now
+end
+if tokens > capacity then
+  tokens = capacity
+end
+
+local allowed = 0
+if tokens >= period then
+  allowed = 1
+end
+if ARGV[5] == '0' then
+  return {allowed, tokens}
+end
+if allowed == 1 then
+  tokens = tokens - period
+end
+
+redis.call('HSET', KEYS[1], 'tokens', string.format('%d', tokens), 'ts', string.format('%d', ts))
+redis.call('PEXPIRE', KEYS[1], math.ceil(math.ceil((capacity - tokens) / limit) / 1000) + 1000)
+return {allowed, tokens}
+`
+
+// Sliding window log: sorted set dengan score = waktu request (µs).
+// KEYS[1] = key, ARGV = limit, period_us, now_us, member unik, consume (0 = Peek)
+const slidingWindowScript = `
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - period)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+  allowed = 1
+end
+local consume = ARGV[5] ~= '0'
+if allowed == 1 and consume then
+  redis.call('ZADD', KEYS[1], now, ARGV[4])
+  count = count + 1
+end
+
+local oldest = 0
+local newest = 0
+if count > 0 then
+  oldest = tonumber(redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')[2])
+  newest = tonumber(redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')[2])
+end
+if consume then
+  redis.call('PEXPIRE', KEYS[1], math.ceil(period / 1000))
+end
+return {allowed, count, oldest, newest}
+`
+
+// GCRA: satu nilai theoretical arrival time (µs). Sama dengan MemoryStore.GCRA.
+// KEYS[1] = key, ARGV = emission_us, burst, now_us, consume (0 = Peek)
+const gcraScript = `
+local emission = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local tat = tonumber(redis.call('GET', KEYS[1]))
+if tat == nil or tat < now then
+  tat = now
+end
+local new_tat = tat + emission
+if now < new_tat - emission * burst then
+  return {0, tat}
+end
+if ARGV[4] == '0' then
+  return {1, tat}
+end
+redis.call('SET', KEYS[1], string.format('%d', new_tat), 'PX', math.ceil((new_tat - now) / 1000) + 1000)
+return {1, new_tat}
+`
+
+type redisScript struct {
+	source string
+	sha    string
+}
+
+func newRedisScript(source string) redisScript {
+	sum := sha1.Sum([]byte(source))
+	return redisScript{source: source, sha: hex.EncodeToString(sum[:])}
+}
+
+var (
+	tokenBucketLua   = newRedisScript(tokenBucketScript)
+	slidingWindowLua = newRedisScript(slidingWindowScript)
+	gcraLua          = newRedisScript(gcraScript)
+)
+
+// RedisStore - state di Redis supaya semua replica API berbagi limit yang sama dan limit
+// tidak reset saat restart. Setiap keputusan adalah satu script Lua (atomic di server).
+// Key kedaluwarsa sendiri lewat PEXPIRE, jadi tidak perlu Cleanup.
+type RedisStore struct {
+	config   RedisConfig
+	pool     *redisPool
+	memberID string // prefix member sorted set, unik per proses
+	sequence atomic.Uint64
+}
+
+func NewRedisStore(config RedisConfig) (*RedisStore, error) {
+	if config.Addr == "" {
+		return nil, errors.New("redis store requires an address")
+	}
+	if config.Prefix == "" {
+		config.Prefix = "ratelimit:"
+	}
+	if config.PoolSize <= 0 {
+		config.PoolSize = 10
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 200 * time.Millisecond
+	}
+
+	random := make([]byte, 6)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	store := &RedisStore{
+		config:   config,
+		pool:     newRedisPool(config),
+		memberID: hex.EncodeToString(random),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.DialTimeout+config.Timeout)
+	defer cancel()
+	if _, err := store.pool.do(ctx, "PING"); err != nil {
+		return nil, fmt.Errorf("redis ping failed: %w", err)
+	}
+	return store, nil
+}
+
+// eval - EVALSHA, lalu EVAL jika script belum ada di cache server (restart / failover)
+func (s *RedisStore) eval(ctx context.Context, script redisScript, key string, args ...string) ([]interface{}, error) {
+	ctx, cancel := withTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	command := append([]string{"EVALSHA", script.sha, "1", s.config.Prefix + key}, args...)
+	reply, err := s.pool.do(ctx, command...)
+	var redisErr RedisError
+	if errors.As(err, &redisErr) && strings.HasPrefix(string(redisErr), "NOSCRIPT") {
+		command[0], command[1] = "EVAL", script.source
+		reply, err = s.pool.do(ctx, command...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("redis: unexpected script reply %T", reply)
+	}
+	for _, value := range values {
+		if _, ok := value.(int64); !ok {
+			return nil, fmt.Errorf("redis: unexpected script reply %v", values)
+		}
+	}
+	return values, nil
+}
+
+func (s *RedisStore) TokenBucket(ctx context.Context, key string, rate Rate, now time.Time) (Result, error) {
+	return s.tokenBucket(ctx, key, rate, now, true)
+}
+
+func (s *RedisStore) SlidingWindow(ctx context.Context, key string, rate Rate, now time.Time) (Result, error) {
+	return s.slidingWindow(ctx, key, rate, now, true)
+}
+
+func (s *RedisStore) GCRA(ctx context.Context, key string, rate Rate, now time.Time) (Result, error) {
+	return s.gcraDecide(ctx, key, rate, now, true)
+}
+
+func (s *RedisStore) Peek(ctx context.Context, algorithm Algorithm, key string, rate Rate, now time.Time) (Result, error) {
+	switch algorithm {
+	case AlgorithmSlidingWindow:
+		return s.slidingWindow(ctx, key, rate, now, false)
+	case AlgorithmGCRA:
+		return s.gcraDecide(ctx, key, rate, now, false)
+	default:
+		return s.tokenBucket(ctx, key, rate, now, false)
+	}
+}
+
+func (s *RedisStore) Reset(ctx context.Context, key string) error {
+	ctx, cancel := withTimeout(ctx, s.config.Timeout)
+	defer cancel()
+	_, err := s.pool.do(ctx, "DEL", s.config.Prefix+key)
+	return err
+}
+
+func consumeArg(consume bool) string {
+	if consume {
+		return "1"
+	}
+	return "0"
+}
+
+func (s *RedisStore) tokenBucket(ctx context.Context, key string, rate Rate, now time.Time, consume bool) (Result, error) {
+	if err := rate.validate(); err != nil {
+		return Result{}, err
+	}
+	values, err := s.eval(ctx, tokenBucketLua, key,
+		strconv.Itoa(rate.Limit),
+		strconv.FormatInt(rate.Period.Microseconds(), 10),
+		strconv.FormatInt(rate.burst(), 10),
+		strconv.FormatInt(micros(now), 10),
+		consumeArg(consume),
+	)
+	if err != nil {
+		return Result{}, err
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("redis: unexpected token bucket reply %v", values)
+	}
+	return tokenBucketResult(values[0].(int64) == 1, values[1].(int64), rate), nil
+}
+
+func (s *RedisStore) slidingWindow(ctx context.Context, key string, rate Rate, now time.Time, consume bool) (Result, error) {
+	if err := rate.validate(); err != nil {
+		return Result{}, err
+	}
+	nowUs := micros(now)
+	member := fmt.Sprintf("%d-%s-%d", nowUs, s.memberID, s.sequence.Add(1))
+	values, err := s.eval(ctx, slidingWindowLua, key,
+		strconv.Itoa(rate.Limit),
+		strconv.FormatInt(rate.Period.Microseconds(), 10),
+		strconv.FormatInt(nowUs, 10),
+		member,
+		consumeArg(consume),
+	)
+	if err != nil {
+		return Result{}, err
+	}
+	if len(values) != 4 {
+		return Result{}, fmt.Errorf("redis: unexpected sliding window reply %v", values)
+	}
+	return slidingWindowResult(values[0].(int64) == 1, values[1].(int64), values[2].(int64), values[3].(int64), nowUs, rate), nil
+}
+
+func (s *RedisStore) gcraDecide(ctx context.Context, key string, rate Rate, now time.Time, consume bool) (Result, error) {
+	if err := rate.validate(); err != nil {
+		return Result{}, err
+	}
+	nowUs := micros(now)
+	values, err := s.eval(ctx, gcraLua, key,
+		strconv.FormatInt(rate.emission(), 10),
+		strconv.FormatInt(rate.burst(), 10),
+		strconv.FormatInt(nowUs, 10),
+		consumeArg(consume),
+	)
+	if err != nil {
+		return Result{}, err
+	}
+	if len(values) != 2 {
+		return Result{}, fmt.Errorf("redis: unexpected gcra reply %v", values)
+	}
+	return gcraResult(values[0].(int64) == 1, values[1].(int64), nowUs, rate), nil
+}
+
+func (s *RedisStore) Close() error {
+	s.pool.close()
+	return nil
+}
@@ -0,0 +1,169 @@
+// ratelimit/registry.go
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// PolicyRegistry - PolicySet yang sedang aktif beserta sumbernya. File policy bisa diubah saat
+// server berjalan: Watch memuat ulang file yang berubah dan menukar PolicySet secara atomik.
+// Store tidak ikut diganti, jadi counter per aturan tetap berjalan setelah reload.
+type PolicyRegistry struct {
+	path  string // kosong = policy bawaan
+	store Store
+	clock Clock
+
+	mu          sync.Mutex // satu reload pada satu waktu
+	current     atomic.Pointer[PolicySet]
+	checksum    string
+	loadedAt    time.Time
+	modTime     time.Time
+	size        int64
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// PolicyStatus - ringkasan registry untuk endpoint admin
+type PolicyStatus struct {
+	Source      string
+	Checksum    string
+	LoadedAt    time.Time
+	LastError   string
+	LastErrorAt time.Time
+	Rules       []PolicyRule
+}
+
+// NewPolicyRegistry - memuat policy dari path (kosong = bawaan). Error di sini fatal untuk
+// pemanggil karena belum ada policy lama yang bisa dipakai.
+func NewPolicyRegistry(path string, store Store, clock Clock) (*PolicyRegistry, error) {
+	if clock == nil {
+		clock = SystemClock{}
+	}
+	registry := &PolicyRegistry{path: path, store: store, clock: clock}
+	if _, err := registry.Reload(); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// Current - PolicySet aktif
+func (r *PolicyRegistry) Current() *PolicySet {
+	return r.current.Load()
+}
+
+// Reload - membaca ulang sumber policy. Jika isinya sama tidak ada yang diganti; jika tidak
+// valid, policy lama tetap dipakai dan error dicatat di Status.
+func (r *PolicyRegistry) Reload() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data := embeddedPolicies
+	var info os.FileInfo
+	if r.path != "" {
+		var err error
+		if info, err = os.Stat(r.path); err == nil {
+			data, err = os.ReadFile(r.path)
+		}
+		if err != nil {
+			return false, r.fail(err)
+		}
+		r.modTime, r.size = info.ModTime(), info.Size()
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	if checksum == r.checksum {
+		return false, nil
+	}
+
+	config, err := ParsePolicyConfig(data)
+	if err != nil {
+		return false, r.fail(err)
+	}
+	set, err := NewPolicySet(config, r.store, r.clock)
+	if err != nil {
+		return false, r.fail(err)
+	}
+
+	r.current.Store(set)
+	r.checksum = checksum
+	r.loadedAt = r.clock.Now()
+	r.lastError = ""
+	return true, nil
+}
+
+func (r *PolicyRegistry) fail(err error) error {
+	r.lastError = err.Error()
+	r.lastErrorAt = r.clock.Now()
+	return err
+}
+
+// changed - apakah file berubah sejak dibaca terakhir (cek murah sebelum membaca isi)
+func (r *PolicyRegistry) changed() bool {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return true // biar Reload mencatat errornya
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !info.ModTime().Equal(r.modTime) || info.Size() != r.size
+}
+
+// Watch - memulai routine yang memeriksa file policy setiap interval
+func (r *PolicyRegistry) Watch(interval time.Duration) {
+	if r.path == "" || interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if !r.changed() {
+				continue
+			}
+			reloaded, err := r.Reload()
+			if err != nil {
+				log.Printf("Rate limit policy reload failed, keeping previous policies: %v", err)
+				continue
+			}
+			if reloaded {
+				log.Printf("Rate limit policies reloaded from %s (%d rules)", r.path, len(r.Current().rules))
+			}
+		}
+	}()
+}
+
+func (r *PolicyRegistry) Status() PolicyStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	source := r.path
+	if source == "" {
+		source = "embedded"
+	}
+	return PolicyStatus{
+		Source:      source,
+		Checksum:    r.checksum,
+		LoadedAt:    r.loadedAt,
+		LastError:   r.lastError,
+		LastErrorAt: r.lastErrorAt,
+		Rules:       r.Current().Rules(),
+	}
+}
+
+var activePolicies atomic.Pointer[PolicyRegistry]
+
+// SetActivePolicies - registry yang dipakai middleware policy dan endpoint admin
+func SetActivePolicies(registry *PolicyRegistry) {
+	activePolicies.Store(registry)
+}
+
+// ActivePolicies - registry aktif, nil jika belum diatur (lihat config.ConnectRateLimitPolicies)
+func ActivePolicies() *PolicyRegistry {
+	return activePolicies.Load()
+}
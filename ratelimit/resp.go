@@ -0,0 +1,233 @@
+// ratelimit/resp.go - client Redis minimal (protokol RESP2) untuk RedisStore.
+// Tidak memakai library supaya bisa dijalankan terhadap server apapun yang bicara
+// protokol Redis (Redis, Valkey, KeyDB, miniredis, fake in-process).
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RedisError - balasan error dari server, contoh "NOSCRIPT No matching script"
+type RedisError string
+
+func (e RedisError) Error() string { return string(e) }
+
+var errNilReply = errors.New("redis: nil reply")
+
+// RedisConfig - koneksi ke server Redis
+type RedisConfig struct {
+	Addr     string // host:port
+	Username string // ACL user, kosong = default
+	Password string
+	DB       int
+	TLS      bool
+
+	Prefix      string        // prefix semua key, default "ratelimit:"
+	PoolSize    int           // koneksi idle maksimal, default 10
+	DialTimeout time.Duration // default 1 detik
+	Timeout     time.Duration // batas satu operasi jika ctx tidak punya deadline, default 200ms
+}
+
+// ParseRedisURL - redis://[user:password@]host:port/db, rediss:// untuk TLS
+func ParseRedisURL(raw string) (RedisConfig, error) {
+	var config RedisConfig
+	u, err := url.Parse(raw)
+	if err != nil {
+		return config, err
+	}
+	switch u.Scheme {
+	case "redis":
+	case "rediss":
+		config.TLS = true
+	default:
+		return config, fmt.Errorf("redis url must start with redis:// or rediss://")
+	}
+
+	config.Addr = u.Host
+	if u.Port() == "" {
+		config.Addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		config.Username = u.User.Username()
+		config.Password, _ = u.User.Password()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if config.DB, err = strconv.Atoi(db); err != nil {
+			return config, fmt.Errorf("invalid redis db %q", db)
+		}
+	}
+	return config, nil
+}
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+func dialRedis(ctx context.Context, config RedisConfig) (*redisConn, error) {
+	dialer := &net.Dialer{Timeout: config.DialTimeout}
+	var conn net.Conn
+	var err error
+	if config.TLS {
+		host, _, _ := net.SplitHostPort(config.Addr)
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", config.Addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", config.Addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rc := &redisConn{conn: conn, reader: bufio.NewReader(conn), writer: bufio.NewWriter(conn)}
+	if config.Password != "" {
+		args := []string{"AUTH", config.Password}
+		if config.Username != "" {
+			args = []string{"AUTH", config.Username, config.Password}
+		}
+		if _, err := rc.do(ctx, args...); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	if config.DB != 0 {
+		if _, err := rc.do(ctx, "SELECT", strconv.Itoa(config.DB)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return rc, nil
+}
+
+// do - kirim satu command lalu baca balasannya
+func (rc *redisConn) do(ctx context.Context, args ...string) (interface{}, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		rc.conn.SetDeadline(deadline)
+	} else {
+		rc.conn.SetDeadline(time.Time{})
+	}
+
+	fmt.Fprintf(rc.writer, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(rc.writer, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := rc.writer.Flush(); err != nil {
+		return nil, err
+	}
+	return readReply(rc.reader)
+}
+
+// readReply - membaca satu balasan RESP2. Error dari server dikembalikan sebagai RedisError,
+// bulk/array nil sebagai errNilReply di level teratas dan nil di dalam array.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, RedisError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid bulk length %q", payload)
+		}
+		if size < 0 {
+			return nil, errNilReply
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid array length %q", payload)
+		}
+		if count < 0 {
+			return nil, errNilReply
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			item, err := readReply(r)
+			if err != nil && !errors.Is(err, errNilReply) {
+				var redisErr RedisError
+				if !errors.As(err, &redisErr) {
+					return nil, err
+				}
+				item = redisErr
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unknown reply type %q", kind)
+}
+
+// redisPool - koneksi idle dipakai ulang, koneksi yang error dibuang
+type redisPool struct {
+	config RedisConfig
+	idle   chan *redisConn
+}
+
+func newRedisPool(config RedisConfig) *redisPool {
+	return &redisPool{config: config, idle: make(chan *redisConn, config.PoolSize)}
+}
+
+func (p *redisPool) do(ctx context.Context, args ...string) (interface{}, error) {
+	var rc *redisConn
+	select {
+	case rc = <-p.idle:
+	default:
+		var err error
+		if rc, err = dialRedis(ctx, p.config); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := rc.do(ctx, args...)
+	var redisErr RedisError
+	if err != nil && !errors.As(err, &redisErr) && !errors.Is(err, errNilReply) {
+		// Error jaringan / timeout: state koneksi tidak jelas, jangan dipakai lagi
+		rc.conn.Close()
+		return nil, err
+	}
+
+	select {
+	case p.idle <- rc:
+	default:
+		rc.conn.Close()
+	}
+	return reply, err
+}
+
+func (p *redisPool) close() {
+	for {
+		select {
+		case rc := <-p.idle:
+			rc.conn.Close()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// Package ratelimit - inti rate limiter dan penyimpanan state-nya.
+//
+// Limiter memutuskan satu request berdasarkan Policy (algoritma + Rate) memakai Clock,
+// sedangkan Store menyimpan state per key. MemoryStore untuk satu instance, RedisStore
+// untuk beberapa replica API yang harus berbagi limit yang sama. Kedua implementasi memakai
+// aritmetika bilangan bulat yang sama (waktu dalam mikrodetik, saldo token dalam unit, lihat
+// tokenUnits) sehingga hasilnya identik dan tidak ada sisa refill yang hilang karena pembulatan,
+// diverifikasi oleh store_test.go terhadap kedua implementasi.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrInvalidRate = errors.New("rate limit and period must be greater than 0")
+
+// Rate - Limit request per Period. Burst = kapasitas token bucket / GCRA
+// (tidak dipakai sliding window), minimal 1.
+type Rate struct {
+	Limit  int
+	Period time.Duration
+	Burst  int
+}
+
+func (r Rate) validate() error {
+	if r.Limit <= 0 || r.Period < time.Millisecond {
+		return ErrInvalidRate
+	}
+	return nil
+}
+
+func (r Rate) burst() int64 {
+	if r.Burst <= 0 {
+		return 1
+	}
+	return int64(r.Burst)
+}
+
+// emission - jarak antar request pada laju rata-rata (GCRA), dalam mikrodetik
+func (r Rate) emission() int64 {
+	emission := r.Period.Microseconds() / int64(r.Limit)
+	if emission < 1 {
+		return 1
+	}
+	return emission
+}
+
+// Result - keputusan untuk satu request
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration // jika ditolak: jeda sampai request berikutnya boleh
+	ResetAfter time.Duration // jeda sampai limit kembali penuh
+}
+
+// Store - state rate limiter. Key sudah termasuk nama limiter dan algoritmanya (Limiter.storeKey),
+// jadi satu key hanya pernah dipakai satu algoritma. `now` diberikan pemanggil (Clock di Limiter)
+// supaya semua replica dan test memakai jam yang sama untuk perhitungan.
+type Store interface {
+	TokenBucket(ctx context.Context, key string, rate Rate, now time.Time) (Result, error)
+	SlidingWindow(ctx context.Context, key string, rate Rate, now time.Time) (Result, error)
+	GCRA(ctx context.Context, key string, rate Rate, now time.Time) (Result, error)
+	// Peek - state key untuk algoritma tersebut tanpa memakai kuota (Allowed = request
+	// berikutnya akan diterima, Remaining = kuota yang tersisa sekarang)
+	Peek(ctx context.Context, algorithm Algorithm, key string, rate Rate, now time.Time) (Result, error)
+	// Reset - menghapus state key
+	Reset(ctx context.Context, key string) error
+	Close() error
+}
+
+// Cleaner - store yang perlu membuang key kedaluwarsa secara berkala (Redis memakai TTL)
+type Cleaner interface {
+	Cleanup(now time.Time)
+}
+
+func micros(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+func microDuration(us int64) time.Duration {
+	return time.Duration(us) * time.Microsecond
+}
+
+// tokenUnits - harga satu token dalam unit saldo token bucket. Satu token = Period (µs) unit
+// dan setiap mikrodetik menambah Limit unit, jadi refill selalu bilangan bulat yang tepat
+// (tidak ada pecahan token yang dibuang seperti pembulatan per menit versi lama).
+func (r Rate) tokenUnits() int64 {
+	return r.Period.Microseconds()
+}
+
+// refillUnits - unit yang bertambah selama elapsed mikrodetik, paling banyak missing.
+// elapsed dibatasi dulu supaya elapsed*Limit tidak overflow (dan tetap tepat di Lua/double).
+func refillUnits(elapsed int64, rate Rate, missing int64) int64 {
+	if elapsed <= 0 || missing <= 0 {
+		return 0
+	}
+	if elapsed >= (missing+int64(rate.Limit)-1)/int64(rate.Limit) {
+		return missing
+	}
+	return elapsed * int64(rate.Limit)
+}
+
+// durationForUnits - waktu yang dibutuhkan untuk mengisi `missing` unit, dibulatkan ke atas
+func durationForUnits(missing int64, rate Rate) time.Duration {
+	if missing <= 0 {
+		return 0
+	}
+	return microDuration((missing + int64(rate.Limit) - 1) / int64(rate.Limit))
+}
+
+// tokenBucketResult - Result dari saldo token (unit) setelah request diputuskan
+func tokenBucketResult(allowed bool, units int64, rate Rate) Result {
+	result := Result{
+		Allowed:    allowed,
+		Limit:      rate.Limit,
+		Remaining:  int(units / rate.tokenUnits()),
+		ResetAfter: durationForUnits(rate.burst()*rate.tokenUnits()-units, rate),
+	}
+	if !allowed {
+		result.RetryAfter = durationForUnits(rate.tokenUnits()-units, rate)
+	}
+	return result
+}
+
+// slidingWindowResult - Result dari jumlah request di window dan request tertua/terbaru (µs)
+func slidingWindowResult(allowed bool, count, oldest, newest, now int64, rate Rate) Result {
+	result := Result{
+		Allowed:   allowed,
+		Limit:     rate.Limit,
+		Remaining: rate.Limit - int(count),
+	}
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	if count > 0 {
+		result.ResetAfter = microDuration(newest + rate.Period.Microseconds() - now)
+	}
+	if !allowed {
+		result.RetryAfter = microDuration(oldest + rate.Period.Microseconds() - now)
+	}
+	return result
+}
+
+// gcraResult - Result dari theoretical arrival time (TAT) setelah request diputuskan (µs).
+// Request diterima jika now >= TAT + emission - burst*emission.
+func gcraResult(allowed bool, tat, now int64, rate Rate) Result {
+	emission := rate.emission()
+	tolerance := emission * rate.burst()
+	result := Result{Allowed: allowed, Limit: rate.Limit}
+
+	if tat > now {
+		result.ResetAfter = microDuration(tat - now)
+	}
+	if allowed {
+		result.Remaining = int((now - (tat - tolerance)) / emission)
+	} else {
+		result.RetryAfter = microDuration(tat + emission - tolerance - now)
+	}
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	return result
+}
+
+// ttlMillis - TTL key (ms, dibulatkan ke atas) ditambah 1 detik cadangan
+func ttlMillis(d time.Duration) int64 {
+	return int64((d+time.Millisecond-1)/time.Millisecond) + 1000
+}
+
+// withTimeout - batas waktu default untuk satu operasi store jika ctx tidak punya deadline
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
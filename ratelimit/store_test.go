@@ -0,0 +1,275 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"sync"
+	"testing"
+	"time"
+)
+
+type storeStep struct {
+	at         time.Duration // offset dari waktu awal skenario
+	key        string
+	want       bool
+	remaining  int
+	retryAfter time.Duration // hanya dicek jika request ditolak
+	resetAfter time.Duration // hanya dicek jika > 0
+	rateLimit  string        // header RateLimit, hanya dicek jika diisi
+	peek       bool          // Limiter.Peek, kuota tidak dipakai
+	reset      bool          // Limiter.Reset, hasil tidak dicek
+}
+
+type storeScenario struct {
+	name      string
+	algorithm Algorithm
+	rate      Rate
+	steps     []storeStep
+}
+
+var storeScenarios = []storeScenario{
+	{
+		name: "token bucket: burst habis lalu refill bertahap",
+		rate: Rate{Limit: 60, Period: time.Minute, Burst: 3},
+		steps: []storeStep{
+			{at: 0, key: "a", peek: true, want: true, remaining: 3},
+			{at: 0, key: "a", want: true, remaining: 2, resetAfter: time.Second, rateLimit: `"test";r=2;t=1`},
+			{at: 0, key: "a", want: true, remaining: 1, resetAfter: 2 * time.Second},
+			{at: 0, key: "a", want: true, remaining: 0, resetAfter: 3 * time.Second, rateLimit: `"test";r=0;t=3`},
+			{at: 0, key: "a", want: false, remaining: 0, retryAfter: time.Second, rateLimit: `"test";r=0;t=1`},
+			{at: 400 * time.Millisecond, key: "a", want: false, remaining: 0, retryAfter: 600 * time.Millisecond},
+			{at: 400 * time.Millisecond, key: "a", peek: true, want: false, remaining: 0, retryAfter: 600 * time.Millisecond},
+			{at: time.Second, key: "a", want: true, remaining: 0},
+			{at: time.Second, key: "a", want: false, remaining: 0, retryAfter: time.Second},
+			// Key lain punya bucket sendiri
+			{at: time.Second, key: "b", want: true, remaining: 2},
+			// Reset mengembalikan bucket penuh
+			{at: time.Second, key: "a", reset: true},
+			{at: time.Second, key: "a", want: true, remaining: 2},
+		},
+	},
+	{
+		name: "token bucket: refill kontinu pada traffic rutin",
+		rate: Rate{Limit: 2, Period: time.Minute, Burst: 2},
+		steps: []storeStep{
+			// 30 detik per token, request tiap 20 detik. Pecahan token dari setiap refill
+			// terbawa ke request berikutnya (versi lama membulatkan ke bawah per menit)
+			{at: 0, key: "a", want: true, remaining: 1, resetAfter: 30 * time.Second},
+			{at: 20 * time.Second, key: "a", want: true, remaining: 0, resetAfter: 40 * time.Second},
+			{at: 40 * time.Second, key: "a", want: true, remaining: 0, resetAfter: 50 * time.Second},
+			{at: 60 * time.Second, key: "a", want: true, remaining: 0, resetAfter: 60 * time.Second},
+			{at: 80 * time.Second, key: "a", want: false, retryAfter: 10 * time.Second},
+			{at: 90 * time.Second, key: "a", want: true, remaining: 0, resetAfter: 60 * time.Second},
+		},
+	},
+	{
+		name: "token bucket: refill tidak melebihi burst",
+		rate: Rate{Limit: 10, Period: time.Second, Burst: 5},
+		steps: []storeStep{
+			{at: 0, key: "a", want: true, remaining: 4},
+			{at: time.Hour, key: "a", want: true, remaining: 4, resetAfter: 100 * time.Millisecond},
+		},
+	},
+	{
+		name: "token bucket: refill sebagian tidak hilang",
+		rate: Rate{Limit: 3, Period: time.Second, Burst: 1},
+		steps: []storeStep{
+			{at: 0, key: "a", want: true, remaining: 0},
+			// 1/3 detik per token, dicek berkali-kali sebelum token penuh
+			{at: 100 * time.Millisecond, key: "a", want: false, retryAfter: 233334 * time.Microsecond},
+			{at: 200 * time.Millisecond, key: "a", want: false, retryAfter: 133334 * time.Microsecond},
+			{at: 300 * time.Millisecond, key: "a", want: false, retryAfter: 33334 * time.Microsecond},
+			{at: 334 * time.Millisecond, key: "a", want: true, remaining: 0},
+		},
+	},
+	{
+		name: "token bucket: jam mundur tidak menambah token",
+		rate: Rate{Limit: 60, Period: time.Minute, Burst: 1},
+		steps: []storeStep{
+			{at: 10 * time.Second, key: "a", want: true, remaining: 0},
+			{at: 5 * time.Second, key: "a", want: false, retryAfter: time.Second},
+			{at: 11 * time.Second, key: "a", want: true, remaining: 0},
+		},
+	},
+	{
+		name:      "sliding window: limit per window dan request ditolak tidak dihitung",
+		algorithm: AlgorithmSlidingWindow,
+		rate:      Rate{Limit: 3, Period: 10 * time.Second},
+		steps: []storeStep{
+			{at: 0, key: "a", want: true, remaining: 2, resetAfter: 10 * time.Second},
+			{at: time.Second, key: "a", want: true, remaining: 1},
+			{at: 2 * time.Second, key: "a", want: true, remaining: 0, resetAfter: 10 * time.Second},
+			{at: 3 * time.Second, key: "a", want: false, remaining: 0, retryAfter: 7 * time.Second, resetAfter: 9 * time.Second},
+			{at: 3 * time.Second, key: "a", peek: true, want: false, remaining: 0, retryAfter: 7 * time.Second},
+			{at: 9 * time.Second, key: "a", want: false, remaining: 0, retryAfter: time.Second},
+			// Request pertama (t=0) keluar dari window tepat di t=10s
+			{at: 10 * time.Second, key: "a", want: true, remaining: 0},
+			{at: 10 * time.Second, key: "a", want: false, remaining: 0, retryAfter: time.Second},
+			{at: 10 * time.Second, key: "b", want: true, remaining: 2},
+			{at: 10 * time.Second, key: "a", reset: true},
+			{at: 10 * time.Second, key: "a", want: true, remaining: 2},
+			{at: 30 * time.Second, key: "a", peek: true, want: true, remaining: 3},
+			{at: 30 * time.Second, key: "a", want: true, remaining: 2},
+		},
+	},
+	{
+		name:      "gcra: burst lalu satu request per emission interval",
+		algorithm: AlgorithmGCRA,
+		rate:      Rate{Limit: 60, Period: time.Minute, Burst: 3},
+		steps: []storeStep{
+			{at: 0, key: "a", want: true, remaining: 2, resetAfter: time.Second, rateLimit: `"test";r=2;t=1`},
+			{at: 0, key: "a", want: true, remaining: 1, resetAfter: 2 * time.Second},
+			{at: 0, key: "a", want: true, remaining: 0, resetAfter: 3 * time.Second},
+			{at: 0, key: "a", want: false, remaining: 0, retryAfter: time.Second, resetAfter: 3 * time.Second},
+			{at: 400 * time.Millisecond, key: "a", want: false, remaining: 0, retryAfter: 600 * time.Millisecond},
+			{at: time.Second, key: "a", want: true, remaining: 0, resetAfter: 3 * time.Second},
+			{at: time.Second, key: "a", want: false, remaining: 0, retryAfter: time.Second},
+			{at: 2 * time.Second, key: "a", peek: true, want: true, remaining: 1},
+			{at: time.Second, key: "b", want: true, remaining: 2},
+			// Setelah diam cukup lama kapasitas kembali penuh, tidak lebih
+			{at: time.Hour, key: "a", want: true, remaining: 2, resetAfter: time.Second},
+		},
+	},
+	{
+		name:      "gcra: interval tidak bulat",
+		algorithm: AlgorithmGCRA,
+		rate:      Rate{Limit: 3, Period: time.Second, Burst: 1},
+		steps: []storeStep{
+			{at: 0, key: "a", want: true, remaining: 0, resetAfter: 333333 * time.Microsecond},
+			{at: 100 * time.Millisecond, key: "a", want: false, retryAfter: 233333 * time.Microsecond},
+			{at: 333333 * time.Microsecond, key: "a", want: true, remaining: 0},
+			{at: 666665 * time.Microsecond, key: "a", want: false, retryAfter: time.Microsecond},
+			{at: 666666 * time.Microsecond, key: "a", want: true, remaining: 0},
+		},
+	},
+	{
+		name:      "gcra: jam mundur tidak memberi kapasitas tambahan",
+		algorithm: AlgorithmGCRA,
+		rate:      Rate{Limit: 60, Period: time.Minute, Burst: 1},
+		steps: []storeStep{
+			{at: 10 * time.Second, key: "a", want: true, remaining: 0},
+			{at: 5 * time.Second, key: "a", want: false, retryAfter: 6 * time.Second},
+			{at: 11 * time.Second, key: "a", want: true, remaining: 0},
+		},
+	},
+}
+
+// testStores - setiap skenario dijalankan terhadap semua implementasi Store: RedisStore lewat
+// miniredis (script Lua yang sama dengan server Redis sungguhan) harus memberi hasil yang sama
+// persis dengan MemoryStore
+var testStores = []struct {
+	name     string
+	newStore func(t *testing.T) Store
+}{
+	{"memory", func(t *testing.T) Store { return NewMemoryStore() }},
+	{"redis", newMiniredisStore},
+}
+
+func newMiniredisStore(t *testing.T) Store {
+	t.Helper()
+	server := miniredis.RunT(t)
+	config, err := ParseRedisURL("redis://" + server.Addr() + "/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := NewRedisStore(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestStoreScenarios(t *testing.T) {
+	start := time.UnixMilli(1_700_000_000_000)
+	for _, backend := range testStores {
+		for _, scenario := range storeScenarios {
+			t.Run(backend.name+"/"+scenario.name, func(t *testing.T) {
+				ctx := context.Background()
+				clock := NewFakeClock(start)
+				limiter, err := NewLimiter(Policy{Name: "test", Algorithm: scenario.algorithm, Rate: scenario.rate}, backend.newStore(t), clock)
+				if err != nil {
+					t.Fatal(err)
+				}
+				limiter = limiter.WithMetrics(nil)
+
+				for i, step := range scenario.steps {
+					clock.Set(start.Add(step.at))
+					if step.reset {
+						if err := limiter.Reset(ctx, step.key); err != nil {
+							t.Fatalf("step %d: %v", i+1, err)
+						}
+						continue
+					}
+
+					var result Result
+					if step.peek {
+						result, err = limiter.Peek(ctx, step.key)
+					} else {
+						result, err = limiter.Allow(ctx, step.key)
+					}
+					if err != nil {
+						t.Fatalf("step %d: %v", i+1, err)
+					}
+
+					if result.Allowed != step.want ||
+						result.Remaining != step.remaining ||
+						(!step.want && result.RetryAfter != step.retryAfter) ||
+						(step.resetAfter > 0 && result.ResetAfter != step.resetAfter) {
+						t.Fatalf("step %d: got allowed=%v remaining=%d retry_after=%s reset_after=%s, want allowed=%v remaining=%d retry_after=%s reset_after=%s",
+							i+1, result.Allowed, result.Remaining, result.RetryAfter, result.ResetAfter,
+							step.want, step.remaining, step.retryAfter, step.resetAfter)
+					}
+					if step.rateLimit != "" {
+						if got := limiter.Headers(result)["RateLimit"]; got != step.rateLimit {
+							t.Fatalf("step %d: RateLimit header = %s, want %s", i+1, got, step.rateLimit)
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+// Request bersamaan pada key yang sama tidak boleh melewati kapasitas burst
+func TestStoreConcurrentAllow(t *testing.T) {
+	const burst = 25
+	for _, backend := range testStores {
+		for _, algorithm := range []Algorithm{AlgorithmTokenBucket, AlgorithmGCRA, AlgorithmSlidingWindow} {
+			t.Run(backend.name+"/"+string(algorithm), func(t *testing.T) {
+				policy := Policy{Name: "test", Algorithm: algorithm, Rate: Rate{Limit: burst, Period: time.Minute, Burst: burst}}
+				limiter, err := NewLimiter(policy, backend.newStore(t), NewFakeClock(time.Unix(1_800_000_000, 0)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				limiter = limiter.WithMetrics(nil)
+
+				var (
+					wg      sync.WaitGroup
+					mu      sync.Mutex
+					allowed int
+				)
+				for i := 0; i < 8*burst; i++ {
+					wg.Add(1)
+					go func() {
+						defer wg.Done()
+						result, err := limiter.Allow(context.Background(), "k")
+						if err != nil {
+							t.Error(err)
+							return
+						}
+						if result.Allowed {
+							mu.Lock()
+							allowed++
+							mu.Unlock()
+						}
+					}()
+				}
+				wg.Wait()
+				if allowed != burst {
+					t.Errorf("allowed %d requests, want %d", allowed, burst)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// recommend/composer.go
+package recommend
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/utils"
+	"gorm.io/gorm"
+)
+
+const defaultLimit = 20
+
+// Result - hasil komposisi beberapa strategi
+type Result struct {
+	Products      []models.ProductListView
+	Basis         string
+	Categories    []string
+	Contributions map[string]int // jumlah produk per strategi di hasil akhir
+}
+
+// DefaultArm - komposisi saat tidak ada experiment aktif: produk toko yang di-follow di depan,
+// lalu item-to-item dan produk mirip yang baru dilihat diselang-seling dengan kategori personal
+func DefaultArm() models.ExperimentArm {
+	return models.ExperimentArm{
+		Name: "default",
+		Strategies: []models.StrategyWeight{
+			{Name: StrategyFollowedShops, Weight: 1, Limit: 5},
+			{Name: StrategyItemSimilarity, Weight: 1, Limit: 10},
+			{Name: StrategyRecentlyViewed, Weight: 1, Limit: 8},
+			{Name: StrategyPersonal, Weight: 1},
+		},
+	}
+}
+
+type source struct {
+	weight     float64
+	limit      int
+	candidates Candidates
+	next       int
+	taken      int
+	credit     float64
+}
+
+func (s *source) exhausted() bool {
+	return s.next >= len(s.candidates.Products) || (s.limit > 0 && s.taken >= s.limit)
+}
+
+// Compose - menjalankan strategi di arm lalu mencampurnya dengan smooth weighted round-robin:
+// strategi dengan bobot lebih besar lebih sering mendapat giliran, urutan di arm memecah seri.
+// Produk duplikat dilewati. Produk yang melanggar MaxPerShop/MaxPerCategory ditunda dan hanya
+// dipakai jika hasil masih kurang dari limit. Jika semua strategi kosong dipakai bestseller.
+func Compose(db *gorm.DB, req Request, arm models.ExperimentArm) Result {
+	if req.Limit <= 0 {
+		req.Limit = defaultLimit
+	}
+
+	var sources []*source
+	var names []string
+	for _, strategy := range arm.Strategies {
+		r, ok := Lookup(strategy.Name)
+		if !ok || strategy.Weight <= 0 {
+			continue
+		}
+		sources = append(sources, &source{
+			weight:     strategy.Weight,
+			limit:      strategy.Limit,
+			candidates: r.Recommend(db, req),
+		})
+		names = append(names, strategy.Name)
+	}
+
+	result := Result{Contributions: make(map[string]int)}
+	seen := make(utils.ProductIDSet)
+	perShop := make(map[string]int)
+	perCategory := make(map[string]int)
+	var deferred []models.ProductListView
+	var deferredFrom []string
+
+	violatesDiversity := func(product models.ProductListView) bool {
+		if arm.MaxPerShop > 0 && product.ShopName != "" && perShop[product.ShopName] >= arm.MaxPerShop {
+			return true
+		}
+		return arm.MaxPerCategory > 0 && product.Category != "" && perCategory[product.Category] >= arm.MaxPerCategory
+	}
+	accept := func(product models.ProductListView, strategy string) {
+		perShop[product.ShopName]++
+		perCategory[product.Category]++
+		result.Products = append(result.Products, product)
+		result.Contributions[strategy]++
+	}
+
+	for len(result.Products) < req.Limit {
+		best := -1
+		var total float64
+		for i, s := range sources {
+			if s.exhausted() {
+				continue
+			}
+			s.credit += s.weight
+			total += s.weight
+			if best < 0 || s.credit > sources[best].credit {
+				best = i
+			}
+		}
+		if best < 0 {
+			break
+		}
+		s := sources[best]
+		s.credit -= total
+
+		// Ambil kandidat berikutnya yang belum dipakai dari strategi ini
+		for !s.exhausted() {
+			product := s.candidates.Products[s.next]
+			s.next++
+			if !seen.Add(product.ID) {
+				continue
+			}
+			if violatesDiversity(product) {
+				deferred = append(deferred, product)
+				deferredFrom = append(deferredFrom, names[best])
+				continue
+			}
+			accept(product, names[best])
+			s.taken++
+			break
+		}
+	}
+
+	for i := 0; i < len(deferred) && len(result.Products) < req.Limit; i++ {
+		accept(deferred[i], deferredFrom[i])
+	}
+
+	// Basis dari strategi yang paling banyak menyumbang, seri dimenangkan urutan di arm
+	bestCount := 0
+	for i, s := range sources {
+		if count := result.Contributions[names[i]]; count > bestCount {
+			bestCount = count
+			result.Basis = s.candidates.Basis
+		}
+		if result.Contributions[names[i]] > 0 {
+			for _, category := range s.candidates.Categories {
+				if !containsString(result.Categories, category) {
+					result.Categories = append(result.Categories, category)
+				}
+			}
+		}
+	}
+
+	if len(result.Products) == 0 {
+		result.Products = utils.GetAnonymousRecommendations(db)
+		if len(result.Products) > req.Limit {
+			result.Products = result.Products[:req.Limit]
+		}
+		result.Basis = "fallback_bestseller_and_rating"
+		result.Contributions[StrategyBestseller] = len(result.Products)
+	}
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+// recommend/evaluate.go - metrik evaluasi offline (dipakai cmd/receval)
+package recommend
+
+import (
+	"ecommerce-golang/models"
+	"math"
+)
+
+// EvalReport - rata-rata metrik satu strategi atas semua user yang dievaluasi
+type EvalReport struct {
+	Name       string  `json:"name"`
+	K          int     `json:"k"`
+	Users      int     `json:"users"`
+	EmptyLists int     `json:"empty_lists"` // user yang tidak mendapat rekomendasi sama sekali
+	Precision  float64 `json:"precision_at_k"`
+	Recall     float64 `json:"recall_at_k"`
+	NDCG       float64 `json:"ndcg_at_k"`
+	HitRate    float64 `json:"hit_rate"`  // user dengan minimal satu produk relevan di top-k
+	Coverage   float64 `json:"coverage"`  // produk unik yang pernah direkomendasikan / ukuran katalog
+	Diversity  float64 `json:"diversity"` // rata-rata intra-list diversity kategori
+}
+
+// Evaluation - mengumpulkan metrik per user untuk satu strategi
+type Evaluation struct {
+	name        string
+	k           int
+	catalogSize int
+
+	users, empty, hits    int
+	precision, recall     float64
+	ndcg                  float64
+	diversity             float64
+	diversityLists        int
+	recommendedProductIDs map[uint]bool
+}
+
+// NewEvaluation - catalogSize = jumlah produk yang bisa direkomendasikan, untuk coverage
+func NewEvaluation(name string, k, catalogSize int) *Evaluation {
+	return &Evaluation{
+		name:                  name,
+		k:                     k,
+		catalogSize:           catalogSize,
+		recommendedProductIDs: make(map[uint]bool),
+	}
+}
+
+// Add - hasil rekomendasi untuk satu user dan produk yang ternyata dibeli (held-out).
+// List kosong tetap dihitung dengan nilai 0 supaya strategi yang jarang berlaku tidak diuntungkan.
+func (e *Evaluation) Add(recommended []models.ProductListView, relevant []uint) {
+	if len(recommended) > e.k {
+		recommended = recommended[:e.k]
+	}
+	relevantSet := make(map[uint]bool, len(relevant))
+	for _, id := range relevant {
+		relevantSet[id] = true
+	}
+	ids := make([]uint, len(recommended))
+	categories := make([]string, len(recommended))
+	for i, product := range recommended {
+		ids[i] = product.ID
+		categories[i] = product.Category
+		e.recommendedProductIDs[product.ID] = true
+	}
+
+	e.users++
+	if len(ids) == 0 {
+		e.empty++
+	}
+	precision := PrecisionAtK(ids, relevantSet, e.k)
+	if precision > 0 {
+		e.hits++
+	}
+	e.precision += precision
+	e.recall += RecallAtK(ids, relevantSet, e.k)
+	e.ndcg += NDCGAtK(ids, relevantSet, e.k)
+	if len(categories) >= 2 {
+		e.diversity += IntraListDiversity(categories)
+		e.diversityLists++
+	}
+}
+
+func (e *Evaluation) Report() EvalReport {
+	report := EvalReport{Name: e.name, K: e.k, Users: e.users, EmptyLists: e.empty}
+	if e.users > 0 {
+		n := float64(e.users)
+		report.Precision = e.precision / n
+		report.Recall = e.recall / n
+		report.NDCG = e.ndcg / n
+		report.HitRate = float64(e.hits) / n
+	}
+	if e.catalogSize > 0 {
+		report.Coverage = float64(len(e.recommendedProductIDs)) / float64(e.catalogSize)
+	}
+	if e.diversityLists > 0 {
+		report.Diversity = e.diversity / float64(e.diversityLists)
+	}
+	return report
+}
+
+func countHits(recommended []uint, relevant map[uint]bool, k int) int {
+	hits := 0
+	for i, id := range recommended {
+		if i >= k {
+			break
+		}
+		if relevant[id] {
+			hits++
+		}
+	}
+	return hits
+}
+
+// PrecisionAtK - produk relevan di top-k dibagi k
+func PrecisionAtK(recommended []uint, relevant map[uint]bool, k int) float64 {
+	if k <= 0 {
+		return 0
+	}
+	return float64(countHits(recommended, relevant, k)) / float64(k)
+}
+
+// RecallAtK - produk relevan di top-k dibagi semua produk relevan
+func RecallAtK(recommended []uint, relevant map[uint]bool, k int) float64 {
+	if len(relevant) == 0 {
+		return 0
+	}
+	return float64(countHits(recommended, relevant, k)) / float64(len(relevant))
+}
+
+// NDCGAtK - normalized discounted cumulative gain dengan relevansi biner:
+// produk relevan di posisi atas bernilai lebih besar daripada di posisi bawah
+func NDCGAtK(recommended []uint, relevant map[uint]bool, k int) float64 {
+	var dcg float64
+	for i, id := range recommended {
+		if i >= k {
+			break
+		}
+		if relevant[id] {
+			dcg += 1 / math.Log2(float64(i+2))
+		}
+	}
+
+	var ideal float64
+	for i := 0; i < len(relevant) && i < k; i++ {
+		ideal += 1 / math.Log2(float64(i+2))
+	}
+	if ideal == 0 {
+		return 0
+	}
+	return dcg / ideal
+}
+
+// IntraListDiversity - proporsi pasangan produk di satu list yang kategorinya berbeda
+// (0 = semua satu kategori, 1 = semua kategori berbeda)
+func IntraListDiversity(categories []string) float64 {
+	pairs, different := 0, 0
+	for i := 0; i < len(categories); i++ {
+		for j := i + 1; j < len(categories); j++ {
+			pairs++
+			if categories[i] != categories[j] {
+				different++
+			}
+		}
+	}
+	if pairs == 0 {
+		return 0
+	}
+	return float64(different) / float64(pairs)
+}
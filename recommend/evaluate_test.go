@@ -0,0 +1,117 @@
+package recommend
+
+import (
+	"ecommerce-golang/models"
+	"math"
+	"testing"
+)
+
+func relevantSet(ids ...uint) map[uint]bool {
+	set := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+func assertFloat(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-4 {
+		t.Errorf("%s = %.6f, want %.6f", name, got, want)
+	}
+}
+
+func TestRankingMetrics(t *testing.T) {
+	tests := []struct {
+		name        string
+		recommended []uint
+		relevant    map[uint]bool
+		k           int
+		precision   float64
+		recall      float64
+		ndcg        float64
+	}{
+		{
+			// DCG = 1/log2(3) + 1/log2(5) = 1.0616, IDCG = 1 + 1/log2(3) + 1/log2(4) = 2.1309
+			name: "dua dari tiga relevan", recommended: []uint{1, 2, 3, 4}, relevant: relevantSet(2, 4, 9), k: 4,
+			precision: 0.5, recall: 2.0 / 3, ndcg: 0.4982,
+		},
+		{
+			// Hanya posisi 1-2 yang dinilai: DCG = 1/log2(3), IDCG = 1 + 1/log2(3)
+			name: "dipotong ke k", recommended: []uint{1, 2, 3, 4}, relevant: relevantSet(2, 4, 9), k: 2,
+			precision: 0.5, recall: 1.0 / 3, ndcg: 0.6309 / 1.6309,
+		},
+		{
+			name: "urutan sempurna", recommended: []uint{2, 4, 9}, relevant: relevantSet(2, 4, 9), k: 3,
+			precision: 1, recall: 1, ndcg: 1,
+		},
+		{
+			// DCG = 1/log2(3) = 0.6309, IDCG = 1
+			name: "relevan di posisi kedua", recommended: []uint{5, 2}, relevant: relevantSet(2), k: 2,
+			precision: 0.5, recall: 1, ndcg: 0.6309,
+		},
+		{
+			// List lebih pendek dari k tetap dibagi k
+			name: "list lebih pendek dari k", recommended: []uint{2}, relevant: relevantSet(2, 7), k: 4,
+			precision: 0.25, recall: 0.5, ndcg: 1 / 1.6309,
+		},
+		{
+			name: "tidak ada yang relevan", recommended: []uint{1, 3}, relevant: relevantSet(2), k: 2,
+		},
+		{
+			name: "list kosong", recommended: nil, relevant: relevantSet(2), k: 10,
+		},
+		{
+			name: "tanpa ground truth", recommended: []uint{1, 2}, relevant: relevantSet(), k: 2,
+		},
+		{
+			name: "k nol", recommended: []uint{2}, relevant: relevantSet(2), k: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFloat(t, "precision", PrecisionAtK(tt.recommended, tt.relevant, tt.k), tt.precision)
+			assertFloat(t, "recall", RecallAtK(tt.recommended, tt.relevant, tt.k), tt.recall)
+			assertFloat(t, "ndcg", NDCGAtK(tt.recommended, tt.relevant, tt.k), tt.ndcg)
+		})
+	}
+}
+
+func TestIntraListDiversity(t *testing.T) {
+	tests := []struct {
+		categories []string
+		want       float64
+	}{
+		{[]string{"Buku", "Buku", "Mainan"}, 2.0 / 3},             // 3 pasangan, 2 berbeda
+		{[]string{"Buku", "Mainan", "Elektronik", "Olahraga"}, 1}, // 6 pasangan, semua berbeda
+		{[]string{"Buku", "Buku", "Mainan", "Mainan"}, 4.0 / 6},   // hanya 2 pasangan yang sama
+		{[]string{"Buku", "Buku"}, 0},
+		{[]string{"Buku"}, 0},
+		{nil, 0},
+	}
+	for _, tt := range tests {
+		assertFloat(t, "IntraListDiversity", IntraListDiversity(tt.categories), tt.want)
+	}
+}
+
+func TestEvaluationReport(t *testing.T) {
+	evaluation := NewEvaluation("test", 2, 10)
+	// Produk 3 di luar top-2, tidak dihitung untuk metrik maupun coverage
+	evaluation.Add([]models.ProductListView{
+		{ID: 1, Category: "Buku"},
+		{ID: 2, Category: "Mainan"},
+		{ID: 3, Category: "Buku"},
+	}, []uint{2})
+	evaluation.Add(nil, []uint{4})
+
+	report := evaluation.Report()
+	if report.Users != 2 || report.EmptyLists != 1 || report.K != 2 {
+		t.Errorf("report = %+v", report)
+	}
+	assertFloat(t, "precision", report.Precision, 0.25) // (1/2 + 0) / 2
+	assertFloat(t, "recall", report.Recall, 0.5)
+	assertFloat(t, "ndcg", report.NDCG, 0.6309/2)
+	assertFloat(t, "hit rate", report.HitRate, 0.5)
+	assertFloat(t, "coverage", report.Coverage, 0.2) // produk 1 dan 2 dari katalog 10
+	assertFloat(t, "diversity", report.Diversity, 1) // list kosong tidak ikut dirata-rata
+}
@@ -0,0 +1,94 @@
+// recommend/experiment.go
+package recommend
+
+import (
+	"ecommerce-golang/models"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"hash/fnv"
+	"strings"
+)
+
+// Assignment - arm yang didapat user, dilaporkan di metadata response
+type Assignment struct {
+	Experiment string               `json:"name,omitempty"`
+	Arm        string               `json:"arm"`
+	Mix        models.ExperimentArm `json:"-"`
+}
+
+// Bucket - angka 0..buckets-1 yang selalu sama untuk pasangan experiment dan key,
+// sehingga user tetap di arm yang sama selama experiment tidak diubah namanya
+func Bucket(experiment, key string, buckets uint) uint {
+	if buckets == 0 {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write([]byte(experiment))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return uint(h.Sum32()) % buckets
+}
+
+// Assign - memilih arm berdasarkan bobot traffic. Tanpa experiment dipakai DefaultArm.
+func Assign(experiment *models.RecommendationExperiment, key string) Assignment {
+	if experiment == nil || len(experiment.Arms) == 0 {
+		arm := DefaultArm()
+		return Assignment{Arm: arm.Name, Mix: arm}
+	}
+
+	var total uint
+	for _, arm := range experiment.Arms {
+		total += arm.Traffic
+	}
+	bucket := Bucket(experiment.Name, key, total)
+	for _, arm := range experiment.Arms {
+		if bucket < arm.Traffic {
+			return Assignment{Experiment: experiment.Name, Arm: arm.Name, Mix: arm}
+		}
+		bucket -= arm.Traffic
+	}
+
+	arm := experiment.Arms[len(experiment.Arms)-1]
+	return Assignment{Experiment: experiment.Name, Arm: arm.Name, Mix: arm}
+}
+
+// LoadActiveExperiment - experiment aktif (yang terakhir diubah jika lebih dari satu), nil jika tidak ada
+func LoadActiveExperiment(db *gorm.DB) (*models.RecommendationExperiment, error) {
+	var experiment models.RecommendationExperiment
+	err := db.Where("active = ?", true).Order("updated_at DESC, id DESC").First(&experiment).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+// ValidateExperiment - nama arm unik, traffic positif dan strategi terdaftar
+func ValidateExperiment(experiment models.RecommendationExperiment) error {
+	if strings.TrimSpace(experiment.Name) == "" {
+		return errors.New("experiment name is required")
+	}
+	if len(experiment.Arms) == 0 {
+		return errors.New("experiment needs at least one arm")
+	}
+	names := make(map[string]bool)
+	for _, arm := range experiment.Arms {
+		if strings.TrimSpace(arm.Name) == "" {
+			return errors.New("arm name is required")
+		}
+		if names[arm.Name] {
+			return fmt.Errorf("duplicate arm %q", arm.Name)
+		}
+		names[arm.Name] = true
+		if arm.Traffic == 0 {
+			return fmt.Errorf("arm %q traffic must be positive", arm.Name)
+		}
+		if err := ValidateArm(arm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+// Package recommend - strategi rekomendasi produk yang bisa dipasang-lepas.
+//
+// Setiap strategi mengimplementasikan Recommender dan didaftarkan ke registry dengan nama.
+// Compose mencampur beberapa strategi berbobot (ExperimentArm) menjadi satu daftar tanpa
+// duplikat, dan Assign membagi user ke arm experiment yang sedang aktif.
+package recommend
+
+import (
+	"ecommerce-golang/models"
+	"fmt"
+	"gorm.io/gorm"
+	"sort"
+	"sync"
+)
+
+// Request - konteks satu permintaan rekomendasi
+type Request struct {
+	UserID          uint
+	IsAuthenticated bool
+	VisitorID       string // pengunjung anonim (cookie visitor_id), kosong jika tidak ada
+	Limit           int
+}
+
+// Candidates - hasil satu strategi. Products kosong berarti strategi tidak berlaku untuk user ini.
+type Candidates struct {
+	Products   []models.ProductListView
+	Basis      string   // nilai recommendation_basis jika strategi ini paling banyak menyumbang
+	Categories []string // kategori yang dipakai, untuk metadata categories_used
+}
+
+type Recommender interface {
+	Name() string
+	Recommend(db *gorm.DB, req Request) Candidates
+}
+
+// Func - adapter supaya fungsi biasa bisa dipakai sebagai Recommender
+type Func struct {
+	StrategyName string
+	Fn           func(db *gorm.DB, req Request) Candidates
+}
+
+func (f Func) Name() string { return f.StrategyName }
+
+func (f Func) Recommend(db *gorm.DB, req Request) Candidates { return f.Fn(db, req) }
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Recommender)
+)
+
+// Register - mendaftarkan strategi. Nama yang sama menggantikan strategi sebelumnya.
+func Register(r Recommender) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[r.Name()] = r
+}
+
+func Lookup(name string) (Recommender, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	r, ok := registry[name]
+	return r, ok
+}
+
+// Names - nama semua strategi terdaftar, urut abjad
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Chain - strategi pertama yang menghasilkan produk dipakai, sisanya tidak dijalankan
+func Chain(name string, strategies ...string) Recommender {
+	return Func{StrategyName: name, Fn: func(db *gorm.DB, req Request) Candidates {
+		for _, strategy := range strategies {
+			r, ok := Lookup(strategy)
+			if !ok {
+				continue
+			}
+			if candidates := r.Recommend(db, req); len(candidates.Products) > 0 {
+				return candidates
+			}
+		}
+		return Candidates{}
+	}}
+}
+
+// ValidateArm - semua strategi harus terdaftar dan bobotnya positif
+func ValidateArm(arm models.ExperimentArm) error {
+	if len(arm.Strategies) == 0 {
+		return fmt.Errorf("arm %q has no strategies", arm.Name)
+	}
+	for _, strategy := range arm.Strategies {
+		if _, ok := Lookup(strategy.Name); !ok {
+			return fmt.Errorf("arm %q: unknown strategy %q", arm.Name, strategy.Name)
+		}
+		if strategy.Weight <= 0 {
+			return fmt.Errorf("arm %q: strategy %q weight must be positive", arm.Name, strategy.Name)
+		}
+		if strategy.Limit < 0 {
+			return fmt.Errorf("arm %q: strategy %q limit must not be negative", arm.Name, strategy.Name)
+		}
+	}
+	if arm.MaxPerShop < 0 || arm.MaxPerCategory < 0 {
+		return fmt.Errorf("arm %q: diversity limits must not be negative", arm.Name)
+	}
+	return nil
+}
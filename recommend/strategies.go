@@ -0,0 +1,130 @@
+// recommend/strategies.go - strategi bawaan, dibungkus dari query di utils
+package recommend
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/utils"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Nama strategi bawaan
+const (
+	StrategyBestseller      = "bestseller"
+	StrategyPurchaseHistory = "purchase_history"
+	StrategyCart            = "cart"
+	StrategyWishlist        = "wishlist"
+	StrategyPersonal        = "personal" // purchase_history → cart → wishlist → bestseller
+	StrategyItemSimilarity  = "item_similarity"
+	StrategyFollowedShops   = "followed_shops"
+	StrategyRecentlyViewed  = "recently_viewed"
+)
+
+// Waktu paruh bobot view: view 3 hari lalu bernilai setengah view hari ini
+const viewHalfLife = 72 * time.Hour
+
+func init() {
+	Register(Func{StrategyName: StrategyBestseller, Fn: bestseller})
+	Register(Func{StrategyName: StrategyPurchaseHistory, Fn: purchaseHistory})
+	Register(Func{StrategyName: StrategyCart, Fn: cart})
+	Register(Func{StrategyName: StrategyWishlist, Fn: wishlist})
+	Register(Chain(StrategyPersonal, StrategyPurchaseHistory, StrategyCart, StrategyWishlist, StrategyBestseller))
+	Register(Func{StrategyName: StrategyItemSimilarity, Fn: itemSimilarity})
+	Register(Func{StrategyName: StrategyFollowedShops, Fn: followedShops})
+	Register(Func{StrategyName: StrategyRecentlyViewed, Fn: recentlyViewed})
+}
+
+// bestseller - produk trending lalu rating dan penjualan terbaik, untuk anonymous dan user baru
+func bestseller(db *gorm.DB, req Request) Candidates {
+	basis := "anonymous_bestseller_and_rating"
+	if req.IsAuthenticated {
+		basis = "new_user_bestseller_and_rating"
+	}
+	return Candidates{Products: utils.GetAnonymousRecommendations(db), Basis: basis}
+}
+
+func purchaseHistory(db *gorm.DB, req Request) Candidates {
+	if !req.IsAuthenticated || !utils.CheckUserHasPurchaseHistory(db, req.UserID) {
+		return Candidates{}
+	}
+	categories := utils.GetTopCategoriesByPurchase(db, req.UserID, 3)
+	return Candidates{
+		Products:   utils.GetCategoryBasedRecommendations(db, categories, req.UserID),
+		Basis:      "purchase_history_based",
+		Categories: categories,
+	}
+}
+
+func cart(db *gorm.DB, req Request) Candidates {
+	if !req.IsAuthenticated || !utils.CheckUserHasCartItems(db, req.UserID) {
+		return Candidates{}
+	}
+	categories := utils.GetTopCategoriesByCart(db, req.UserID)
+	return Candidates{
+		Products:   utils.GetCategoryBasedRecommendations(db, categories, req.UserID),
+		Basis:      "cart_based",
+		Categories: categories,
+	}
+}
+
+func wishlist(db *gorm.DB, req Request) Candidates {
+	if !req.IsAuthenticated || !utils.CheckUserHasWishlistOrFollows(db, req.UserID) {
+		return Candidates{}
+	}
+	categories := utils.GetTopCategoriesByWishlist(db, req.UserID, 3)
+	if len(categories) == 0 {
+		return Candidates{}
+	}
+	return Candidates{
+		Products:   utils.GetCategoryBasedRecommendations(db, categories, req.UserID),
+		Basis:      "wishlist_and_following_based",
+		Categories: categories,
+	}
+}
+
+// itemSimilarity - produk yang sering dibeli bersama pembelian/keranjang user (product_neighbors)
+func itemSimilarity(db *gorm.DB, req Request) Candidates {
+	if !req.IsAuthenticated {
+		return Candidates{}
+	}
+	seeds := utils.GetUserSeedProducts(db, req.UserID, 20)
+	return Candidates{
+		Products: utils.GetItemSimilarityRecommendations(db, seeds, req.Limit),
+		Basis:    "item_similarity_blend",
+	}
+}
+
+func followedShops(db *gorm.DB, req Request) Candidates {
+	if !req.IsAuthenticated {
+		return Candidates{}
+	}
+	return Candidates{
+		Products: utils.GetFollowedShopRecommendations(db, req.UserID, req.Limit),
+		Basis:    "followed_shops",
+	}
+}
+
+// recentlyViewed - produk dari kategori yang sering dilihat akhir-akhir ini (bobot meluruh
+// terhadap waktu), tanpa produk yang baru saja dilihat. Berlaku juga untuk visitor anonim.
+func recentlyViewed(db *gorm.DB, req Request) Candidates {
+	userID := uint(0)
+	if req.IsAuthenticated {
+		userID = req.UserID
+	}
+	categories := utils.GetTopCategoriesByViews(db, userID, req.VisitorID, time.Now(), viewHalfLife, 3)
+	if len(categories) == 0 {
+		return Candidates{}
+	}
+
+	viewed := make(map[uint]bool)
+	for _, id := range utils.GetRecentlyViewedProductIDs(db, userID, req.VisitorID, 20) {
+		viewed[id] = true
+	}
+	var products []models.ProductListView
+	for _, product := range utils.GetCategoryBasedRecommendations(db, categories, userID) {
+		if !viewed[product.ID] {
+			products = append(products, product)
+		}
+	}
+	return Candidates{Products: products, Basis: "recently_viewed_based", Categories: categories}
+}
@@ -0,0 +1,172 @@
+// regions/regions.go
+package regions
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Dataset wilayah bawaan (provinsi, kota/kabupaten dan rentang kode pos).
+// Bisa diganti dataset lengkap lewat REGION_DATA_FILE dengan format JSON yang sama.
+// Dataset bawaan memuat ke-38 provinsi tetapi baru sebagian kota/kabupaten, jadi kota yang tidak
+// dikenal dan kode pos di luar rentang hanya menjadi peringatan (Match.Warning). Set
+// REGION_VALIDATION=strict untuk menolaknya setelah memakai dataset lengkap.
+//
+//go:embed data/regions.json
+var embeddedRegions []byte
+
+var (
+	ErrUnknownProvince    = errors.New("unknown province")
+	ErrUnknownCity        = errors.New("unknown city/regency for this province")
+	ErrInvalidPostalCode  = errors.New("postal code must be 5 digits")
+	ErrPostalCodeMismatch = errors.New("postal code does not belong to this city/regency")
+)
+
+var postalCodePattern = regexp.MustCompile(`^[1-9][0-9]{4}$`)
+
+// PostalRange - rentang kode pos (inklusif)
+type PostalRange struct {
+	Min string `json:"min"`
+	Max string `json:"max"`
+}
+
+// City - kota atau kabupaten
+type City struct {
+	Name        string        `json:"name"`
+	Type        string        `json:"type"` // kota / kabupaten
+	PostalCodes []PostalRange `json:"postal_codes"`
+}
+
+// FullName - nama lengkap, contoh "Kota Bandung" atau "Kabupaten Bandung"
+func (c City) FullName() string {
+	if c.Type == "kabupaten" {
+		return "Kabupaten " + c.Name
+	}
+	return "Kota " + c.Name
+}
+
+func (c City) hasPostalCode(code string) bool {
+	for _, r := range c.PostalCodes {
+		if code >= r.Min && code <= r.Max {
+			return true
+		}
+	}
+	return false
+}
+
+type Province struct {
+	Name   string `json:"name"`
+	Cities []City `json:"cities"`
+}
+
+var (
+	loadOnce  sync.Once
+	provinces []Province
+	loadErr   error
+)
+
+// Provinces - seluruh dataset wilayah
+func Provinces() ([]Province, error) {
+	loadOnce.Do(func() {
+		data := embeddedRegions
+		if path := os.Getenv("REGION_DATA_FILE"); path != "" {
+			data, loadErr = os.ReadFile(path)
+			if loadErr != nil {
+				return
+			}
+		}
+		loadErr = json.Unmarshal(data, &provinces)
+	})
+	return provinces, loadErr
+}
+
+// Match - hasil validasi alamat dengan nama provinsi dan kota yang sudah dinormalisasi.
+// Warning terisi jika kota atau kode pos tidak bisa dicocokkan dengan dataset (mode non-strict).
+type Match struct {
+	Province string
+	City     string
+	Warning  string
+}
+
+func strictValidation() bool {
+	return os.Getenv("REGION_VALIDATION") == "strict"
+}
+
+// advisory - di mode non-strict kota/kode pos yang tidak dikenal tetap diterima dengan peringatan
+func advisory(match Match, err error) (Match, error) {
+	if strictValidation() {
+		return Match{}, err
+	}
+	match.Warning = err.Error()
+	return match, nil
+}
+
+// ValidateAddress - memastikan provinsi dan kota/kabupaten dikenal dan kode pos
+// termasuk di wilayah tersebut. Nama kota boleh ditulis "Bandung", "Kota Bandung" atau "Kab. Bandung".
+// Format kode pos dan provinsi selalu divalidasi; kota dan rentang kode pos lihat REGION_VALIDATION.
+func ValidateAddress(provinceName, cityName, postalCode string) (Match, error) {
+	if !postalCodePattern.MatchString(postalCode) {
+		return Match{}, ErrInvalidPostalCode
+	}
+
+	all, err := Provinces()
+	if err != nil {
+		return Match{}, fmt.Errorf("region dataset unavailable: %w", err)
+	}
+
+	var province *Province
+	for i := range all {
+		if normalize(all[i].Name) == normalize(provinceName) {
+			province = &all[i]
+			break
+		}
+	}
+	if province == nil {
+		return Match{}, ErrUnknownProvince
+	}
+
+	cityType, name := splitCityType(cityName)
+	var candidates []City
+	for _, city := range province.Cities {
+		if normalize(city.Name) == name && (cityType == "" || city.Type == cityType) {
+			candidates = append(candidates, city)
+		}
+	}
+	if len(candidates) == 0 {
+		return advisory(Match{Province: province.Name, City: strings.Join(strings.Fields(cityName), " ")}, ErrUnknownCity)
+	}
+
+	for _, city := range candidates {
+		if city.hasPostalCode(postalCode) {
+			return Match{Province: province.Name, City: city.FullName()}, nil
+		}
+	}
+	return advisory(Match{Province: province.Name, City: candidates[0].FullName()}, ErrPostalCodeMismatch)
+}
+
+// splitCityType - memisahkan prefix "kota"/"kabupaten" dari nama kota
+func splitCityType(city string) (cityType, name string) {
+	city = normalize(city)
+	for _, prefix := range []struct{ text, cityType string }{
+		{"kota administrasi ", "kota"},
+		{"kota ", "kota"},
+		{"kabupaten ", "kabupaten"},
+		{"kab. ", "kabupaten"},
+		{"kab ", "kabupaten"},
+	} {
+		if strings.HasPrefix(city, prefix.text) {
+			return prefix.cityType, strings.TrimPrefix(city, prefix.text)
+		}
+	}
+	return "", city
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
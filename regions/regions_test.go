@@ -0,0 +1,73 @@
+package regions
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProvincesCoverAllProvinces(t *testing.T) {
+	provinces, err := Provinces()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(provinces) != 38 {
+		t.Errorf("dataset has %d provinces, want 38", len(provinces))
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	tests := []struct {
+		name                 string
+		strict               bool
+		province, city, code string
+		want                 Match
+		wantErr              error
+	}{
+		{
+			name: "kota", province: "Jawa Barat", city: "Kota Bandung", code: "40115",
+			want: Match{Province: "Jawa Barat", City: "Kota Bandung"},
+		},
+		{
+			name: "kabupaten dari kode pos", province: "jawa  barat", city: "Bandung", code: "40375",
+			want: Match{Province: "Jawa Barat", City: "Kabupaten Bandung"},
+		},
+		{
+			name: "kode pos tidak valid", province: "Jawa Barat", city: "Bandung", code: "4011",
+			wantErr: ErrInvalidPostalCode,
+		},
+		{
+			name: "provinsi tidak dikenal", province: "Jawa Utara", city: "Bandung", code: "40115",
+			wantErr: ErrUnknownProvince,
+		},
+		{
+			name: "kota belum ada di dataset", province: "Aceh", city: "Kota  Banda Aceh", code: "23111",
+			want: Match{Province: "Aceh", City: "Kota Banda Aceh", Warning: ErrUnknownCity.Error()},
+		},
+		{
+			name: "kode pos di luar rentang", province: "DKI Jakarta", city: "Jakarta Pusat", code: "12110",
+			want: Match{Province: "DKI Jakarta", City: "Kota Jakarta Pusat", Warning: ErrPostalCodeMismatch.Error()},
+		},
+		{
+			name: "strict: kota belum ada di dataset", strict: true, province: "Aceh", city: "Banda Aceh", code: "23111",
+			wantErr: ErrUnknownCity,
+		},
+		{
+			name: "strict: kode pos di luar rentang", strict: true, province: "DKI Jakarta", city: "Jakarta Pusat", code: "12110",
+			wantErr: ErrPostalCodeMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.strict {
+				t.Setenv("REGION_VALIDATION", "strict")
+			}
+			got, err := ValidateAddress(tt.province, tt.city, tt.code)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("match = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
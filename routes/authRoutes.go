@@ -4,26 +4,21 @@ package routes
 import (
 	"ecommerce-golang/controllers"
 	"ecommerce-golang/middleware"
+	"ecommerce-golang/models"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 func AuthRoutes(r *gin.Engine, db *gorm.DB) {
-	// Initialize rate limiters
-	authLimiter := middleware.AuthRateLimiter()
-	searchLimiter := middleware.SearchRateLimiter()
-	moderateLimiter := middleware.ModerateRateLimiter()
-	relaxedLimiter := middleware.RelaxedRateLimiter()
-
-	// Start cleanup routines
-	authLimiter.StartCleanupRoutine()
-	searchLimiter.StartCleanupRoutine()
-	moderateLimiter.StartCleanupRoutine()
-	relaxedLimiter.StartCleanupRoutine()
+	// Rate limit per route dan tier dari file policy (ratelimit/data/policies.yaml atau
+	// RATE_LIMIT_POLICY_FILE), lihat GET /admin/rate-limit-policies
+	r.Use(middleware.PolicyRateLimitMiddleware())
+	// Batas request yang diproses bersamaan dengan prioritas checkout > cart > browse > recommendations,
+	// lihat GET /admin/load-shedding
+	r.Use(middleware.LoadSheddingMiddleware())
 
 	// User routes dengan rate limiting ketat untuk auth
 	user := r.Group("/user")
-	user.Use(authLimiter.TokenBucketMiddleware()) // Rate limit untuk auth endpoints
 	{
 		user.POST("/register", func(c *gin.Context) {
 			controllers.UserRegister(c)
@@ -35,7 +30,6 @@ func AuthRoutes(r *gin.Engine, db *gorm.DB) {
 
 	// Seller routes dengan rate limiting ketat untuk auth
 	seller := r.Group("/seller")
-	seller.Use(authLimiter.TokenBucketMiddleware()) // Rate limit untuk auth endpoints
 	{
 		seller.POST("/register", func(c *gin.Context) {
 			controllers.SellerRegister(c)
@@ -49,142 +43,224 @@ func AuthRoutes(r *gin.Engine, db *gorm.DB) {
 	product := r.Group("/product")
 	{
 		// Search dengan rate limit khusus
-		product.GET("", searchLimiter.TokenBucketMiddleware(), controllers.SearchProduct)
+		product.GET("", controllers.SearchProduct)
 
-		// Product detail dengan rate limit relaxed
-		product.GET("/:id", relaxedLimiter.TokenBucketMiddleware(), controllers.GetProductDetail)
+		// Product detail dengan rate limit relaxed dan optional auth untuk mencatat recently viewed
+		product.GET("/:id",
+			middleware.OptionalAuthMiddleware(),
+			controllers.GetProductDetail)
+
+		// "Customers also bought" dari job cmd/itemneighbors
+		product.GET("/:id/related", controllers.GetRelatedProducts)
+
+		// Trending 24h/7d, nasional atau per kota (kota user jika login)
+		product.GET("/trending",
+			middleware.OptionalAuthMiddleware(),
+			controllers.GetTrendingProducts)
 
 		// Recommendations dengan optional auth dan rate limit
 		product.GET("/recommendations",
 			middleware.OptionalAuthMiddleware(),
-			searchLimiter.TokenBucketMiddleware(),
 			controllers.GetRecommendations)
 	}
 
+	// Halaman toko, is_following diisi jika user login
+	r.GET("/shop/:seller_id",
+		middleware.OptionalAuthMiddleware(),
+		controllers.GetShop)
+
 	// Categories dengan rate limit relaxed
-	r.GET("/categories", relaxedLimiter.TokenBucketMiddleware(), controllers.GetCategories)
+	r.GET("/categories", controllers.GetCategories)
+
+	// Flash sale yang sedang dan akan berjalan
+	r.GET("/flash-sales", controllers.GetFlashSales)
+
+	// Data wilayah untuk form alamat
+	r.GET("/regions", controllers.GetRegions)
+
+	// Mata uang tampilan (?currency= / Accept-Currency)
+	r.GET("/currencies", controllers.GetCurrencies)
+
+	// Keranjang tamu (belum login), dikenali dari cookie guest_cart / header X-Guest-Cart.
+	// Isinya digabung ke keranjang user saat POST /user/login.
+	guestCart := r.Group("/guest/cart")
+	{
+		guestCart.GET("", controllers.GetGuestCart)
+		guestCart.POST("/product/:id", controllers.AddProductToGuestCart)
+		guestCart.PUT("/:item_id", controllers.UpdateGuestCartItem)
+		guestCart.DELETE("/:item_id", controllers.RemoveFromGuestCart)
+		guestCart.DELETE("", controllers.ClearGuestCart)
+	}
 
 	// Protected user routes dengan dynamic rate limiting berdasarkan role
 	userProtected := r.Group("/user")
 	userProtected.Use(middleware.AuthMiddleware("user"))
-	userProtected.Use(middleware.DynamicRoleBasedMiddleware()) // Dynamic rate limit
 	{
 		userProtected.GET("/me", controllers.UserMe)
 		userProtected.GET("/profile", controllers.GetUserProfile)
 		userProtected.PUT("/profile", controllers.UpdateUserProfile)
+		userProtected.POST("/profile/photo", controllers.UploadUserPhoto)
+
+		// Address book
+		userProtected.GET("/addresses", controllers.GetUserAddresses)
+		userProtected.POST("/addresses", controllers.CreateUserAddress)
+		userProtected.GET("/addresses/:address_id", controllers.GetUserAddress)
+		userProtected.PUT("/addresses/:address_id", controllers.UpdateUserAddress)
+		userProtected.PUT("/addresses/:address_id/default", controllers.SetDefaultUserAddress)
+		userProtected.DELETE("/addresses/:address_id", controllers.DeleteUserAddress)
 
 		//Cart endpoint
 		userProtected.GET("/cart", controllers.GetUserCart)
+		userProtected.GET("/cart/shipping-quotes", controllers.GetCartShippingQuotes)
 		userProtected.POST("/cart/product/:id", controllers.AddProductToCart)
 		userProtected.PUT("/cart/:cart_id", controllers.UpdateCartItem)
 		userProtected.DELETE("/cart/:cart_id", controllers.RemoveFromCart)
 		userProtected.DELETE("/cart", controllers.ClearCart)
+		userProtected.POST("/cart/refresh", controllers.RefreshCart)
+		userProtected.PUT("/cart/selection", controllers.UpdateCartSelection)
+		userProtected.PUT("/cart/:cart_id/note", controllers.UpdateCartItemNote)
+		userProtected.POST("/cart/:cart_id/save-for-later", controllers.SaveCartItemForLater)
+		userProtected.POST("/cart/:cart_id/move-to-cart", controllers.MoveCartItemToCart)
+		userProtected.POST("/cart/apply-voucher", controllers.ApplyCartVoucher)
+		userProtected.DELETE("/cart/voucher", controllers.RemoveCartVoucher)
+		userProtected.POST("/checkout", controllers.Checkout)
+		userProtected.POST("/flash-sales/:flash_sale_id/reserve", controllers.ReserveFlashSale)
+
+		// Wishlist dan koleksi
+		userProtected.GET("/wishlist", controllers.GetUserWishlist)
+		userProtected.POST("/wishlist", controllers.AddToWishlist)
+		userProtected.PUT("/wishlist/:item_id", controllers.MoveWishlistItem)
+		userProtected.DELETE("/wishlist/:item_id", controllers.RemoveFromWishlist)
+		userProtected.GET("/wishlist/collections", controllers.GetWishlistCollections)
+		userProtected.POST("/wishlist/collections", controllers.CreateWishlistCollection)
+		userProtected.PUT("/wishlist/collections/:collection_id", controllers.RenameWishlistCollection)
+		userProtected.DELETE("/wishlist/collections/:collection_id", controllers.DeleteWishlistCollection)
+
+		// Produk yang terakhir dilihat
+		userProtected.GET("/recently-viewed", controllers.GetRecentlyViewed)
+		userProtected.DELETE("/recently-viewed", controllers.ClearRecentlyViewed)
+		userProtected.DELETE("/recently-viewed/:product_id", controllers.RemoveRecentlyViewed)
+		userProtected.PUT("/recently-viewed/settings", controllers.UpdateViewHistorySettings)
+
+		// Follow toko
+		userProtected.GET("/following", controllers.GetFollowedShops)
+		userProtected.POST("/shops/:seller_id/follow", controllers.FollowShop)
+		userProtected.DELETE("/shops/:seller_id/follow", controllers.UnfollowShop)
+
+		// Shipment tracking
+		userProtected.GET("/shipments", controllers.GetUserShipments)
+		userProtected.GET("/shipments/:shipment_id", controllers.GetUserShipment)
+		userProtected.POST("/shipments/:shipment_id/complete", controllers.CompleteUserShipment)
+
+		// Invoice (?format=html / ?format=pdf untuk download)
+		userProtected.GET("/invoices", controllers.GetUserInvoices)
+		userProtected.GET("/invoices/:invoice_id", controllers.GetUserInvoice)
 	}
 
 	// Protected seller routes dengan dynamic rate limiting
 	sellerProtected := r.Group("/seller")
 	sellerProtected.Use(middleware.AuthMiddleware("seller"))
-	sellerProtected.Use(middleware.DynamicRoleBasedMiddleware()) // Dynamic rate limit
 	{
 		sellerProtected.GET("/me", controllers.SellerMe)
 		sellerProtected.GET("/profile", controllers.GetSellerProfile)
 		sellerProtected.PUT("/profile", controllers.UpdateSellerProfile)
+		sellerProtected.POST("/profile/logo", controllers.UploadShopLogo)
+		sellerProtected.PUT("/profile/tax", controllers.UpdateSellerTaxProfile)
+		sellerProtected.PUT("/profile/holiday", controllers.UpdateSellerHoliday)
 
 		// Seller product management dengan rate limit moderate
-		sellerProtected.POST("/products", moderateLimiter.TokenBucketMiddleware(), controllers.CreateProduct)
-		sellerProtected.GET("/products", relaxedLimiter.TokenBucketMiddleware(), controllers.GetSellerProducts)
-		sellerProtected.GET("/products/:id", relaxedLimiter.TokenBucketMiddleware(), controllers.GetSellerProduct)
-		sellerProtected.PUT("/products/:id", moderateLimiter.TokenBucketMiddleware(), controllers.UpdateProduct)
-		sellerProtected.DELETE("/products/:id", moderateLimiter.TokenBucketMiddleware(), controllers.DeleteProduct)
-	}
-}
+		sellerProtected.POST("/products", controllers.CreateProduct)
+		sellerProtected.GET("/products", controllers.GetSellerProducts)
+		sellerProtected.GET("/products/:id", controllers.GetSellerProduct)
+		sellerProtected.PUT("/products/:id", controllers.UpdateProduct)
+		sellerProtected.DELETE("/products/:id", controllers.DeleteProduct)
+		sellerProtected.POST("/products/:id/images", controllers.UploadProductImage)
 
-// Alternative: Simpler rate limiting setup
-func SimpleAuthRoutes(r *gin.Engine, db *gorm.DB) {
-	// Single global rate limiter
-	globalLimiter := middleware.ModerateRateLimiter()
-	globalLimiter.StartCleanupRoutine()
+		// Bulk import/export produk (CSV/XLSX)
+		sellerProtected.POST("/products/import", controllers.ImportSellerProducts)
+		sellerProtected.GET("/products/import/:job_id", controllers.GetProductImportJob)
+		sellerProtected.GET("/products/export", controllers.ExportSellerProducts)
 
-	// Apply global rate limiting
-	r.Use(globalLimiter.TokenBucketMiddleware())
+		// Pesanan masuk
+		sellerProtected.GET("/orders", controllers.GetSellerOrders)
 
-	// Rest of routes without additional rate limiting...
-	// (sama seperti route sebelumnya tapi tanpa middleware tambahan)
-}
+		// Shipment dan nomor resi
+		sellerProtected.POST("/shipments", controllers.CreateShipment)
+		sellerProtected.GET("/shipments", controllers.GetSellerShipments)
+		sellerProtected.GET("/shipments/:shipment_id", controllers.GetSellerShipment)
+		sellerProtected.POST("/shipments/:shipment_id/refresh-tracking", controllers.RefreshShipmentTracking)
+		sellerProtected.POST("/shipments/:shipment_id/invoice", controllers.IssueSellerInvoice)
 
-// Advanced: Custom rate limiting per endpoint
-func AdvancedAuthRoutes(r *gin.Engine, db *gorm.DB) {
-	// Different rate limiters for different needs
-	authLimiter := middleware.AuthRateLimiter()     // 5/min untuk auth
-	searchLimiter := middleware.SearchRateLimiter() // 30/min untuk search
-	crudLimiter := middleware.ModerateRateLimiter() // 60/min untuk CRUD
-	readLimiter := middleware.RelaxedRateLimiter()  // 120/min untuk read
-
-	// Start cleanup
-	authLimiter.StartCleanupRoutine()
-	searchLimiter.StartCleanupRoutine()
-	crudLimiter.StartCleanupRoutine()
-	readLimiter.StartCleanupRoutine()
-
-	// Auth endpoints - very strict
-	auth := r.Group("/auth")
-	auth.Use(authLimiter.SlidingWindowMiddleware()) // Menggunakan sliding window untuk auth
-	{
-		auth.POST("/user/register", controllers.UserRegister)
-		auth.POST("/user/login", controllers.UserLogin)
-		auth.POST("/seller/register", controllers.SellerRegister)
-		auth.POST("/seller/login", controllers.SellerLogin)
-	}
+		// Invoice (?format=html / ?format=pdf untuk download)
+		sellerProtected.GET("/invoices", controllers.GetSellerInvoices)
+		sellerProtected.GET("/invoices/:invoice_id", controllers.GetSellerInvoice)
 
-	// Public read endpoints - relaxed
-	public := r.Group("/public")
-	public.Use(readLimiter.TokenBucketMiddleware())
-	{
-		public.GET("/products", controllers.SearchProduct)
-		public.GET("/products/:id", controllers.GetProductDetail)
-		public.GET("/categories", controllers.GetCategories)
+		// Voucher toko
+		sellerProtected.POST("/vouchers", controllers.CreateSellerVoucher)
+		sellerProtected.GET("/vouchers", controllers.GetSellerVouchers)
+
+		// Flash sale
+		sellerProtected.POST("/flash-sales", controllers.CreateSellerFlashSale)
+		sellerProtected.GET("/flash-sales", controllers.GetSellerFlashSales)
+		sellerProtected.DELETE("/flash-sales/:flash_sale_id", controllers.CancelSellerFlashSale)
+
+		// API key untuk integrasi ERP
+		sellerProtected.POST("/api-keys", controllers.CreateSellerAPIKey)
+		sellerProtected.GET("/api-keys", controllers.GetSellerAPIKeys)
+		sellerProtected.DELETE("/api-keys/:key_id", controllers.RevokeSellerAPIKey)
 	}
 
-	// Search endpoints - moderate
-	search := r.Group("/search")
-	search.Use(middleware.OptionalAuthMiddleware())
-	search.Use(searchLimiter.TokenBucketMiddleware())
+	// Admin routes, autentikasi dengan ADMIN_TOKEN
+	admin := r.Group("/admin")
+	admin.Use(middleware.AdminTokenMiddleware())
 	{
-		search.GET("/products", controllers.SearchProduct)
-		search.GET("/recommendations", controllers.GetRecommendations)
+		admin.POST("/vouchers", controllers.CreatePlatformVoucher)
+		admin.GET("/vouchers", controllers.GetPlatformVouchers)
+		admin.POST("/flash-sales", controllers.CreateAdminFlashSale)
+
+		// Tarif PPN per kategori
+		admin.GET("/tax-rules", controllers.GetTaxRules)
+		admin.PUT("/tax-rules", controllers.UpsertTaxRule)
+		admin.DELETE("/tax-rules/:rule_id", controllers.DeleteTaxRule)
+
+		// A/B experiment strategi rekomendasi
+		admin.GET("/recommendation-experiments", controllers.GetRecommendationExperiments)
+		admin.PUT("/recommendation-experiments", controllers.UpsertRecommendationExperiment)
+		admin.DELETE("/recommendation-experiments/:experiment_id", controllers.DeleteRecommendationExperiment)
+
+		// Policy rate limit yang berlaku (RATE_LIMIT_POLICY_FILE)
+		admin.GET("/rate-limit-policies", controllers.GetRateLimitPolicies)
+		admin.POST("/rate-limit-policies/reload", controllers.ReloadRateLimitPolicies)
+
+		// Observability rate limit: counter, top key yang kena limit, inspect/reset key
+		admin.GET("/rate-limits/stats", controllers.GetRateLimitStats)
+		admin.GET("/rate-limits/keys", controllers.InspectRateLimitKey)
+		admin.DELETE("/rate-limits/keys", controllers.ResetRateLimitKey)
+
+		// Limit concurrency adaptif, antrian dan jumlah request yang dibuang per kelas
+		admin.GET("/load-shedding", controllers.GetLoadSheddingStats)
 	}
 
-	// Protected endpoints with role-based rate limiting
-	protected := r.Group("/api")
-	protected.Use(middleware.AuthMiddleware("user,seller")) // Accept both roles
-	protected.Use(middleware.DynamicRoleBasedMiddleware())
+	// Webhook tracking dari kurir, autentikasi lewat signature di masing-masing tracker
+	r.POST("/webhooks/couriers/:courier", controllers.CourierWebhook)
+
+	// Integration routes untuk seller yang sync dari ERP, autentikasi dengan X-API-Key
+	// Rate limit dihitung per API key
+	integration := r.Group("/integration")
+	integration.Use(middleware.APIKeyAuthMiddleware())
 	{
-		// User endpoints
-		userGroup := protected.Group("/user")
-		{
-			userGroup.GET("/me", controllers.UserMe)
-			userGroup.GET("/profile", controllers.GetUserProfile)
-			userGroup.PUT("/profile", controllers.UpdateUserProfile)
-
-			//cart end points
-			userGroup.GET("/cart", controllers.GetUserCart)
-			userGroup.POST("/cart/product/:id", controllers.AddProductToCart)
-			userGroup.PUT("/cart/:cart_id", controllers.UpdateCartItem)
-			userGroup.DELETE("/cart/:cart_id", controllers.RemoveFromCart)
-			userGroup.DELETE("/cart", controllers.ClearCart)
-		}
-
-		// Seller endpoints
-		sellerGroup := protected.Group("/seller")
-		{
-			sellerGroup.GET("/me", controllers.SellerMe)
-			sellerGroup.GET("/profile", controllers.GetSellerProfile)
-			sellerGroup.PUT("/profile", controllers.UpdateSellerProfile)
-			sellerGroup.POST("/products", controllers.CreateProduct)
-			sellerGroup.GET("/products", controllers.GetSellerProducts)
-			sellerGroup.GET("/products/:id", controllers.GetSellerProduct)
-			sellerGroup.PUT("/products/:id", controllers.UpdateProduct)
-			sellerGroup.DELETE("/products/:id", controllers.DeleteProduct)
-		}
+		productsRead := middleware.RequireAPIKeyScope(models.APIKeyScopeProductsRead)
+		productsWrite := middleware.RequireAPIKeyScope(models.APIKeyScopeProductsWrite)
+		ordersRead := middleware.RequireAPIKeyScope(models.APIKeyScopeOrdersRead)
+
+		integration.GET("/products", productsRead, controllers.GetSellerProducts)
+		integration.GET("/products/:id", productsRead, controllers.GetSellerProduct)
+		integration.POST("/products", productsWrite, controllers.CreateProduct)
+		integration.PUT("/products/:id", productsWrite, controllers.UpdateProduct)
+		integration.PATCH("/products/:id/stock", productsWrite, controllers.UpdateProductStock)
+		integration.POST("/products/import", productsWrite, controllers.ImportSellerProducts)
+		integration.GET("/products/import/:job_id", productsRead, controllers.GetProductImportJob)
+		integration.GET("/products/export", productsRead, controllers.ExportSellerProducts)
+		integration.GET("/orders", ordersRead, controllers.GetSellerOrders)
 	}
 }
@@ -0,0 +1,70 @@
+// shipping/dimensions.go
+package shipping
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Pembagi volumetrik standar kurir Indonesia: (P x L x T cm) / 6000 = kg
+const VolumetricDivisor = 6000.0
+
+var (
+	dimensionNumber = regexp.MustCompile(`\d+(?:[.,]\d+)?`)
+	dimensionUnit   = regexp.MustCompile(`\d\s*(mm|cm|m)\b`)
+)
+
+// ParseDimensions - parsing teks dimensi bebas seperti "30 20 10", "30x20x10 cm"
+// atau "30 x 20 x 10" menjadi panjang, lebar, tinggi dalam cm
+func ParseDimensions(text string) (length, width, height float64, ok bool) {
+	text = strings.ToLower(text)
+	matches := dimensionNumber.FindAllString(text, -1)
+	if len(matches) != 3 {
+		return 0, 0, 0, false
+	}
+
+	values := make([]float64, 3)
+	for i, match := range matches {
+		value, err := strconv.ParseFloat(strings.Replace(match, ",", ".", 1), 64)
+		if err != nil || value <= 0 {
+			return 0, 0, 0, false
+		}
+		values[i] = value
+	}
+
+	// Konversi kalau seller menulis dalam mm atau meter, default cm
+	if unit := dimensionUnit.FindStringSubmatch(text); unit != nil {
+		switch unit[1] {
+		case "mm":
+			for i := range values {
+				values[i] /= 10
+			}
+		case "m":
+			for i := range values {
+				values[i] *= 100
+			}
+		}
+	}
+
+	return values[0], values[1], values[2], true
+}
+
+// FormatDimensions - format standar "panjang lebar tinggi" dalam cm
+func FormatDimensions(length, width, height float64) string {
+	format := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return format(length) + " " + format(width) + " " + format(height)
+}
+
+// VolumetricWeightGrams - berat volumetrik dalam gram dari dimensi cm
+func VolumetricWeightGrams(length, width, height float64) float64 {
+	return length * width * height / VolumetricDivisor * 1000
+}
+
+// ChargeableWeightGrams - berat yang ditagihkan kurir: terbesar antara berat aktual dan volumetrik
+func ChargeableWeightGrams(actualGrams, volumetricGrams float64) float64 {
+	return math.Max(actualGrams, volumetricGrams)
+}
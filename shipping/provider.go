@@ -0,0 +1,46 @@
+// shipping/provider.go
+package shipping
+
+import (
+	"context"
+	"ecommerce-golang/money"
+	"strings"
+)
+
+// Package - satu paket kiriman (semua item dari satu seller)
+type Package struct {
+	OriginCity      string
+	DestinationCity string
+	WeightGrams     float64 // berat aktual
+	VolumetricGrams float64 // berat volumetrik total
+}
+
+// ChargeableGrams - berat yang dipakai untuk menghitung ongkir
+func (p Package) ChargeableGrams() float64 {
+	return ChargeableWeightGrams(p.WeightGrams, p.VolumetricGrams)
+}
+
+// Quote - satu opsi pengiriman dari kurir
+type Quote struct {
+	Courier    string      `json:"courier"`
+	Service    string      `json:"service"`
+	Price      money.Money `json:"price"`
+	EtaMinDays int         `json:"eta_min_days"`
+	EtaMaxDays int         `json:"eta_max_days"`
+}
+
+// ShippingProvider - sumber tarif ongkir. Implementasi bisa berupa tabel tarif lokal
+// atau API agregator kurir.
+type ShippingProvider interface {
+	Name() string
+	Quote(ctx context.Context, pkg Package) ([]Quote, error)
+}
+
+// NormalizeCity - menyamakan penulisan nama kota ("Kota Bandung", "KAB. BANDUNG " -> "bandung")
+func NormalizeCity(city string) string {
+	city = strings.ToLower(strings.TrimSpace(city))
+	for _, prefix := range []string{"kota administrasi ", "kota ", "kabupaten ", "kab. ", "kab "} {
+		city = strings.TrimPrefix(city, prefix)
+	}
+	return strings.Join(strings.Fields(city), " ")
+}
@@ -0,0 +1,183 @@
+// shipping/tableRate.go
+package shipping
+
+import (
+	"context"
+	"ecommerce-golang/money"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+)
+
+// Zona pengiriman berdasarkan kota asal dan tujuan
+const (
+	ZoneSameCity  = "same_city"
+	ZoneInterCity = "inter_city"
+)
+
+var ErrNoRate = errors.New("no shipping rate available for this route")
+
+// WeightTier - harga flat sampai berat MaxKg
+type WeightTier struct {
+	MaxKg int     `json:"max_kg"`
+	Price float64 `json:"price"`
+}
+
+// Rate - tarif satu layanan kurir untuk satu zona
+type Rate struct {
+	Courier    string       `json:"courier"`
+	Service    string       `json:"service"`
+	Zone       string       `json:"zone"`
+	Tiers      []WeightTier `json:"tiers"`        // urut berdasarkan MaxKg
+	ExtraPerKg float64      `json:"extra_per_kg"` // per kg di atas tier terakhir
+	EtaMinDays int          `json:"eta_min_days"`
+	EtaMaxDays int          `json:"eta_max_days"`
+}
+
+// TableRateProvider - ShippingProvider berbasis tabel tarif lokal
+type TableRateProvider struct {
+	Rates []Rate
+	// ZoneFunc - menentukan zona dari kota asal dan tujuan, default ZoneByCity
+	ZoneFunc func(origin, destination string) string
+}
+
+func NewTableRateProvider(rates []Rate) *TableRateProvider {
+	for i := range rates {
+		sort.Slice(rates[i].Tiers, func(a, b int) bool {
+			return rates[i].Tiers[a].MaxKg < rates[i].Tiers[b].MaxKg
+		})
+	}
+	return &TableRateProvider{
+		Rates:    rates,
+		ZoneFunc: ZoneByCity,
+	}
+}
+
+// LoadTableRates - membaca tabel tarif dari file JSON (array of Rate)
+func LoadTableRates(path string) ([]Rate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rates []Rate
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, err
+	}
+	if len(rates) == 0 {
+		return nil, errors.New("shipping rates file has no rates")
+	}
+	for i, rate := range rates {
+		if rate.Courier == "" || rate.Service == "" || len(rate.Tiers) == 0 {
+			return nil, fmt.Errorf("rate %d: courier, service and tiers are required", i+1)
+		}
+		if rate.Zone != ZoneSameCity && rate.Zone != ZoneInterCity {
+			return nil, fmt.Errorf("rate %d: unknown zone %q (%s, %s)", i+1, rate.Zone, ZoneSameCity, ZoneInterCity)
+		}
+	}
+	return rates, nil
+}
+
+// ZoneByCity - same_city jika kota asal dan tujuan sama, selain itu inter_city
+func ZoneByCity(origin, destination string) string {
+	if NormalizeCity(origin) == NormalizeCity(destination) {
+		return ZoneSameCity
+	}
+	return ZoneInterCity
+}
+
+func (p *TableRateProvider) Name() string {
+	return "table_rate"
+}
+
+func (p *TableRateProvider) Quote(ctx context.Context, pkg Package) ([]Quote, error) {
+	if pkg.OriginCity == "" || pkg.DestinationCity == "" {
+		return nil, errors.New("origin and destination city are required")
+	}
+
+	zone := p.ZoneFunc(pkg.OriginCity, pkg.DestinationCity)
+	kg := chargeableKg(pkg.ChargeableGrams())
+
+	var quotes []Quote
+	for _, rate := range p.Rates {
+		if rate.Zone != zone || len(rate.Tiers) == 0 {
+			continue
+		}
+		quotes = append(quotes, Quote{
+			Courier:    rate.Courier,
+			Service:    rate.Service,
+			Price:      money.FromFloat(rate.priceFor(kg)),
+			EtaMinDays: rate.EtaMinDays,
+			EtaMaxDays: rate.EtaMaxDays,
+		})
+	}
+
+	if len(quotes) == 0 {
+		return nil, ErrNoRate
+	}
+
+	sort.Slice(quotes, func(i, j int) bool {
+		return quotes[i].Price.Amount < quotes[j].Price.Amount
+	})
+	return quotes, nil
+}
+
+func (r Rate) priceFor(kg int) float64 {
+	for _, tier := range r.Tiers {
+		if kg <= tier.MaxKg {
+			return tier.Price
+		}
+	}
+	last := r.Tiers[len(r.Tiers)-1]
+	return last.Price + float64(kg-last.MaxKg)*r.ExtraPerKg
+}
+
+// chargeableKg - dibulatkan ke atas per kg, minimal 1 kg
+func chargeableKg(grams float64) int {
+	kg := int(math.Ceil(grams / 1000))
+	if kg < 1 {
+		kg = 1
+	}
+	return kg
+}
+
+// DefaultRates - tabel tarif bawaan, bisa diganti lewat SHIPPING_RATES_FILE
+func DefaultRates() []Rate {
+	return []Rate{
+		{Courier: "JNE", Service: "REG", Zone: ZoneSameCity, Tiers: []WeightTier{{1, 9000}, {3, 15000}, {5, 22000}}, ExtraPerKg: 4000, EtaMinDays: 1, EtaMaxDays: 2},
+		{Courier: "JNE", Service: "REG", Zone: ZoneInterCity, Tiers: []WeightTier{{1, 18000}, {3, 45000}, {5, 72000}}, ExtraPerKg: 14000, EtaMinDays: 2, EtaMaxDays: 4},
+		{Courier: "JNE", Service: "YES", Zone: ZoneSameCity, Tiers: []WeightTier{{1, 15000}, {3, 27000}, {5, 40000}}, ExtraPerKg: 7000, EtaMinDays: 1, EtaMaxDays: 1},
+		{Courier: "JNE", Service: "YES", Zone: ZoneInterCity, Tiers: []WeightTier{{1, 32000}, {3, 85000}, {5, 135000}}, ExtraPerKg: 26000, EtaMinDays: 1, EtaMaxDays: 1},
+		{Courier: "SiCepat", Service: "REG", Zone: ZoneSameCity, Tiers: []WeightTier{{1, 8000}, {3, 14000}, {5, 20000}}, ExtraPerKg: 3500, EtaMinDays: 1, EtaMaxDays: 2},
+		{Courier: "SiCepat", Service: "REG", Zone: ZoneInterCity, Tiers: []WeightTier{{1, 17000}, {3, 42000}, {5, 68000}}, ExtraPerKg: 13000, EtaMinDays: 2, EtaMaxDays: 3},
+		{Courier: "J&T", Service: "EZ", Zone: ZoneSameCity, Tiers: []WeightTier{{1, 9000}, {3, 16000}, {5, 23000}}, ExtraPerKg: 4000, EtaMinDays: 1, EtaMaxDays: 2},
+		{Courier: "J&T", Service: "EZ", Zone: ZoneInterCity, Tiers: []WeightTier{{1, 19000}, {3, 46000}, {5, 74000}}, ExtraPerKg: 14500, EtaMinDays: 2, EtaMaxDays: 5},
+	}
+}
+
+var (
+	defaultProvider     ShippingProvider
+	defaultProviderOnce sync.Once
+)
+
+// DefaultProvider - provider yang dipakai controller. Tabel tarif dari SHIPPING_RATES_FILE
+// dipasang saat startup lewat SetDefaultProvider (config.ConnectShippingRates), selain itu
+// memakai DefaultRates.
+func DefaultProvider() ShippingProvider {
+	defaultProviderOnce.Do(func() {
+		if defaultProvider != nil {
+			return
+		}
+		defaultProvider = NewTableRateProvider(DefaultRates())
+	})
+	return defaultProvider
+}
+
+// SetDefaultProvider - mengganti provider default (misalnya ke API agregator kurir),
+// dipanggil saat startup sebelum server menerima request
+func SetDefaultProvider(provider ShippingProvider) {
+	defaultProvider = provider
+}
@@ -0,0 +1,169 @@
+// shipping/tracking.go
+package shipping
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownCourier   = errors.New("unknown courier")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// TrackingEvent - event tracking yang sudah dinormalisasi dari format kurir.
+// Status memakai nilai models.Shipment* (picked_up, in_transit, delivered, ...).
+type TrackingEvent struct {
+	ExternalID  string    `json:"id"`
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	Location    string    `json:"location"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// EventID - ID event; jika kurir tidak mengirim ID, dibuat dari isi event
+// supaya event yang sama selalu menghasilkan ID yang sama (idempotent)
+func (e TrackingEvent) EventID() string {
+	if e.ExternalID != "" {
+		return e.ExternalID
+	}
+	sum := sha256.Sum256([]byte(e.Status + "|" + e.OccurredAt.UTC().Format(time.RFC3339Nano) + "|" + e.Description + "|" + e.Location))
+	return hex.EncodeToString(sum[:16])
+}
+
+// CourierTracker - integrasi tracking satu kurir: pull (Track) dan push (webhook)
+type CourierTracker interface {
+	Courier() string
+	Track(ctx context.Context, awb string) ([]TrackingEvent, error)
+	// ParseWebhook - verifikasi dan parsing payload webhook, mengembalikan AWB dan event-nya
+	ParseWebhook(r *http.Request) (awb string, events []TrackingEvent, err error)
+}
+
+var (
+	trackersMu sync.RWMutex
+	trackers   = make(map[string]CourierTracker)
+)
+
+// RegisterTracker - mendaftarkan tracker, dipanggil saat startup
+func RegisterTracker(tracker CourierTracker) {
+	trackersMu.Lock()
+	defer trackersMu.Unlock()
+	trackers[strings.ToLower(tracker.Courier())] = tracker
+}
+
+// GetTracker - tracker untuk kurir tertentu (case-insensitive)
+func GetTracker(courier string) (CourierTracker, error) {
+	trackersMu.RLock()
+	defer trackersMu.RUnlock()
+	tracker, ok := trackers[strings.ToLower(courier)]
+	if !ok {
+		return nil, ErrUnknownCourier
+	}
+	return tracker, nil
+}
+
+// webhookPayload - format webhook ternormalisasi (dipakai agregator tracking dan FakeTracker)
+type webhookPayload struct {
+	AWB    string          `json:"awb"`
+	Events []TrackingEvent `json:"events"`
+}
+
+// WebhookTracker - tracker untuk kurir/agregator yang mengirim payload ternormalisasi
+// dengan signature HMAC-SHA256 dari body di header X-Tracking-Signature.
+// Track tidak didukung karena data hanya datang lewat webhook.
+type WebhookTracker struct {
+	CourierName string
+	Secret      string
+}
+
+func (t *WebhookTracker) Courier() string {
+	return t.CourierName
+}
+
+func (t *WebhookTracker) Track(ctx context.Context, awb string) ([]TrackingEvent, error) {
+	return nil, errors.New("tracking pull not supported for " + t.CourierName)
+}
+
+func (t *WebhookTracker) ParseWebhook(r *http.Request) (string, []TrackingEvent, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return "", nil, err
+	}
+	if !VerifyWebhookSignature(t.Secret, body, r.Header.Get("X-Tracking-Signature")) {
+		return "", nil, ErrInvalidSignature
+	}
+	return parseWebhookPayload(body)
+}
+
+// SignWebhookBody - signature HMAC-SHA256 (hex) untuk body webhook
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func VerifyWebhookSignature(secret string, body []byte, signature string) bool {
+	if secret == "" || signature == "" {
+		return false
+	}
+	expected := SignWebhookBody(secret, body)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
+
+func parseWebhookPayload(body []byte) (string, []TrackingEvent, error) {
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return "", nil, err
+	}
+	if payload.AWB == "" {
+		return "", nil, errors.New("awb is required")
+	}
+	return payload.AWB, payload.Events, nil
+}
+
+// FakeTracker - tracker in-memory untuk test dan development lokal.
+// Event ditambahkan dengan AddEvent lalu bisa diambil lewat Track,
+// webhook diterima tanpa signature.
+type FakeTracker struct {
+	mu     sync.Mutex
+	events map[string][]TrackingEvent
+}
+
+func NewFakeTracker() *FakeTracker {
+	return &FakeTracker{events: make(map[string][]TrackingEvent)}
+}
+
+func (t *FakeTracker) Courier() string {
+	return "fake"
+}
+
+func (t *FakeTracker) AddEvent(awb string, event TrackingEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.events[awb] = append(t.events[awb], event)
+}
+
+func (t *FakeTracker) Track(ctx context.Context, awb string) ([]TrackingEvent, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	events := make([]TrackingEvent, len(t.events[awb]))
+	copy(events, t.events[awb])
+	return events, nil
+}
+
+func (t *FakeTracker) ParseWebhook(r *http.Request) (string, []TrackingEvent, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		return "", nil, err
+	}
+	return parseWebhookPayload(body)
+}
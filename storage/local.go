@@ -0,0 +1,102 @@
+// storage/local.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage - menyimpan file di filesystem lokal, di-serve lewat route static URLPrefix
+type LocalStorage struct {
+	BaseDir   string
+	URLPrefix string
+}
+
+func NewLocalStorage(baseDir, urlPrefix string) (*LocalStorage, error) {
+	if err := os.MkdirAll(baseDir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{
+		BaseDir:   baseDir,
+		URLPrefix: strings.TrimRight(urlPrefix, "/"),
+	}, nil
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return err
+	}
+
+	// Tulis ke file sementara lalu rename supaya tidak ada file setengah jadi yang ter-serve
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), fullPath)
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(fullPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (s *LocalStorage) Exists(ctx context.Context, key string) (bool, error) {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(fullPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	fullPath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(fullPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return s.URLPrefix + "/" + strings.TrimLeft(key, "/")
+}
+
+// path - memastikan key tidak keluar dari BaseDir (path traversal)
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" {
+		return "", errors.New("invalid storage key")
+	}
+	return filepath.Join(s.BaseDir, filepath.FromSlash(cleaned)), nil
+}
@@ -0,0 +1,20 @@
+// storage/storage.go
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+// Storage - abstraksi penyimpanan file (gambar produk, logo toko, foto profil).
+// Key selalu memakai separator "/" dan relatif terhadap root storage.
+type Storage interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+	// URL - URL publik untuk key tertentu
+	URL(key string) string
+}
@@ -0,0 +1,52 @@
+// utils/address.go
+package utils
+
+import (
+	"ecommerce-golang/models"
+	"errors"
+	"gorm.io/gorm"
+)
+
+var ErrNoDestination = errors.New("no shipping address set")
+
+// Destination - tujuan pengiriman user
+type Destination struct {
+	AddressID  uint   `json:"address_id,omitempty"`
+	Province   string `json:"province,omitempty"`
+	City       string `json:"city"`
+	PostalCode string `json:"postal_code,omitempty"`
+}
+
+// GetUserDestination - tujuan pengiriman: alamat dengan addressID jika diberikan,
+// selain itu alamat default. User lama tanpa address book memakai kota di profile.
+func GetUserDestination(db *gorm.DB, userID uint, addressID uint) (Destination, error) {
+	var address models.UserAddress
+	query := db.Where("user_id = ?", userID)
+	if addressID != 0 {
+		query = query.Where("id = ?", addressID)
+	} else {
+		query = query.Where("is_default = ?", true)
+	}
+
+	err := query.First(&address).Error
+	if err == nil {
+		return Destination{
+			AddressID:  address.ID,
+			Province:   address.Province,
+			City:       address.City,
+			PostalCode: address.PostalCode,
+		}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return Destination{}, err
+	}
+	if addressID != 0 {
+		return Destination{}, err
+	}
+
+	var profile models.UserProfile
+	if err := db.Where("user_id = ?", userID).First(&profile).Error; err != nil || profile.City == "" {
+		return Destination{}, ErrNoDestination
+	}
+	return Destination{City: profile.City}, nil
+}
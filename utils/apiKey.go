@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// Format API key: sk_<prefix>_<secret>
+const apiKeyTag = "sk"
+
+var ErrInvalidAPIKeyFormat = errors.New("invalid API key format")
+
+// GenerateAPIKey - membuat API key baru. fullKey hanya ditampilkan sekali ke seller,
+// yang disimpan di database hanya prefix dan hash dari secret.
+// Prefix 64 bit supaya tabrakan di unique index prefix praktis tidak mungkin.
+func GenerateAPIKey() (fullKey, prefix, secretHash string, err error) {
+	prefixBytes := make([]byte, 8)
+	if _, err = rand.Read(prefixBytes); err != nil {
+		return "", "", "", err
+	}
+	secretBytes := make([]byte, 24)
+	if _, err = rand.Read(secretBytes); err != nil {
+		return "", "", "", err
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	secret := hex.EncodeToString(secretBytes)
+	fullKey = apiKeyTag + "_" + prefix + "_" + secret
+
+	return fullKey, prefix, HashAPIKeySecret(secret), nil
+}
+
+// ParseAPIKey - memecah API key menjadi prefix dan secret
+func ParseAPIKey(key string) (prefix, secret string, err error) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyTag || parts[1] == "" || parts[2] == "" {
+		return "", "", ErrInvalidAPIKeyFormat
+	}
+	return parts[1], parts[2], nil
+}
+
+// HashAPIKeySecret - secret sudah random 192 bit, jadi sha256 cukup (tidak perlu bcrypt)
+func HashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyAPIKeySecret - membandingkan secret dengan hash secara constant-time
+func VerifyAPIKeySecret(secret, secretHash string) bool {
+	computed := HashAPIKeySecret(secret)
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(secretHash)) == 1
+}
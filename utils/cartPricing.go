@@ -0,0 +1,149 @@
+// utils/cartPricing.go
+package utils
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/money"
+	"ecommerce-golang/pricing"
+	"gorm.io/gorm"
+	"time"
+)
+
+// GetCartLines - baris keranjang user yang dicentang untuk produk aktif (tanpa item yang disimpan
+// untuk nanti), lengkap dengan harga flash sale untuk quantity yang kuotanya sedang dipegang user
+// dan PPN sesuai status PKP seller
+func GetCartLines(db *gorm.DB, userID uint, now time.Time) ([]pricing.Line, error) {
+	var rows []cartLineRow
+	err := db.Table("product_user_carts").
+		Select(`product_user_carts.id as cart_id, product_user_carts.product_id,
+				products.seller_id, products.category, product_user_carts.quantity, products.price,
+				COALESCE(seller_profiles.is_pkp, false) as is_pkp, seller_profiles.price_tax_mode`).
+		Joins("JOIN products ON product_user_carts.product_id = products.id").
+		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
+		Where("product_user_carts.user_id = ? AND products.is_active = ?", userID, true).
+		Where("product_user_carts.saved_for_later = ? AND product_user_carts.selected = ?", false, true).
+		Order("product_user_carts.id ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	allocations, err := GetUserFlashSaleAllocations(db, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	taxRules, err := LoadTaxRules(db)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := cartLines(rows, taxRules)
+	for i := range lines {
+		if allocation, ok := allocations[lines[i].ProductID]; ok {
+			lines[i].FlashSaleQuantity = min(int64(allocation.Quantity), lines[i].Quantity)
+			lines[i].FlashSalePrice = allocation.SalePrice.Amount
+		}
+	}
+	return lines, nil
+}
+
+// cartLineRow - hasil join baris keranjang (user atau guest) dengan produk dan profil seller
+type cartLineRow struct {
+	CartID    uint
+	ProductID uint
+	SellerID  uint
+	Category  string
+	Quantity  uint
+	Price     money.Money
+
+	IsPKP        bool
+	PriceTaxMode string
+}
+
+func cartLines(rows []cartLineRow, taxRules TaxRules) []pricing.Line {
+	lines := make([]pricing.Line, len(rows))
+	for i, row := range rows {
+		lines[i] = pricing.Line{
+			CartID:    row.CartID,
+			ProductID: row.ProductID,
+			SellerID:  row.SellerID,
+			Category:  row.Category,
+			Quantity:  int64(row.Quantity),
+			UnitPrice: row.Price.Amount,
+			Tax:       taxRules.LineTax(row.IsPKP, row.PriceTaxMode, row.Category),
+		}
+	}
+	return lines
+}
+
+// PriceUserCart - total keranjang user termasuk voucher yang sedang dipasang.
+// shipping berisi ongkir per seller yang sudah dipilih (boleh nil).
+// Voucher yang sudah tidak berlaku atau tidak memenuhi syarat keranjang tetap muncul di
+// Result.Voucher dengan error-nya, tanpa potongan. Error lain dari pricing engine dikembalikan.
+func PriceUserCart(db *gorm.DB, userID uint, shipping map[uint]int64) (pricing.Result, error) {
+	now := time.Now()
+	lines, err := GetCartLines(db, userID, now)
+	if err != nil {
+		return pricing.Result{}, err
+	}
+	return priceCartLines(db, userID, lines, shipping, now)
+}
+
+// priceCartLines - menghitung baris keranjang user dengan voucher yang sedang dipasang
+func priceCartLines(db *gorm.DB, userID uint, lines []pricing.Line, shipping map[uint]int64, now time.Time) (pricing.Result, error) {
+	input := pricing.Input{Lines: lines, Shipping: shipping}
+
+	voucher, err := GetAppliedVoucher(db, userID, now)
+	if err != nil {
+		return pricing.Result{}, err
+	}
+	if voucher != nil {
+		if err := CheckVoucherValidity(*voucher, now); err != nil {
+			result, calcErr := pricing.Calculate(input)
+			if calcErr != nil {
+				return pricing.Result{}, calcErr
+			}
+			result.Voucher = &pricing.VoucherResult{Code: voucher.Code, Type: voucher.Type, Error: err.Error()}
+			return result, nil
+		}
+		rule := VoucherRule(*voucher)
+		input.Voucher = &rule
+	}
+
+	result, err := pricing.Calculate(input)
+	if err != nil && !pricing.IsVoucherRejected(err) {
+		return pricing.Result{}, err
+	}
+	return result, nil
+}
+
+// PriceProductLine - total harga satu produk di keranjang user (termasuk harga flash sale),
+// untuk response tambah/ubah item keranjang
+func PriceProductLine(db *gorm.DB, userID uint, product models.Product, quantity uint) money.Money {
+	line := pricing.Line{
+		ProductID: product.ID,
+		SellerID:  product.SellerID,
+		Quantity:  int64(quantity),
+		UnitPrice: product.Price.Amount,
+	}
+	if allocations, err := GetUserFlashSaleAllocations(db, userID, time.Now()); err == nil {
+		if allocation, ok := allocations[product.ID]; ok {
+			line.FlashSaleQuantity = int64(allocation.Quantity)
+			line.FlashSalePrice = allocation.SalePrice.Amount
+		}
+	}
+	return money.IDR(line.Subtotal())
+}
+
+// NewOrderItem - item pembelian dari satu baris keranjang. Catatan pembeli ikut disalin
+// supaya terbaca seller saat menyiapkan pesanan.
+func NewOrderItem(cartItem models.ProductUserCart, product models.Product, quantity uint, price money.Money) models.ProductUserHistory {
+	return models.ProductUserHistory{
+		UserID:    cartItem.UserID,
+		ProductID: cartItem.ProductID,
+		Category:  product.Category,
+		Quantity:  quantity,
+		Price:     price,
+		Note:      cartItem.Note,
+	}
+}
@@ -0,0 +1,174 @@
+// utils/cartValidation.go
+package utils
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/money"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Kode peringatan per baris keranjang
+const (
+	CartWarningPriceIncreased         = "price_increased"
+	CartWarningPriceDecreased         = "price_decreased"
+	CartWarningOutOfStock             = "out_of_stock"
+	CartWarningQuantityReducedToStock = "quantity_reduced_to_stock"
+	CartWarningProductInactive        = "product_inactive"
+	CartWarningSellerOnHoliday        = "seller_on_holiday"
+)
+
+type CartWarning struct {
+	Code           string       `json:"code"`
+	Message        string       `json:"message"`
+	PreviousPrice  *money.Money `json:"previous_price,omitempty"`
+	CurrentPrice   *money.Money `json:"current_price,omitempty"`
+	AvailableStock *uint        `json:"available_stock,omitempty"`
+}
+
+// CartLineState - kondisi satu baris keranjang dibandingkan produk saat ini
+type CartLineState struct {
+	Quantity   uint
+	PriceAtAdd money.Money
+	Price      money.Money
+	Stock      uint
+	IsActive   bool
+	OnHoliday  bool
+}
+
+// CartLineWarnings - daftar peringatan untuk satu baris keranjang.
+// PriceAtAdd 0 (baris lama sebelum ada snapshot) tidak menghasilkan peringatan harga.
+func CartLineWarnings(line CartLineState) []CartWarning {
+	warnings := []CartWarning{}
+
+	if !line.IsActive {
+		warnings = append(warnings, CartWarning{
+			Code:    CartWarningProductInactive,
+			Message: "Produk sudah tidak dijual dan tidak dihitung di total",
+		})
+		return warnings
+	}
+
+	if line.PriceAtAdd.Amount > 0 && line.PriceAtAdd.Amount != line.Price.Amount {
+		previous, current := line.PriceAtAdd, line.Price
+		warning := CartWarning{PreviousPrice: &previous, CurrentPrice: &current}
+		if current.Amount > previous.Amount {
+			warning.Code = CartWarningPriceIncreased
+			warning.Message = fmt.Sprintf("Harga naik dari %s menjadi %s", previous, current)
+		} else {
+			warning.Code = CartWarningPriceDecreased
+			warning.Message = fmt.Sprintf("Harga turun dari %s menjadi %s", previous, current)
+		}
+		warnings = append(warnings, warning)
+	}
+
+	stock := line.Stock
+	if stock == 0 {
+		warnings = append(warnings, CartWarning{
+			Code:           CartWarningOutOfStock,
+			Message:        "Stok produk habis",
+			AvailableStock: &stock,
+		})
+	} else if line.Quantity > stock {
+		warnings = append(warnings, CartWarning{
+			Code:           CartWarningQuantityReducedToStock,
+			Message:        fmt.Sprintf("Stok tersisa %d, jumlah di keranjang akan dikurangi", stock),
+			AvailableStock: &stock,
+		})
+	}
+
+	if line.OnHoliday {
+		warnings = append(warnings, CartWarning{
+			Code:    CartWarningSellerOnHoliday,
+			Message: "Toko sedang libur",
+		})
+	}
+	return warnings
+}
+
+// CartRefreshChange - perubahan yang diterapkan ke satu baris keranjang saat refresh
+type CartRefreshChange struct {
+	CartID    uint     `json:"cart_id"`
+	ProductID uint     `json:"product_id"`
+	Applied   []string `json:"applied"`
+	Removed   bool     `json:"removed"`
+	Quantity  uint     `json:"quantity"`
+}
+
+// RefreshUserCart - menerima perubahan yang dilaporkan CartLineWarnings: snapshot harga
+// disamakan dengan harga sekarang dan quantity dikurangi ke stok. Produk tidak aktif,
+// stok habis dan toko libur tetap di keranjang kecuali removeUnavailable.
+func RefreshUserCart(db *gorm.DB, userID uint, removeUnavailable bool) ([]CartRefreshChange, error) {
+	changes := []CartRefreshChange{}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var items []models.ProductUserCart
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).Order("id ASC").
+			Find(&items).Error; err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			var product models.Product
+			if err := tx.First(&product, item.ProductID).Error; err != nil {
+				return err
+			}
+			var profile models.SellerProfile
+			if err := tx.Where("seller_id = ?", product.SellerID).Limit(1).Find(&profile).Error; err != nil {
+				return err
+			}
+
+			warnings := CartLineWarnings(CartLineState{
+				Quantity:   item.Quantity,
+				PriceAtAdd: item.PriceAtAdd,
+				Price:      product.Price,
+				Stock:      product.Stock,
+				IsActive:   product.IsActive,
+				OnHoliday:  profile.OnHoliday,
+			})
+			// Snapshot lama yang masih 0 ikut diisi walaupun tidak ada peringatan harga
+			if len(warnings) == 0 && item.PriceAtAdd.Amount == product.Price.Amount {
+				continue
+			}
+
+			change := CartRefreshChange{CartID: item.ID, ProductID: item.ProductID, Applied: []string{}}
+			unavailable := false
+			for _, warning := range warnings {
+				switch warning.Code {
+				case CartWarningPriceIncreased, CartWarningPriceDecreased:
+					change.Applied = append(change.Applied, warning.Code)
+				case CartWarningQuantityReducedToStock:
+					item.Quantity = product.Stock
+					change.Applied = append(change.Applied, warning.Code)
+				case CartWarningProductInactive, CartWarningOutOfStock, CartWarningSellerOnHoliday:
+					unavailable = true
+				}
+			}
+
+			if unavailable && removeUnavailable {
+				if err := tx.Delete(&item).Error; err != nil {
+					return err
+				}
+				change.Removed = true
+				changes = append(changes, change)
+				continue
+			}
+
+			item.PriceAtAdd = product.Price
+			if err := tx.Model(&item).Updates(map[string]interface{}{
+				"price_at_add": item.PriceAtAdd,
+				"quantity":     item.Quantity,
+			}).Error; err != nil {
+				return err
+			}
+			change.Quantity = item.Quantity
+			if len(change.Applied) > 0 {
+				changes = append(changes, change)
+			}
+		}
+		return nil
+	})
+	return changes, err
+}
@@ -0,0 +1,205 @@
+// utils/checkout.go
+package utils
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/money"
+	"ecommerce-golang/pricing"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var (
+	ErrCartEmpty           = errors.New("no selected items in cart")
+	ErrShippingNotSelected = errors.New("shipping option must be selected for every seller")
+	ErrOutOfStock          = errors.New("product is out of stock")
+)
+
+// PlaceOrder - membuat item pembelian dari baris keranjang yang dicentang dalam satu transaksi.
+// Stok dikurangi dengan UPDATE bersyarat (stock >= qty) sehingga tidak bisa minus walaupun
+// dua user checkout bersamaan, kuota flash sale yang dipakai ditandai purchased, klaim voucher
+// menjadi redeemed, lalu baris keranjang dihapus. shipping berisi ongkir yang dipilih untuk setiap seller di keranjang.
+func PlaceOrder(db *gorm.DB, userID uint, shipping map[uint]int64, now time.Time) ([]models.ProductUserHistory, pricing.Result, error) {
+	var items []models.ProductUserHistory
+	var result pricing.Result
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Serialisasi dengan checkout lain, voucher dan reservasi flash sale milik user yang sama
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.User{}, userID).Error; err != nil {
+			return err
+		}
+
+		lines, err := GetCartLines(tx, userID, now)
+		if err != nil {
+			return err
+		}
+		if len(lines) == 0 {
+			return ErrCartEmpty
+		}
+		for _, line := range lines {
+			if _, ok := shipping[line.SellerID]; !ok {
+				return ErrShippingNotSelected
+			}
+		}
+
+		if result, err = priceCartLines(tx, userID, lines, shipping, now); err != nil {
+			return err
+		}
+		// Voucher yang tidak lagi memberi potongan tidak ditebus diam-diam,
+		// user melepasnya atau mengubah isi keranjang lebih dulu
+		if result.Voucher != nil && !result.Voucher.Applied {
+			return fmt.Errorf("%w: %s", ErrVoucherNotUsable, result.Voucher.Error)
+		}
+
+		cartIDs := make([]uint, len(lines))
+		productIDs := make([]uint, len(lines))
+		for i, line := range lines {
+			cartIDs[i] = line.CartID
+			productIDs[i] = line.ProductID
+		}
+		var cartItems []models.ProductUserCart
+		if err := tx.Where("id IN ? AND user_id = ?", cartIDs, userID).Find(&cartItems).Error; err != nil {
+			return err
+		}
+		var products []models.Product
+		if err := tx.Where("id IN ?", productIDs).Find(&products).Error; err != nil {
+			return err
+		}
+		cartByID := make(map[uint]models.ProductUserCart, len(cartItems))
+		for _, cartItem := range cartItems {
+			cartByID[cartItem.ID] = cartItem
+		}
+		productByID := make(map[uint]models.Product, len(products))
+		for _, product := range products {
+			productByID[product.ID] = product
+		}
+
+		allocations, err := GetUserFlashSaleAllocations(tx, userID, now)
+		if err != nil {
+			return err
+		}
+
+		var lineOf []int // index result.Lines untuk setiap item
+		for lineIndex, line := range result.Lines {
+			quantity := uint(line.Quantity)
+			stock := tx.Model(&models.Product{}).
+				Where("id = ? AND stock >= ?", line.ProductID, quantity).
+				UpdateColumns(map[string]interface{}{
+					"stock":      gorm.Expr("stock - ?", quantity),
+					"total_sold": gorm.Expr("total_sold + ?", quantity),
+				})
+			if stock.Error != nil {
+				return stock.Error
+			}
+			if stock.RowsAffected == 0 {
+				return fmt.Errorf("%w: product %d", ErrOutOfStock, line.ProductID)
+			}
+
+			if line.FlashSaleQuantity > 0 {
+				allocation := allocations[line.ProductID]
+				if err := PurchaseFlashSaleReservations(tx, userID, allocation.FlashSaleID, uint(line.FlashSaleQuantity), now); err != nil {
+					return err
+				}
+			}
+
+			for _, item := range orderItems(cartByID[line.CartID], productByID[line.ProductID], line) {
+				items = append(items, item)
+				lineOf = append(lineOf, lineIndex)
+			}
+		}
+		applyOrderPricing(items, lineOf, result)
+
+		if err := tx.Create(&items).Error; err != nil {
+			return err
+		}
+		if result.Voucher != nil {
+			if err := RedeemAppliedVoucher(tx, userID, now); err != nil {
+				return err
+			}
+		}
+		return tx.Where("id IN ? AND user_id = ?", cartIDs, userID).Delete(&models.ProductUserCart{}).Error
+	})
+	if err != nil {
+		return nil, pricing.Result{}, err
+	}
+	return items, result, nil
+}
+
+// orderItems - item pembelian untuk satu baris keranjang. Quantity yang mendapat harga flash sale
+// dicatat sebagai item terpisah supaya Price setiap item tetap harga per unit yang dibayar.
+func orderItems(cartItem models.ProductUserCart, product models.Product, line pricing.LineResult) []models.ProductUserHistory {
+	var items []models.ProductUserHistory
+	if line.FlashSaleQuantity > 0 {
+		items = append(items, NewOrderItem(cartItem, product, uint(line.FlashSaleQuantity), line.FlashSalePrice))
+	}
+	if regular := line.Quantity - line.FlashSaleQuantity; regular > 0 {
+		items = append(items, NewOrderItem(cartItem, product, uint(regular), line.UnitPrice))
+	}
+	return items
+}
+
+// applyOrderPricing - menyalin rincian harga checkout ke item pembelian. Diskon dan PPN baris
+// dibagi ke item dari baris yang sama, ongkir dan subsidi ongkir paket dibagi ke item seller
+// tersebut, sebanding subtotal item (pricing.Allocate) sehingga jumlahnya tetap sama persis.
+func applyOrderPricing(items []models.ProductUserHistory, lineOf []int, result pricing.Result) {
+	byLine := make(map[int][]int)
+	bySeller := make(map[uint][]int)
+	for i := range items {
+		line := result.Lines[lineOf[i]]
+		items[i].Subtotal = money.IDR(items[i].Price.Amount * int64(items[i].Quantity))
+		items[i].TaxBasisPoints = line.TaxBasisPoints
+		items[i].TaxInclusive = line.TaxInclusive
+		byLine[lineOf[i]] = append(byLine[lineOf[i]], i)
+		bySeller[line.SellerID] = append(bySeller[line.SellerID], i)
+	}
+
+	split := func(indices []int, total int64) []int64 {
+		weights := make([]int64, len(indices))
+		var sum int64
+		for j, i := range indices {
+			weights[j] = items[i].Subtotal.Amount
+			sum += weights[j]
+		}
+		if sum == 0 {
+			for j := range weights {
+				weights[j] = 1
+			}
+		}
+		return pricing.Allocate(total, weights)
+	}
+	for lineIndex, indices := range byLine {
+		line := result.Lines[lineIndex]
+		discounts := split(indices, line.Discount.Amount)
+		bases := split(indices, line.TaxBase.Amount)
+		taxes := split(indices, line.Tax.Amount)
+		for j, i := range indices {
+			items[i].Discount = money.IDR(discounts[j])
+			items[i].TaxBase = money.IDR(bases[j])
+			items[i].Tax = money.IDR(taxes[j])
+		}
+	}
+	for _, seller := range result.Sellers {
+		indices := bySeller[seller.SellerID]
+		shipping := split(indices, seller.Shipping.Amount)
+		subsidies := split(indices, seller.ShippingDiscount.Amount)
+		for j, i := range indices {
+			items[i].Shipping = money.IDR(shipping[j])
+			items[i].ShippingDiscount = money.IDR(subsidies[j])
+		}
+	}
+
+	for i := range items {
+		item := &items[i]
+		total := item.Subtotal.Amount - item.Discount.Amount + item.Shipping.Amount - item.ShippingDiscount.Amount
+		if !item.TaxInclusive {
+			total += item.Tax.Amount
+		}
+		item.Total = money.IDR(total)
+		if result.Voucher != nil && result.Voucher.Applied {
+			item.VoucherCode = result.Voucher.Code
+		}
+	}
+}
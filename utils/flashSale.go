@@ -0,0 +1,316 @@
+// utils/flashSale.go
+package utils
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/money"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"time"
+)
+
+var (
+	ErrFlashSaleNotFound  = errors.New("flash sale not found")
+	ErrFlashSaleNotLive   = errors.New("flash sale is not running")
+	ErrFlashSaleSoldOut   = errors.New("flash sale quota is sold out")
+	ErrFlashSaleUserLimit = errors.New("flash sale purchase limit per user reached")
+	ErrFlashSaleOverlap   = errors.New("product already has a flash sale in this time range")
+	ErrFlashSaleExpired   = errors.New("flash sale reservation has expired")
+)
+
+// FlashSaleReservationTTL - lama kuota dipegang sebelum dikembalikan jika belum dibayar
+const FlashSaleReservationTTL = 15 * time.Minute
+
+// GetLiveFlashSales - flash sale yang sedang berjalan untuk daftar produk, key = product ID
+func GetLiveFlashSales(db *gorm.DB, productIDs []uint, now time.Time) (map[uint]models.FlashSale, error) {
+	sales := make(map[uint]models.FlashSale)
+	if len(productIDs) == 0 {
+		return sales, nil
+	}
+
+	var rows []models.FlashSale
+	err := db.Where("product_id IN ? AND is_active = ? AND starts_at <= ? AND ends_at > ?", productIDs, true, now, now).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if current, exists := sales[row.ProductID]; !exists || row.SalePrice.Amount < current.SalePrice.Amount {
+			sales[row.ProductID] = row
+		}
+	}
+	return sales, nil
+}
+
+func flashSaleInfo(sale models.FlashSale, originalPrice money.Money) *models.FlashSaleInfo {
+	return &models.FlashSaleInfo{
+		ID:            sale.ID,
+		SalePrice:     sale.SalePrice,
+		OriginalPrice: originalPrice,
+		Quota:         sale.Quota,
+		Remaining:     sale.Remaining(),
+		PerUserLimit:  sale.PerUserLimit,
+		StartsAt:      sale.StartsAt,
+		EndsAt:        sale.EndsAt,
+	}
+}
+
+// ApplyFlashSalePrices - mengganti harga produk listing dengan harga flash sale yang sedang berjalan
+func ApplyFlashSalePrices(db *gorm.DB, products []models.ProductListView) {
+	ids := make([]uint, len(products))
+	for i, product := range products {
+		ids[i] = product.ID
+	}
+
+	sales, err := GetLiveFlashSales(db, ids, time.Now())
+	if err != nil {
+		return
+	}
+	for i := range products {
+		if sale, ok := sales[products[i].ID]; ok {
+			products[i].FlashSale = flashSaleInfo(sale, products[i].Price)
+			products[i].Price = sale.SalePrice
+		}
+	}
+}
+
+// ApplyFlashSaleToDetail - sama seperti ApplyFlashSalePrices untuk halaman detail produk
+func ApplyFlashSaleToDetail(db *gorm.DB, product *models.ProductDetailView) {
+	sales, err := GetLiveFlashSales(db, []uint{product.ID}, time.Now())
+	if err != nil {
+		return
+	}
+	if sale, ok := sales[product.ID]; ok {
+		product.FlashSale = flashSaleInfo(sale, product.Price)
+		product.Price = sale.SalePrice
+	}
+}
+
+// FlashSaleAllocation - jumlah item keranjang user yang mendapat harga flash sale
+type FlashSaleAllocation struct {
+	FlashSaleID uint        `json:"flash_sale_id"`
+	SalePrice   money.Money `json:"sale_price"`
+	Quantity    uint        `json:"quantity"`
+}
+
+// GetUserFlashSaleAllocations - kuota flash sale yang sedang dipegang user untuk kampanye
+// yang masih berjalan, key = product ID
+func GetUserFlashSaleAllocations(db *gorm.DB, userID uint, now time.Time) (map[uint]FlashSaleAllocation, error) {
+	var rows []struct {
+		ProductID   uint
+		FlashSaleID uint
+		SalePrice   money.Money
+		Quantity    uint
+	}
+	err := db.Table("flash_sale_reservations").
+		Select(`flash_sale_reservations.product_id, flash_sale_reservations.flash_sale_id,
+				flash_sales.sale_price, SUM(flash_sale_reservations.quantity) as quantity`).
+		Joins("JOIN flash_sales ON flash_sale_reservations.flash_sale_id = flash_sales.id").
+		Where("flash_sale_reservations.user_id = ? AND flash_sale_reservations.status = ? AND flash_sale_reservations.expires_at > ?",
+			userID, models.FlashSaleReserved, now).
+		Where("flash_sales.is_active = ? AND flash_sales.starts_at <= ? AND flash_sales.ends_at > ?", true, now, now).
+		Group("flash_sale_reservations.product_id, flash_sale_reservations.flash_sale_id, flash_sales.sale_price").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	allocations := make(map[uint]FlashSaleAllocation)
+	for _, row := range rows {
+		if current, exists := allocations[row.ProductID]; !exists || row.SalePrice.Amount < current.SalePrice.Amount {
+			allocations[row.ProductID] = FlashSaleAllocation{
+				FlashSaleID: row.FlashSaleID,
+				SalePrice:   row.SalePrice,
+				Quantity:    row.Quantity,
+			}
+		}
+	}
+	return allocations, nil
+}
+
+// ReserveFlashSale - mengklaim kuota flash sale untuk user.
+// Kuota dikurangi dengan satu UPDATE bersyarat (sold + qty <= quota) sehingga tetap benar
+// walaupun ribuan request datang bersamaan saat kampanye dimulai, tanpa mengunci row flash sale
+// lebih lama dari satu statement. Batas per user dijaga dengan mengunci row user.
+func ReserveFlashSale(db *gorm.DB, flashSaleID, userID, quantity uint, now time.Time) (models.FlashSaleReservation, error) {
+	var reservation models.FlashSaleReservation
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var sale models.FlashSale
+		if err := tx.First(&sale, flashSaleID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrFlashSaleNotFound
+			}
+			return err
+		}
+		if !sale.IsLive(now) {
+			return ErrFlashSaleNotLive
+		}
+
+		if sale.PerUserLimit > 0 {
+			if quantity > sale.PerUserLimit {
+				return ErrFlashSaleUserLimit
+			}
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.User{}, userID).Error; err != nil {
+				return err
+			}
+			var held uint
+			if err := tx.Model(&models.FlashSaleReservation{}).
+				Select("COALESCE(SUM(quantity), 0)").
+				Where("flash_sale_id = ? AND user_id = ?", sale.ID, userID).
+				Where("status = ? OR (status = ? AND expires_at > ?)", models.FlashSalePurchased, models.FlashSaleReserved, now).
+				Scan(&held).Error; err != nil {
+				return err
+			}
+			if held+quantity > sale.PerUserLimit {
+				return ErrFlashSaleUserLimit
+			}
+		}
+
+		result := tx.Model(&models.FlashSale{}).
+			Where("id = ? AND sold + ? <= quota AND is_active = ? AND starts_at <= ? AND ends_at > ?", sale.ID, quantity, true, now, now).
+			UpdateColumn("sold", gorm.Expr("sold + ?", quantity))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrFlashSaleSoldOut
+		}
+
+		reservation = models.FlashSaleReservation{
+			FlashSaleID: sale.ID,
+			UserID:      userID,
+			ProductID:   sale.ProductID,
+			Quantity:    quantity,
+			Status:      models.FlashSaleReserved,
+			ExpiresAt:   now.Add(FlashSaleReservationTTL),
+		}
+		return tx.Create(&reservation).Error
+	})
+
+	return reservation, err
+}
+
+// ReleaseExpiredFlashSaleReservations - mengembalikan kuota dari reservasi yang kedaluwarsa
+func ReleaseExpiredFlashSaleReservations(db *gorm.DB, now time.Time) (int, error) {
+	var expired []models.FlashSaleReservation
+	if err := db.Where("status = ? AND expires_at <= ?", models.FlashSaleReserved, now).
+		Limit(500).Find(&expired).Error; err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, reservation := range expired {
+		changed := false
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Status dicek ulang supaya kuota tidak dikembalikan dua kali
+			result := tx.Model(&models.FlashSaleReservation{}).
+				Where("id = ? AND status = ?", reservation.ID, models.FlashSaleReserved).
+				Update("status", models.FlashSaleReleased)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			changed = true
+			return tx.Model(&models.FlashSale{}).
+				Where("id = ? AND sold >= ?", reservation.FlashSaleID, reservation.Quantity).
+				UpdateColumn("sold", gorm.Expr("sold - ?", reservation.Quantity)).Error
+		})
+		if err != nil {
+			return released, err
+		}
+		if changed {
+			released++
+		}
+	}
+	return released, nil
+}
+
+// PurchaseFlashSaleReservations - menandai reservasi aktif user sebagai purchased sebanyak
+// quantity yang dibeli dengan harga flash sale. Reservasi yang hanya terpakai sebagian diperkecil
+// dan sisanya dikembalikan ke kuota; reservasi yang tidak terpakai dibiarkan sampai kedaluwarsa.
+func PurchaseFlashSaleReservations(tx *gorm.DB, userID, flashSaleID, quantity uint, now time.Time) error {
+	var reservations []models.FlashSaleReservation
+	if err := tx.Where("flash_sale_id = ? AND user_id = ? AND status = ? AND expires_at > ?",
+		flashSaleID, userID, models.FlashSaleReserved, now).
+		Order("id ASC").Find(&reservations).Error; err != nil {
+		return err
+	}
+
+	for _, reservation := range reservations {
+		if quantity == 0 {
+			break
+		}
+		used := min(reservation.Quantity, quantity)
+		quantity -= used
+
+		// Status dicek ulang: reservasi bisa sudah dilepas cleanup setelah dibaca
+		result := tx.Model(&models.FlashSaleReservation{}).
+			Where("id = ? AND status = ?", reservation.ID, models.FlashSaleReserved).
+			Updates(map[string]interface{}{"status": models.FlashSalePurchased, "quantity": used})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrFlashSaleExpired
+		}
+		if rest := reservation.Quantity - used; rest > 0 {
+			if err := tx.Model(&models.FlashSale{}).
+				Where("id = ? AND sold >= ?", flashSaleID, rest).
+				UpdateColumn("sold", gorm.Expr("sold - ?", rest)).Error; err != nil {
+				return err
+			}
+		}
+	}
+	if quantity > 0 {
+		return ErrFlashSaleExpired
+	}
+	return nil
+}
+
+// StartFlashSaleReservationCleanup - goroutine yang melepas reservasi kedaluwarsa setiap menit
+func StartFlashSaleReservationCleanup(db *gorm.DB) {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if released, err := ReleaseExpiredFlashSaleReservations(db, time.Now()); err != nil {
+				log.Printf("Release flash sale reservations failed: %v", err)
+			} else if released > 0 {
+				log.Printf("Released %d expired flash sale reservations", released)
+			}
+		}
+	}()
+}
+
+// ValidateFlashSale - validasi kampanye terhadap produk dan kampanye lain untuk produk yang sama
+func ValidateFlashSale(db *gorm.DB, sale models.FlashSale, product models.Product) error {
+	if sale.SalePrice.Amount <= 0 || sale.SalePrice.Amount >= product.Price.Amount {
+		return errors.New("sale_price must be greater than 0 and lower than the product price")
+	}
+	if sale.Quota == 0 {
+		return errors.New("quota must be greater than 0")
+	}
+	if sale.Quota > product.Stock {
+		return errors.New("quota must not exceed product stock")
+	}
+	if !sale.EndsAt.After(sale.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+
+	var count int64
+	query := db.Model(&models.FlashSale{}).
+		Where("product_id = ? AND is_active = ? AND starts_at < ? AND ends_at > ?", product.ID, true, sale.EndsAt, sale.StartsAt)
+	if sale.ID != 0 {
+		query = query.Where("id <> ?", sale.ID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrFlashSaleOverlap
+	}
+	return nil
+}
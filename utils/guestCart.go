@@ -0,0 +1,277 @@
+// utils/guestCart.go
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"ecommerce-golang/models"
+	"ecommerce-golang/pricing"
+	"encoding/hex"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// Token guest cart dikirim lewat cookie atau header (untuk aplikasi mobile)
+const (
+	GuestCartCookie = "guest_cart"
+	GuestCartHeader = "X-Guest-Cart"
+)
+
+// Alasan penyesuaian quantity saat guest cart digabung ke keranjang user
+const (
+	CartMergeCappedAtStock      = "capped_at_stock"
+	CartMergeOutOfStock         = "out_of_stock"
+	CartMergeProductUnavailable = "product_unavailable"
+)
+
+var ErrInvalidGuestCartToken = errors.New("invalid guest cart token")
+
+// GuestCartTTL - umur guest cart sejak terakhir diubah, dari env GUEST_CART_TTL (default 30 hari)
+func GuestCartTTL() time.Duration {
+	if ttl, err := time.ParseDuration(os.Getenv("GUEST_CART_TTL")); err == nil && ttl > 0 {
+		return ttl
+	}
+	return 30 * 24 * time.Hour
+}
+
+// guestCartSecret - kunci HMAC token guest cart, GUEST_CART_SECRET atau JWT_SECRET_KEY
+func guestCartSecret() ([]byte, error) {
+	secret := os.Getenv("GUEST_CART_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET_KEY")
+	}
+	if secret == "" {
+		return nil, errors.New("GUEST_CART_SECRET or JWT_SECRET_KEY environment variable is not set")
+	}
+	return []byte(secret), nil
+}
+
+func signGuestCartKey(secret []byte, key string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(key))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewGuestCartToken - membuat key random baru beserta token <key>.<signature>
+func NewGuestCartToken() (token, key string, err error) {
+	secret, err := guestCartSecret()
+	if err != nil {
+		return "", "", err
+	}
+	keyBytes := make([]byte, 16)
+	if _, err = rand.Read(keyBytes); err != nil {
+		return "", "", err
+	}
+	key = hex.EncodeToString(keyBytes)
+	return key + "." + signGuestCartKey(secret, key), key, nil
+}
+
+// ParseGuestCartToken - memverifikasi signature dan mengembalikan key guest cart
+func ParseGuestCartToken(token string) (string, error) {
+	secret, err := guestCartSecret()
+	if err != nil {
+		return "", err
+	}
+	key, signature, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok || key == "" || signature == "" {
+		return "", ErrInvalidGuestCartToken
+	}
+	if !hmac.Equal([]byte(signGuestCartKey(secret, key)), []byte(strings.ToLower(signature))) {
+		return "", ErrInvalidGuestCartToken
+	}
+	return key, nil
+}
+
+// FindGuestCart - guest cart yang masih berlaku untuk token tersebut
+func FindGuestCart(db *gorm.DB, token string, now time.Time) (models.GuestCart, error) {
+	var cart models.GuestCart
+	key, err := ParseGuestCartToken(token)
+	if err != nil {
+		return cart, err
+	}
+	err = db.Where("cart_key = ? AND expires_at > ?", key, now).First(&cart).Error
+	return cart, err
+}
+
+// CreateGuestCart - guest cart baru, token hanya dikembalikan di sini
+func CreateGuestCart(db *gorm.DB, now time.Time) (models.GuestCart, string, error) {
+	token, key, err := NewGuestCartToken()
+	if err != nil {
+		return models.GuestCart{}, "", err
+	}
+	cart := models.GuestCart{CartKey: key, ExpiresAt: now.Add(GuestCartTTL())}
+	if err := db.Create(&cart).Error; err != nil {
+		return models.GuestCart{}, "", err
+	}
+	return cart, token, nil
+}
+
+// TouchGuestCart - memperpanjang umur guest cart setiap kali isinya diubah
+func TouchGuestCart(db *gorm.DB, cart *models.GuestCart, now time.Time) error {
+	cart.ExpiresAt = now.Add(GuestCartTTL())
+	return db.Model(cart).Update("expires_at", cart.ExpiresAt).Error
+}
+
+// GetGuestCartLines - baris guest cart untuk produk aktif dengan PPN sesuai status PKP seller.
+// Harga flash sale tidak berlaku karena kuotanya hanya bisa dipegang user yang login.
+func GetGuestCartLines(db *gorm.DB, guestCartID uint) ([]pricing.Line, error) {
+	var rows []cartLineRow
+	err := db.Table("guest_cart_items").
+		Select(`guest_cart_items.id as cart_id, guest_cart_items.product_id,
+				products.seller_id, products.category, guest_cart_items.quantity, products.price,
+				COALESCE(seller_profiles.is_pkp, false) as is_pkp, seller_profiles.price_tax_mode`).
+		Joins("JOIN products ON guest_cart_items.product_id = products.id").
+		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
+		Where("guest_cart_items.guest_cart_id = ? AND products.is_active = ?", guestCartID, true).
+		Order("guest_cart_items.id ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	taxRules, err := LoadTaxRules(db)
+	if err != nil {
+		return nil, err
+	}
+	return cartLines(rows, taxRules), nil
+}
+
+// PriceGuestCart - total guest cart (voucher hanya bisa dipasang setelah login)
+func PriceGuestCart(db *gorm.DB, guestCartID uint) (pricing.Result, error) {
+	lines, err := GetGuestCartLines(db, guestCartID)
+	if err != nil {
+		return pricing.Result{}, err
+	}
+	return pricing.Calculate(pricing.Input{Lines: lines})
+}
+
+// CartMergeAdjustment - produk guest cart yang quantity-nya tidak bisa digabung utuh
+type CartMergeAdjustment struct {
+	ProductID uint   `json:"product_id"`
+	Requested uint   `json:"requested"` // quantity di keranjang user + guest cart
+	Quantity  uint   `json:"quantity"`  // quantity akhir di keranjang user
+	Reason    string `json:"reason"`
+}
+
+type CartMergeResult struct {
+	MergedItems int                   `json:"merged_items"`
+	Adjustments []CartMergeAdjustment `json:"adjustments"`
+}
+
+// MergeGuestCart - menggabungkan guest cart ke keranjang user lalu menghapus guest cart.
+// Item diproses urut product_id, quantity dijumlah dan dibatasi stok saat ini.
+// Guest cart dikunci supaya dua login bersamaan tidak menggabungkan isinya dua kali.
+func MergeGuestCart(db *gorm.DB, guestCartID, userID uint) (CartMergeResult, error) {
+	result := CartMergeResult{Adjustments: []CartMergeAdjustment{}}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var cart models.GuestCart
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&cart, guestCartID).Error; err != nil {
+			return err
+		}
+
+		var items []models.GuestCartItem
+		if err := tx.Where("guest_cart_id = ?", cart.ID).Order("product_id ASC").Find(&items).Error; err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			var existing models.ProductUserCart
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("user_id = ? AND product_id = ?", userID, item.ProductID).
+				First(&existing).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			found := err == nil
+			requested := existing.Quantity + item.Quantity
+
+			var product models.Product
+			err = tx.Where("id = ? AND is_active = ?", item.ProductID, true).First(&product).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				result.Adjustments = append(result.Adjustments, CartMergeAdjustment{
+					ProductID: item.ProductID, Requested: requested, Quantity: existing.Quantity,
+					Reason: CartMergeProductUnavailable,
+				})
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if product.Stock == 0 {
+				result.Adjustments = append(result.Adjustments, CartMergeAdjustment{
+					ProductID: item.ProductID, Requested: requested, Quantity: existing.Quantity,
+					Reason: CartMergeOutOfStock,
+				})
+				continue
+			}
+
+			quantity := min(requested, product.Stock)
+			if quantity < requested {
+				result.Adjustments = append(result.Adjustments, CartMergeAdjustment{
+					ProductID: item.ProductID, Requested: requested, Quantity: quantity,
+					Reason: CartMergeCappedAtStock,
+				})
+			}
+
+			if found {
+				existing.Quantity = quantity
+				existing.SavedForLater = false
+				existing.Selected = true
+				err = tx.Save(&existing).Error
+			} else {
+				err = tx.Create(&models.ProductUserCart{
+					UserID:     userID,
+					ProductID:  item.ProductID,
+					Quantity:   quantity,
+					PriceAtAdd: product.Price,
+				}).Error
+			}
+			if err != nil {
+				return err
+			}
+			result.MergedItems++
+		}
+
+		if err := tx.Where("guest_cart_id = ?", cart.ID).Delete(&models.GuestCartItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&cart).Error
+	})
+	return result, err
+}
+
+// DeleteExpiredGuestCarts - menghapus guest cart kedaluwarsa beserta isinya
+func DeleteExpiredGuestCarts(db *gorm.DB, now time.Time) (int64, error) {
+	var ids []uint
+	if err := db.Model(&models.GuestCart{}).Where("expires_at <= ?", now).
+		Limit(500).Pluck("id", &ids).Error; err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	// Item ikut terhapus lewat foreign key ON DELETE CASCADE
+	result := db.Where("id IN ? AND expires_at <= ?", ids, now).Delete(&models.GuestCart{})
+	return result.RowsAffected, result.Error
+}
+
+// StartGuestCartCleanup - goroutine yang membersihkan guest cart kedaluwarsa setiap jam
+func StartGuestCartCleanup(db *gorm.DB) {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if deleted, err := DeleteExpiredGuestCarts(db, time.Now()); err != nil {
+				log.Printf("Delete expired guest carts failed: %v", err)
+			} else if deleted > 0 {
+				log.Printf("Deleted %d expired guest carts", deleted)
+			}
+		}
+	}()
+}
@@ -0,0 +1,294 @@
+// utils/imageUpload.go
+package utils
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"ecommerce-golang/models"
+	"ecommerce-golang/storage"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"strings"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Batas upload gambar
+const (
+	MaxImageUploadSize = 5 << 20 // 5 MB
+	maxImagePixels     = 40_000_000
+)
+
+// Ukuran sisi terpanjang untuk setiap varian gambar
+const (
+	originalMaxSide  = 2048
+	mediumMaxSide    = 800
+	thumbnailMaxSide = 200
+)
+
+var (
+	ErrImageTooLarge       = errors.New("image exceeds maximum upload size of 5MB")
+	ErrUnsupportedImage    = errors.New("unsupported image type, use JPEG, PNG, GIF or WebP")
+	ErrImageDimensionLimit = errors.New("image dimensions are too large")
+)
+
+// Format output untuk setiap MIME type yang diterima. Semua gambar di-encode ulang,
+// sehingga metadata EXIF (lokasi GPS, info kamera) otomatis terbuang.
+var imageOutputFormat = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "png",
+	"image/webp": "jpg",
+}
+
+// SaveImageUpload - memvalidasi, memproses dan menyimpan gambar beserta varian medium dan thumbnail.
+// Jika gambar yang sama persis sudah pernah di-upload, asset lama dipakai ulang.
+func SaveImageUpload(ctx context.Context, db *gorm.DB, store storage.Storage, data []byte, uploaderType string, uploaderID uint) (models.ImageAsset, error) {
+	if len(data) > MaxImageUploadSize {
+		return models.ImageAsset{}, ErrImageTooLarge
+	}
+
+	// Sniff MIME dari isi file, bukan dari header Content-Type client
+	contentType := http.DetectContentType(data)
+	ext, ok := imageOutputFormat[contentType]
+	if !ok {
+		return models.ImageAsset{}, ErrUnsupportedImage
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	var existing models.ImageAsset
+	if err := db.Where("hash = ?", hash).First(&existing).Error; err == nil {
+		return existing, nil
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return models.ImageAsset{}, ErrUnsupportedImage
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return models.ImageAsset{}, ErrImageDimensionLimit
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return models.ImageAsset{}, ErrUnsupportedImage
+	}
+
+	original := resizeToFit(img, originalMaxSide)
+	if contentType == "image/jpeg" {
+		original = applyEXIFOrientation(original, jpegOrientation(data))
+	}
+
+	variants := []struct {
+		name string
+		img  image.Image
+	}{
+		{"original", original},
+		{"medium", resizeToFit(original, mediumMaxSide)},
+		{"thumb", resizeToFit(original, thumbnailMaxSide)},
+	}
+
+	urls := make(map[string]string, len(variants))
+	keyPrefix := fmt.Sprintf("images/%s/%s/", hash[:2], hash)
+	for _, variant := range variants {
+		encoded, outputType, err := encodeImage(variant.img, ext)
+		if err != nil {
+			return models.ImageAsset{}, err
+		}
+		key := keyPrefix + variant.name + "." + ext
+		if err := store.Put(ctx, key, encoded, outputType); err != nil {
+			return models.ImageAsset{}, fmt.Errorf("failed to store image: %w", err)
+		}
+		urls[variant.name] = store.URL(key)
+	}
+
+	bounds := original.Bounds()
+	asset := models.ImageAsset{
+		Hash:         hash,
+		ContentType:  contentType,
+		Width:        bounds.Dx(),
+		Height:       bounds.Dy(),
+		Size:         int64(len(data)),
+		OriginalURL:  urls["original"],
+		MediumURL:    urls["medium"],
+		ThumbnailURL: urls["thumb"],
+		UploaderType: uploaderType,
+		UploaderID:   uploaderID,
+	}
+	if err := db.Create(&asset).Error; err != nil {
+		// Upload paralel dengan file yang sama, pakai record yang sudah tersimpan
+		if db.Where("hash = ?", hash).First(&existing).Error == nil {
+			return existing, nil
+		}
+		return models.ImageAsset{}, err
+	}
+
+	return asset, nil
+}
+
+// ThumbnailURL - mengubah URL gambar original hasil upload menjadi URL varian thumbnail.
+// URL eksternal (bukan hasil upload) dikembalikan apa adanya.
+func ThumbnailURL(url string) string {
+	idx := strings.LastIndex(url, "/original.")
+	if idx == -1 {
+		return url
+	}
+	return url[:idx] + "/thumb." + url[idx+len("/original."):]
+}
+
+func resizeToFit(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSide && height <= maxSide {
+		return img
+	}
+
+	if width >= height {
+		height = height * maxSide / width
+		width = maxSide
+	} else {
+		width = width * maxSide / height
+		height = maxSide
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+func encodeImage(img image.Image, ext string) ([]byte, string, error) {
+	var buf bytes.Buffer
+	switch ext {
+	case "png":
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/png", nil
+	default:
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/jpeg", nil
+	}
+}
+
+// jpegOrientation - membaca tag Orientation (0x0112) dari segmen EXIF APP1.
+// Mengembalikan 1 (normal) jika tidak ada atau tidak valid.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		segmentLength := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if marker == 0xDA || segmentLength < 2 || pos+2+segmentLength > len(data) {
+			return 1
+		}
+
+		segment := data[pos+4 : pos+2+segmentLength]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		pos += 2 + segmentLength
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifdOffset := int(order.Uint32(tiff[4:]))
+	if ifdOffset+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifdOffset:]))
+	for i := 0; i < entries; i++ {
+		entry := ifdOffset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation >= 1 && orientation <= 8 {
+				return orientation
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// applyEXIFOrientation - memutar/membalik gambar sesuai orientation EXIF,
+// karena informasi ini hilang saat gambar di-encode ulang
+func applyEXIFOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // flip horizontal
+				sx, sy = width-1-x, y
+			case 3: // rotate 180
+				sx, sy = width-1-x, height-1-y
+			case 4: // flip vertical
+				sx, sy = x, height-1-y
+			case 5: // transpose
+				sx, sy = y, x
+			case 6: // rotate 90 CW
+				sx, sy = y, height-1-x
+			case 7: // transverse
+				sx, sy = width-1-y, height-1-x
+			case 8: // rotate 270 CW
+				sx, sy = width-1-y, x
+			}
+			dst.Set(x, y, img.At(bounds.Min.X+sx, bounds.Min.Y+sy))
+		}
+	}
+	return dst
+}
@@ -0,0 +1,315 @@
+// utils/invoice.go
+package utils
+
+import (
+	"ecommerce-golang/invoice"
+	"ecommerce-golang/models"
+	"ecommerce-golang/money"
+	"ecommerce-golang/pricing"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrShipmentHasNoItems = errors.New("shipment has no items to invoice")
+
+// InvoiceNumber - format nomor invoice, contoh INV/12/2026/000001.
+// Urutan per seller dan tidak di-reset tiap tahun, tahun hanya penanda tanggal terbit.
+func InvoiceNumber(sellerID uint, issuedAt time.Time, sequence uint) string {
+	return fmt.Sprintf("INV/%d/%d/%06d", sellerID, issuedAt.Year(), sequence)
+}
+
+// IssueShipmentInvoice - menerbitkan invoice untuk shipment, atau mengembalikan invoice yang
+// sudah ada. Nomor diambil dari InvoiceSequence seller yang dikunci di transaksi yang sama,
+// sehingga dua request bersamaan tidak mendapat nomor yang sama dan tidak ada nomor yang terlewat.
+func IssueShipmentInvoice(db *gorm.DB, shipmentID uint, now time.Time) (models.Invoice, error) {
+	var result models.Invoice
+
+	var shipment models.Shipment
+	if err := db.Preload("Items.Product").First(&shipment, shipmentID).Error; err != nil {
+		return result, err
+	}
+	if len(shipment.Items) == 0 {
+		return result, ErrShipmentHasNoItems
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Pastikan baris counter ada, lalu kunci
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.InvoiceSequence{SellerID: shipment.SellerID}).Error; err != nil {
+			return err
+		}
+		var sequence models.InvoiceSequence
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("seller_id = ?", shipment.SellerID).
+			First(&sequence).Error; err != nil {
+			return err
+		}
+
+		// Request lain mungkin sudah menerbitkan invoice selama kita menunggu lock
+		err := tx.Preload("Items").Where("shipment_id = ?", shipment.ID).First(&result).Error
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		issued, err := buildInvoice(tx, shipment, now)
+		if err != nil {
+			return err
+		}
+
+		sequence.LastNumber++
+		issued.Sequence = sequence.LastNumber
+		issued.Number = InvoiceNumber(shipment.SellerID, now, sequence.LastNumber)
+		if err := tx.Create(&issued).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(&sequence).Error; err != nil {
+			return err
+		}
+		result = issued
+		return nil
+	})
+	return result, err
+}
+
+// buildInvoice - menyalin data seller, buyer dan item shipment beserta rincian harga yang
+// dicatat saat checkout (potongan voucher, ongkir, subsidi ongkir dan PPN).
+// Item lama tanpa rincian harga dihitung ulang dengan pricing engine memakai tarif dan
+// status PKP saat ini, tanpa diskon dan ongkir.
+func buildInvoice(tx *gorm.DB, shipment models.Shipment, now time.Time) (models.Invoice, error) {
+	inv := models.Invoice{
+		SellerID:   shipment.SellerID,
+		UserID:     shipment.UserID,
+		ShipmentID: shipment.ID,
+		IssuedAt:   now,
+	}
+
+	var sellerProfile models.SellerProfile
+	if err := tx.Where("seller_id = ?", shipment.SellerID).First(&sellerProfile).Error; err != nil &&
+		!errors.Is(err, gorm.ErrRecordNotFound) {
+		return inv, err
+	}
+	var seller models.Seller
+	if err := tx.First(&seller, shipment.SellerID).Error; err != nil {
+		return inv, err
+	}
+	inv.SellerName = firstNonEmpty(sellerProfile.ShopName, seller.Username)
+	inv.SellerAddress = joinAddress(sellerProfile.Address, sellerProfile.City)
+	inv.SellerIsPKP = sellerProfile.IsPKP
+	if sellerProfile.IsPKP {
+		inv.SellerNPWP = sellerProfile.NPWP
+	}
+
+	var user models.User
+	if err := tx.First(&user, shipment.UserID).Error; err != nil {
+		return inv, err
+	}
+	var userProfile models.UserProfile
+	if err := tx.Where("user_id = ?", shipment.UserID).First(&userProfile).Error; err != nil &&
+		!errors.Is(err, gorm.ErrRecordNotFound) {
+		return inv, err
+	}
+	inv.BuyerName = firstNonEmpty(userProfile.FullName, user.Username)
+	inv.BuyerEmail = user.Email
+	inv.BuyerAddress = joinAddress(userProfile.Address, userProfile.City)
+
+	legacy, err := priceLegacyItems(tx, shipment, sellerProfile)
+	if err != nil {
+		return inv, err
+	}
+
+	for i, item := range shipment.Items {
+		if line, ok := legacy[i]; ok {
+			item.Subtotal = line.Subtotal
+			item.TaxBasisPoints = line.TaxBasisPoints
+			item.TaxInclusive = line.TaxInclusive
+			item.TaxBase = line.TaxBase
+			item.Tax = line.Tax
+			item.Total = line.Total
+			if !line.TaxInclusive {
+				item.Total.Amount += line.Tax.Amount
+			}
+		}
+
+		inv.Items = append(inv.Items, models.InvoiceItem{
+			HistoryID:    item.ID,
+			ProductID:    item.ProductID,
+			Name:         item.Product.Name,
+			Category:     item.Category,
+			Quantity:     item.Quantity,
+			UnitPrice:    item.Price,
+			Subtotal:     item.Subtotal,
+			Discount:     item.Discount,
+			TaxRate:      float64(item.TaxBasisPoints) / 100,
+			TaxInclusive: item.TaxInclusive,
+			TaxBase:      item.TaxBase,
+			Tax:          item.Tax,
+		})
+		inv.Subtotal.Amount += item.Subtotal.Amount
+		inv.Discount.Amount += item.Discount.Amount
+		inv.Shipping.Amount += item.Shipping.Amount
+		inv.ShippingDiscount.Amount += item.ShippingDiscount.Amount
+		if item.TaxBasisPoints > 0 {
+			inv.TaxBase.Amount += item.TaxBase.Amount
+		}
+		inv.Tax.Amount += item.Tax.Amount
+		if item.TaxInclusive {
+			inv.TaxIncluded.Amount += item.Tax.Amount
+		}
+		inv.Total.Amount += item.Total.Amount
+	}
+	return inv, nil
+}
+
+// priceLegacyItems - harga dan PPN item shipment yang belum punya rincian harga checkout,
+// key = index di shipment.Items
+func priceLegacyItems(tx *gorm.DB, shipment models.Shipment, sellerProfile models.SellerProfile) (map[int]pricing.LineResult, error) {
+	var indexes []int
+	for i, item := range shipment.Items {
+		if item.Subtotal.IsZero() {
+			indexes = append(indexes, i)
+		}
+	}
+	if len(indexes) == 0 {
+		return nil, nil
+	}
+
+	taxRules, err := LoadTaxRules(tx)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]pricing.Line, len(indexes))
+	for j, i := range indexes {
+		item := shipment.Items[i]
+		lines[j] = pricing.Line{
+			CartID:    item.ID,
+			ProductID: item.ProductID,
+			SellerID:  shipment.SellerID,
+			Category:  item.Category,
+			Quantity:  int64(item.Quantity),
+			UnitPrice: item.Price.Amount,
+			Tax:       taxRules.LineTax(sellerProfile.IsPKP, sellerProfile.PriceTaxMode, item.Category),
+		}
+	}
+	priced, err := pricing.Calculate(pricing.Input{Lines: lines})
+	if err != nil {
+		return nil, err
+	}
+
+	legacy := make(map[int]pricing.LineResult, len(indexes))
+	for j, i := range indexes {
+		legacy[i] = priced.Lines[j]
+	}
+	return legacy, nil
+}
+
+// InvoiceDocument - menyusun dokumen untuk renderer HTML/PDF dari invoice tersimpan
+func InvoiceDocument(inv models.Invoice) invoice.Document {
+	title := "INVOICE"
+	if inv.SellerIsPKP {
+		title = "INVOICE / FAKTUR PENJUALAN"
+	}
+
+	doc := invoice.Document{
+		Title:    title,
+		Number:   inv.Number,
+		IssuedAt: inv.IssuedAt,
+		Seller: invoice.Party{
+			Name:    inv.SellerName,
+			Address: inv.SellerAddress,
+			NPWP:    inv.SellerNPWP,
+		},
+		Buyer: invoice.Party{
+			Name:    inv.BuyerName,
+			Address: inv.BuyerAddress,
+			Email:   inv.BuyerEmail,
+		},
+		Subtotal:         inv.Subtotal,
+		Discount:         inv.Discount,
+		Shipping:         inv.Shipping,
+		ShippingDiscount: inv.ShippingDiscount,
+		TaxBase:          inv.TaxBase,
+		Tax:              inv.Tax,
+		TaxIncluded:      inv.TaxIncluded,
+		Total:            inv.Total,
+	}
+
+	// Ringkasan PPN per tarif dari item, jumlahnya sama dengan perhitungan per kelompok
+	type taxKey struct {
+		rate      float64
+		inclusive bool
+	}
+	summary := make(map[taxKey]*invoice.TaxLine)
+	var keys []taxKey
+	for _, item := range inv.Items {
+		rate := ""
+		if item.TaxRate > 0 {
+			rate = formatTaxRate(item.TaxRate)
+			key := taxKey{item.TaxRate, item.TaxInclusive}
+			line, ok := summary[key]
+			if !ok {
+				line = &invoice.TaxLine{Rate: rate, Inclusive: item.TaxInclusive, Base: money.IDR(0), Tax: money.IDR(0)}
+				summary[key] = line
+				keys = append(keys, key)
+			}
+			line.Base.Amount += item.TaxBase.Amount
+			line.Tax.Amount += item.Tax.Amount
+		}
+		doc.Items = append(doc.Items, invoice.Item{
+			Name:         item.Name,
+			Quantity:     item.Quantity,
+			UnitPrice:    item.UnitPrice,
+			Subtotal:     item.Subtotal,
+			TaxRate:      rate,
+			TaxInclusive: item.TaxInclusive,
+			Tax:          item.Tax,
+		})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].rate != keys[j].rate {
+			return keys[i].rate < keys[j].rate
+		}
+		return !keys[i].inclusive && keys[j].inclusive
+	})
+	for _, key := range keys {
+		doc.TaxLines = append(doc.TaxLines, *summary[key])
+	}
+
+	if !inv.SellerIsPKP {
+		doc.Notes = append(doc.Notes, "Penjual bukan Pengusaha Kena Pajak (PKP), tidak memungut PPN.")
+	} else if inv.TaxIncluded.Amount > 0 {
+		doc.Notes = append(doc.Notes, "Harga sudah termasuk PPN.")
+	}
+	doc.Notes = append(doc.Notes, "Invoice ini dibuat secara elektronik dan sah tanpa tanda tangan.")
+	return doc
+}
+
+func formatTaxRate(rate float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", rate), "0"), ".") + "%"
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func joinAddress(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, ", ")
+}
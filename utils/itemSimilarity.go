@@ -0,0 +1,315 @@
+// utils/itemSimilarity.go
+package utils
+
+import (
+	"database/sql/driver"
+	"ecommerce-golang/models"
+	"fmt"
+	"gorm.io/gorm"
+	"math"
+	"sort"
+	"time"
+)
+
+// Bobot interaksi user-produk: pembelian lebih kuat daripada sekadar masuk keranjang
+const (
+	purchaseWeight = 1.0
+	cartWeight     = 0.5
+)
+
+// ItemNeighborOptions - parameter job item-to-item similarity
+type ItemNeighborOptions struct {
+	TopN            int  // tetangga yang disimpan per produk
+	MinSupport      uint // minimal jumlah user yang berinteraksi dengan kedua produk
+	MaxItemsPerUser int  // hanya item terbaru per user, membatasi jumlah pasangan
+}
+
+func DefaultItemNeighborOptions() ItemNeighborOptions {
+	return ItemNeighborOptions{TopN: 20, MinSupport: 2, MaxItemsPerUser: 50}
+}
+
+type ItemNeighbor struct {
+	NeighborID uint
+	Score      float64
+	Support    uint
+}
+
+// LoadUserBaskets - produk yang pernah dibeli atau dimasukkan keranjang per user beserta bobotnya.
+// Jika produk ada di keduanya dipakai bobot pembelian.
+func LoadUserBaskets(db *gorm.DB, maxItemsPerUser int) (map[uint]map[uint]float64, error) {
+	type interaction struct {
+		UserID    uint
+		ProductID uint
+		LastAt    aggregateTime
+	}
+
+	var purchases, carts []interaction
+	if err := db.Table("product_user_histories").
+		Select("user_id, product_id, MAX(created_at) as last_at").
+		Group("user_id, product_id").
+		Scan(&purchases).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Table("product_user_carts").
+		Select("user_id, product_id, MAX(updated_at) as last_at").
+		Group("user_id, product_id").
+		Scan(&carts).Error; err != nil {
+		return nil, err
+	}
+
+	type weighted struct {
+		productID uint
+		weight    float64
+		lastAt    time.Time
+	}
+	perUser := make(map[uint]map[uint]*weighted)
+	add := func(rows []interaction, weight float64) {
+		for _, row := range rows {
+			items, ok := perUser[row.UserID]
+			if !ok {
+				items = make(map[uint]*weighted)
+				perUser[row.UserID] = items
+			}
+			item, ok := items[row.ProductID]
+			if !ok {
+				items[row.ProductID] = &weighted{row.ProductID, weight, row.LastAt.Time}
+				continue
+			}
+			item.weight = math.Max(item.weight, weight)
+			if row.LastAt.After(item.lastAt) {
+				item.lastAt = row.LastAt.Time
+			}
+		}
+	}
+	add(purchases, purchaseWeight)
+	add(carts, cartWeight)
+
+	baskets := make(map[uint]map[uint]float64, len(perUser))
+	for userID, items := range perUser {
+		list := make([]*weighted, 0, len(items))
+		for _, item := range items {
+			list = append(list, item)
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if !list[i].lastAt.Equal(list[j].lastAt) {
+				return list[i].lastAt.After(list[j].lastAt)
+			}
+			return list[i].productID < list[j].productID
+		})
+		if maxItemsPerUser > 0 && len(list) > maxItemsPerUser {
+			list = list[:maxItemsPerUser]
+		}
+
+		basket := make(map[uint]float64, len(list))
+		for _, item := range list {
+			basket[item.productID] = item.weight
+		}
+		baskets[userID] = basket
+	}
+	return baskets, nil
+}
+
+// aggregateTime - hasil MAX()/MIN() kolom waktu. MySQL (parseTime=True) mengembalikan time.Time,
+// SQLite (cmd/receval -synthetic) mengembalikan teks karena hasil agregat tidak punya tipe kolom.
+type aggregateTime struct {
+	time.Time
+}
+
+func (t aggregateTime) Value() (driver.Value, error) {
+	return t.Time, nil
+}
+
+func (t *aggregateTime) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		t.Time = time.Time{}
+	case time.Time:
+		t.Time = v
+	case []byte:
+		return t.parse(string(v))
+	case string:
+		return t.parse(v)
+	default:
+		return fmt.Errorf("cannot scan %T into time", src)
+	}
+	return nil
+}
+
+func (t *aggregateTime) parse(s string) error {
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999-07:00", "2006-01-02T15:04:05.999999999-07:00", "2006-01-02 15:04:05.999999999"} {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot parse time %q", s)
+}
+
+// ComputeItemNeighbors - cosine similarity antar produk dari keranjang/pembelian user.
+// Hasil per produk urut score tertinggi, seri diurutkan support lalu ID supaya deterministik.
+func ComputeItemNeighbors(baskets map[uint]map[uint]float64, opts ItemNeighborOptions) map[uint][]ItemNeighbor {
+	type pair struct{ a, b uint }
+
+	norms := make(map[uint]float64)
+	dots := make(map[pair]float64)
+	support := make(map[pair]uint)
+
+	for _, basket := range baskets {
+		items := make([]uint, 0, len(basket))
+		for productID, weight := range basket {
+			items = append(items, productID)
+			norms[productID] += weight * weight
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i] < items[j] })
+
+		for i := 0; i < len(items); i++ {
+			for j := i + 1; j < len(items); j++ {
+				p := pair{items[i], items[j]}
+				dots[p] += basket[p.a] * basket[p.b]
+				support[p]++
+			}
+		}
+	}
+
+	neighbors := make(map[uint][]ItemNeighbor)
+	for p, dot := range dots {
+		if support[p] < opts.MinSupport {
+			continue
+		}
+		score := dot / math.Sqrt(norms[p.a]*norms[p.b])
+		neighbors[p.a] = append(neighbors[p.a], ItemNeighbor{NeighborID: p.b, Score: score, Support: support[p]})
+		neighbors[p.b] = append(neighbors[p.b], ItemNeighbor{NeighborID: p.a, Score: score, Support: support[p]})
+	}
+
+	for productID, list := range neighbors {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Score != list[j].Score {
+				return list[i].Score > list[j].Score
+			}
+			if list[i].Support != list[j].Support {
+				return list[i].Support > list[j].Support
+			}
+			return list[i].NeighborID < list[j].NeighborID
+		})
+		if opts.TopN > 0 && len(list) > opts.TopN {
+			list = list[:opts.TopN]
+		}
+		neighbors[productID] = list
+	}
+	return neighbors
+}
+
+// SaveItemNeighbors - mengganti seluruh isi product_neighbors dalam satu transaksi,
+// pembaca tidak pernah melihat hasil setengah jadi
+func SaveItemNeighbors(db *gorm.DB, neighbors map[uint][]ItemNeighbor, now time.Time) (int, error) {
+	var rows []models.ProductNeighbor
+	for productID, list := range neighbors {
+		for i, neighbor := range list {
+			rows = append(rows, models.ProductNeighbor{
+				ProductID:  productID,
+				NeighborID: neighbor.NeighborID,
+				Rank:       uint(i + 1),
+				Score:      neighbor.Score,
+				Support:    neighbor.Support,
+				ComputedAt: now,
+			})
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.ProductNeighbor{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+	return len(rows), err
+}
+
+// RebuildItemNeighbors - job lengkap: baca interaksi, hitung similarity, simpan top-N
+func RebuildItemNeighbors(db *gorm.DB, opts ItemNeighborOptions, now time.Time) (products, rows int, err error) {
+	baskets, err := LoadUserBaskets(db, opts.MaxItemsPerUser)
+	if err != nil {
+		return 0, 0, err
+	}
+	neighbors := ComputeItemNeighbors(baskets, opts)
+	rows, err = SaveItemNeighbors(db, neighbors, now)
+	return len(neighbors), rows, err
+}
+
+// GetRelatedProducts - "customers also bought" untuk satu produk, urut rank
+func GetRelatedProducts(db *gorm.DB, productID uint, limit int) []models.ProductListView {
+	var products []models.ProductListView
+
+	db.Table("products").
+		Select(`products.id, products.name, products.price, products.rating, 
+                products.total_sold, products.images as image, products.category,
+                seller_profiles.shop_name, seller_profiles.city`).
+		Joins("JOIN product_neighbors ON product_neighbors.neighbor_id = products.id AND product_neighbors.product_id = ?", productID).
+		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
+		Where("products.is_active = ? AND products.stock > 0", true).
+		Order("product_neighbors.rank ASC").
+		Limit(limit).
+		Find(&products)
+
+	ProcessProductImages(&products)
+	return products
+}
+
+// GetUserSeedProducts - produk terakhir yang dibeli atau ada di keranjang user,
+// dipakai sebagai titik awal rekomendasi item-to-item
+func GetUserSeedProducts(db *gorm.DB, userID uint, limit int) []uint {
+	var purchased, carted []uint
+	db.Table("product_user_histories").
+		Select("product_id").
+		Where("user_id = ?", userID).
+		Group("product_id").
+		Order("MAX(created_at) DESC").
+		Limit(limit).
+		Pluck("product_id", &purchased)
+	db.Table("product_user_carts").
+		Where("user_id = ?", userID).
+		Order("updated_at DESC").
+		Limit(limit).
+		Pluck("product_id", &carted)
+
+	seen := make(map[uint]bool)
+	var seeds []uint
+	for _, id := range append(purchased, carted...) {
+		if !seen[id] && len(seeds) < limit {
+			seen[id] = true
+			seeds = append(seeds, id)
+		}
+	}
+	return seeds
+}
+
+// GetItemSimilarityRecommendations - tetangga dari beberapa produk sekaligus, score dijumlah
+// per produk tetangga. Produk seed sendiri tidak ikut direkomendasikan.
+func GetItemSimilarityRecommendations(db *gorm.DB, seedIDs []uint, limit int) []models.ProductListView {
+	var products []models.ProductListView
+	if len(seedIDs) == 0 {
+		return products
+	}
+
+	scores := db.Table("product_neighbors").
+		Select("neighbor_id, SUM(score) as score").
+		Where("product_id IN ? AND neighbor_id NOT IN ?", seedIDs, seedIDs).
+		Group("neighbor_id")
+
+	db.Table("products").
+		Select(`products.id, products.name, products.price, products.rating, 
+                products.total_sold, products.images as image, products.category,
+                seller_profiles.shop_name, seller_profiles.city`).
+		Joins("JOIN (?) AS neighbor_scores ON neighbor_scores.neighbor_id = products.id", scores).
+		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
+		Where("products.is_active = ? AND products.stock > 0", true).
+		Order("neighbor_scores.score DESC, products.id ASC").
+		Limit(limit).
+		Find(&products)
+
+	ProcessProductImages(&products)
+	return products
+}
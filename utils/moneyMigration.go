@@ -0,0 +1,60 @@
+// utils/moneyMigration.go
+package utils
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+	"strings"
+)
+
+// moneyColumns - kolom uang yang dulu DOUBLE/DECIMAL dan sekarang BIGINT rupiah
+var moneyColumns = []struct {
+	Table  string
+	Column string
+}{
+	{"products", "price"},
+	{"product_user_histories", "price"},
+	{"flash_sales", "sale_price"},
+	{"vouchers", "min_spend"},
+	{"vouchers", "max_discount"},
+}
+
+// MigrateMoneyColumns - membulatkan nilai lama ke rupiah penuh sebelum AutoMigrate mengubah
+// tipe kolom ke BIGINT, supaya ALTER TABLE tidak memotong pecahan (19999.99 jadi 19999).
+// Aman dipanggil berkali-kali: kolom yang sudah integer dilewati.
+func MigrateMoneyColumns(db *gorm.DB) error {
+	migrator := db.Migrator()
+	for _, mc := range moneyColumns {
+		if !migrator.HasTable(mc.Table) || !migrator.HasColumn(mc.Table, mc.Column) {
+			continue
+		}
+
+		columnTypes, err := migrator.ColumnTypes(mc.Table)
+		if err != nil {
+			return fmt.Errorf("read columns of %s: %w", mc.Table, err)
+		}
+		for _, columnType := range columnTypes {
+			if columnType.Name() != mc.Column || !isFractionalType(columnType.DatabaseTypeName()) {
+				continue
+			}
+
+			result := db.Exec(fmt.Sprintf("UPDATE `%s` SET `%s` = ROUND(`%s`) WHERE `%s` <> ROUND(`%s`)",
+				mc.Table, mc.Column, mc.Column, mc.Column, mc.Column))
+			if result.Error != nil {
+				return fmt.Errorf("round %s.%s: %w", mc.Table, mc.Column, result.Error)
+			}
+			log.Printf("Money migration: %s.%s %s -> BIGINT, %d rows rounded",
+				mc.Table, mc.Column, columnType.DatabaseTypeName(), result.RowsAffected)
+		}
+	}
+	return nil
+}
+
+func isFractionalType(name string) bool {
+	switch strings.ToLower(name) {
+	case "double", "float", "decimal", "real", "numeric":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,475 @@
+// utils/productImport.go
+package utils
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/money"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Skema kolom file import/export produk (CSV dan XLSX memakai skema yang sama).
+// Baris pertama wajib header dengan nama kolom di bawah ini (urutan bebas, case-insensitive).
+//
+//	sku          wajib, unik per seller, dipakai untuk upsert. Awalan "#" hanya untuk SKU
+//	             otomatis produk yang sudah ada (hasil export)
+//	name         wajib
+//	description  opsional
+//	price        wajib, angka > 0 (rupiah)
+//	stock        wajib, bilangan bulat >= 0
+//	category     wajib
+//	images       opsional, beberapa URL dipisah "|"
+//	weight       opsional, gram, angka >= 0
+//	dimensions   opsional, teks "panjang lebar tinggi" dalam cm, contoh "30 20 10"
+//	brand        opsional
+//	is_active    opsional, true/false (default true)
+//
+// Export menghasilkan kolom yang sama dengan urutan ProductImportColumns, sehingga
+// file hasil export bisa langsung di-import ulang tanpa kehilangan data.
+var ProductImportColumns = []string{
+	"sku", "name", "description", "price", "stock", "category",
+	"images", "weight", "dimensions", "brand", "is_active",
+}
+
+var requiredImportColumns = []string{"sku", "name", "price", "stock", "category"}
+
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatXLSX = "xlsx"
+
+	imageSeparator = "|"
+	xlsxSheetName  = "Products"
+)
+
+// MaxImportRows - batas jumlah baris per file import
+const MaxImportRows = 5000
+
+// ProductImportRow - satu baris data produk dari file import
+type ProductImportRow struct {
+	Row         int
+	SKU         string
+	Name        string
+	Description string
+	Price       money.Money
+	Stock       uint
+	Category    string
+	Images      []string
+	Weight      float64
+	Dimensions  string
+	Brand       string
+	IsActive    bool
+	Errors      []string
+}
+
+// ProductImportResult - ringkasan hasil import (atau simulasi pada dry-run)
+type ProductImportResult struct {
+	TotalRows    int                     `json:"total_rows"`
+	CreatedCount int                     `json:"created_count"`
+	UpdatedCount int                     `json:"updated_count"`
+	FailedCount  int                     `json:"failed_count"`
+	RowErrors    []models.ImportRowError `json:"row_errors"`
+}
+
+// DetectImportFormat - menentukan format dari ekstensi file
+func DetectImportFormat(fileName string) (string, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return ImportFormatCSV, nil
+	case ".xlsx":
+		return ImportFormatXLSX, nil
+	default:
+		return "", errors.New("unsupported file format, use .csv or .xlsx")
+	}
+}
+
+// ParseProductImportFile - membaca file CSV/XLSX dan memvalidasi setiap baris.
+// Error pada level file (header salah, file rusak) dikembalikan sebagai error,
+// error per baris disimpan di ProductImportRow.Errors.
+func ParseProductImportFile(format string, r io.Reader) ([]ProductImportRow, error) {
+	var records [][]string
+	var err error
+
+	switch format {
+	case ImportFormatCSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		records, err = reader.ReadAll()
+	case ImportFormatXLSX:
+		records, err = readXLSXRecords(r)
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if len(records) == 0 {
+		return nil, errors.New("file is empty")
+	}
+	if len(records)-1 > MaxImportRows {
+		return nil, fmt.Errorf("too many rows: maximum %d per file", MaxImportRows)
+	}
+
+	columnIndex, err := parseImportHeader(records[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []ProductImportRow
+	seenSKU := make(map[string]int)
+	for i, record := range records[1:] {
+		if isEmptyRecord(record) {
+			continue
+		}
+
+		row := parseImportRecord(i+2, record, columnIndex)
+		if row.SKU != "" {
+			if firstRow, exists := seenSKU[row.SKU]; exists {
+				row.Errors = append(row.Errors, fmt.Sprintf("duplicate sku, already used on row %d", firstRow))
+			} else {
+				seenSKU[row.SKU] = row.Row
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func readXLSXRecords(r io.Reader) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, errors.New("workbook has no sheets")
+	}
+	return f.GetRows(sheets[0], excelize.Options{RawCellValue: true})
+}
+
+func parseImportHeader(header []string) (map[string]int, error) {
+	columnIndex := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if name == "" {
+			continue
+		}
+		if !isImportColumn(name) {
+			return nil, fmt.Errorf("unknown column %q, allowed columns: %s", name, strings.Join(ProductImportColumns, ", "))
+		}
+		if _, exists := columnIndex[name]; exists {
+			return nil, fmt.Errorf("duplicate column %q", name)
+		}
+		columnIndex[name] = i
+	}
+
+	for _, required := range requiredImportColumns {
+		if _, exists := columnIndex[required]; !exists {
+			return nil, fmt.Errorf("missing required column %q", required)
+		}
+	}
+	return columnIndex, nil
+}
+
+func isImportColumn(name string) bool {
+	for _, column := range ProductImportColumns {
+		if column == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func parseImportRecord(rowNumber int, record []string, columnIndex map[string]int) ProductImportRow {
+	getRaw := func(column string) string {
+		idx, exists := columnIndex[column]
+		if !exists || idx >= len(record) {
+			return ""
+		}
+		return record[idx]
+	}
+	get := func(column string) string {
+		return strings.TrimSpace(getRaw(column))
+	}
+
+	row := ProductImportRow{
+		Row:         rowNumber,
+		SKU:         get("sku"),
+		Name:        get("name"),
+		Description: getRaw("description"), // tidak di-trim supaya round-trip tetap sama persis
+		Category:    get("category"),
+		Dimensions:  get("dimensions"),
+		Brand:       get("brand"),
+		IsActive:    true,
+	}
+
+	if row.SKU == "" {
+		row.Errors = append(row.Errors, "sku is required")
+	} else if len(row.SKU) > 100 {
+		row.Errors = append(row.Errors, "sku must be at most 100 characters")
+	}
+	if row.Name == "" {
+		row.Errors = append(row.Errors, "name is required")
+	} else if len(row.Name) > 255 {
+		row.Errors = append(row.Errors, "name must be at most 255 characters")
+	}
+	if row.Category == "" {
+		row.Errors = append(row.Errors, "category is required")
+	}
+
+	if price, err := strconv.ParseFloat(get("price"), 64); err != nil {
+		row.Errors = append(row.Errors, "price must be a number")
+	} else if price < 1 {
+		row.Errors = append(row.Errors, "price must be at least 1")
+	} else {
+		row.Price = money.FromFloat(price)
+	}
+
+	if stock, err := strconv.ParseUint(get("stock"), 10, 32); err != nil {
+		row.Errors = append(row.Errors, "stock must be a non-negative integer")
+	} else {
+		row.Stock = uint(stock)
+	}
+
+	if weight := get("weight"); weight != "" {
+		if w, err := strconv.ParseFloat(weight, 64); err != nil || w < 0 {
+			row.Errors = append(row.Errors, "weight must be a non-negative number")
+		} else {
+			row.Weight = w
+		}
+	}
+
+	if images := get("images"); images != "" {
+		for _, image := range strings.Split(images, imageSeparator) {
+			if image = strings.TrimSpace(image); image != "" {
+				row.Images = append(row.Images, image)
+			}
+		}
+	}
+
+	if isActive := get("is_active"); isActive != "" {
+		switch strings.ToLower(isActive) {
+		case "true", "1", "yes", "ya":
+			row.IsActive = true
+		case "false", "0", "no", "tidak":
+			row.IsActive = false
+		default:
+			row.Errors = append(row.Errors, "is_active must be true or false")
+		}
+	}
+
+	return row
+}
+
+// ImportProducts - upsert produk berdasarkan SKU seller. Jika dryRun true tidak ada yang ditulis,
+// hanya menghitung berapa yang akan dibuat/diupdate dan mengembalikan error per baris.
+func ImportProducts(db *gorm.DB, sellerID uint, rows []ProductImportRow, dryRun bool) ProductImportResult {
+	result := ProductImportResult{
+		TotalRows: len(rows),
+		RowErrors: []models.ImportRowError{},
+	}
+
+	for _, row := range rows {
+		if len(row.Errors) > 0 {
+			result.FailedCount++
+			result.RowErrors = append(result.RowErrors, models.ImportRowError{Row: row.Row, SKU: row.SKU, Errors: row.Errors})
+			continue
+		}
+
+		var product models.Product
+		err := db.Where("seller_id = ? AND sku = ?", sellerID, row.SKU).First(&product).Error
+		exists := err == nil
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			result.FailedCount++
+			result.RowErrors = append(result.RowErrors, models.ImportRowError{Row: row.Row, SKU: row.SKU, Errors: []string{"database error"}})
+			continue
+		}
+
+		if !exists && models.IsAutoSKU(row.SKU) {
+			result.FailedCount++
+			result.RowErrors = append(result.RowErrors, models.ImportRowError{Row: row.Row, SKU: row.SKU, Errors: []string{"sku prefix " + models.AutoSKUPrefix + " is reserved for generated SKUs"}})
+			continue
+		}
+
+		if dryRun {
+			if exists {
+				result.UpdatedCount++
+			} else {
+				result.CreatedCount++
+			}
+			continue
+		}
+
+		applyImportRow(&product, row)
+		product.SellerID = sellerID
+
+		if err := db.Save(&product).Error; err != nil {
+			message := "failed to save product"
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				message = "sku already used by another product"
+			}
+			result.FailedCount++
+			result.RowErrors = append(result.RowErrors, models.ImportRowError{Row: row.Row, SKU: row.SKU, Errors: []string{message}})
+			continue
+		}
+
+		if exists {
+			result.UpdatedCount++
+		} else {
+			result.CreatedCount++
+		}
+	}
+
+	return result
+}
+
+func applyImportRow(product *models.Product, row ProductImportRow) {
+	product.SKU = row.SKU
+	product.Name = row.Name
+	product.Description = row.Description
+	product.Price = row.Price
+	product.Stock = row.Stock
+	product.Category = row.Category
+	product.Images = row.Images
+	product.Weight = row.Weight
+	product.Dimensions = row.Dimensions
+	product.Length, product.Width, product.Height = 0, 0, 0
+	NormalizeProductDimensions(product)
+	product.Brand = row.Brand
+	product.IsActive = row.IsActive
+}
+
+// RunProductImportJob - memproses job import di background dan menyimpan hasilnya ke tabel job
+func RunProductImportJob(db *gorm.DB, jobID uint, sellerID uint, rows []ProductImportRow) {
+	defer func() {
+		if r := recover(); r != nil {
+			db.Model(&models.ProductImportJob{ID: jobID}).Updates(map[string]interface{}{
+				"status":        models.ImportJobFailed,
+				"error_message": fmt.Sprintf("import aborted: %v", r),
+				"finished_at":   time.Now(),
+			})
+		}
+	}()
+
+	db.Model(&models.ProductImportJob{}).Where("id = ?", jobID).Update("status", models.ImportJobProcessing)
+
+	result := ImportProducts(db, sellerID, rows, false)
+	finishedAt := time.Now()
+
+	db.Model(&models.ProductImportJob{ID: jobID}).
+		Select("status", "total_rows", "created_count", "updated_count", "failed_count", "row_errors", "finished_at").
+		Updates(models.ProductImportJob{
+			Status:       models.ImportJobCompleted,
+			TotalRows:    result.TotalRows,
+			CreatedCount: result.CreatedCount,
+			UpdatedCount: result.UpdatedCount,
+			FailedCount:  result.FailedCount,
+			RowErrors:    result.RowErrors,
+			FinishedAt:   &finishedAt,
+		})
+}
+
+// EnsureProductSKUs - produk lama yang belum punya SKU diberi SKU otomatis "#P<id>" agar bisa
+// di-export dan di-import ulang (round-trip) tanpa membuat produk duplikat
+func EnsureProductSKUs(db *gorm.DB, sellerID uint) error {
+	return db.Model(&models.Product{}).
+		Where("seller_id = ? AND (sku = '' OR sku IS NULL)", sellerID).
+		Update("sku", gorm.Expr("CONCAT(?, 'P', id)", models.AutoSKUPrefix)).Error
+}
+
+// BackfillProductSKUs - EnsureProductSKUs untuk semua seller. Dijalankan sebelum AutoMigrate
+// membuat unique index (seller_id, sku), karena SKU kosong yang sama akan membuat index gagal dibuat.
+func BackfillProductSKUs(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.Product{}, "SKU") {
+		return nil
+	}
+	return db.Model(&models.Product{}).
+		Where("sku = '' OR sku IS NULL").
+		Update("sku", gorm.Expr("CONCAT(?, 'P', id)", models.AutoSKUPrefix)).Error
+}
+
+// ProductExportRecord - konversi produk ke satu baris sesuai ProductImportColumns
+func ProductExportRecord(product models.Product) []string {
+	return []string{
+		product.SKU,
+		product.Name,
+		product.Description,
+		strconv.FormatInt(product.Price.Amount, 10),
+		strconv.FormatUint(uint64(product.Stock), 10),
+		product.Category,
+		strings.Join(product.Images, imageSeparator),
+		strconv.FormatFloat(product.Weight, 'f', -1, 64),
+		product.Dimensions,
+		product.Brand,
+		strconv.FormatBool(product.IsActive),
+	}
+}
+
+// WriteProductsCSV - export produk ke format CSV
+func WriteProductsCSV(w io.Writer, products []models.Product) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(ProductImportColumns); err != nil {
+		return err
+	}
+	for _, product := range products {
+		if err := writer.Write(ProductExportRecord(product)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteProductsXLSX - export produk ke format XLSX
+func WriteProductsXLSX(w io.Writer, products []models.Product) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName("Sheet1", xlsxSheetName); err != nil {
+		return err
+	}
+
+	writeRow := func(rowNumber int, record []string) error {
+		cell, err := excelize.CoordinatesToCellName(1, rowNumber)
+		if err != nil {
+			return err
+		}
+		values := make([]interface{}, len(record))
+		for i, value := range record {
+			values[i] = value
+		}
+		return f.SetSheetRow(xlsxSheetName, cell, &values)
+	}
+
+	if err := writeRow(1, ProductImportColumns); err != nil {
+		return err
+	}
+	for i, product := range products {
+		if err := writeRow(i+2, ProductExportRecord(product)); err != nil {
+			return err
+		}
+	}
+
+	_, err := f.WriteTo(w)
+	return err
+}
@@ -0,0 +1,182 @@
+// utils/productViews.go
+package utils
+
+import (
+	"ecommerce-golang/models"
+	"encoding/json"
+	"gorm.io/gorm"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// ViewHistoryRetention - umur maksimal riwayat view, dari env VIEW_HISTORY_RETENTION (default 90 hari)
+func ViewHistoryRetention() time.Duration {
+	if retention, err := time.ParseDuration(os.Getenv("VIEW_HISTORY_RETENTION")); err == nil && retention > 0 {
+		return retention
+	}
+	return 90 * 24 * time.Hour
+}
+
+// ViewDecayWeight - bobot view yang meluruh setengahnya setiap halfLife
+func ViewDecayWeight(age, halfLife time.Duration) float64 {
+	if age < 0 {
+		age = 0
+	}
+	return math.Pow(0.5, age.Hours()/halfLife.Hours())
+}
+
+// viewOwner - filter view milik user (jika login) atau visitor anonim
+func viewOwner(query *gorm.DB, userID uint, visitorID string) *gorm.DB {
+	if userID > 0 {
+		return query.Where("product_views.user_id = ?", userID)
+	}
+	return query.Where("product_views.user_id IS NULL AND product_views.visitor_id = ?", visitorID)
+}
+
+// RecentlyViewedProduct - produk di halaman "terakhir dilihat"
+type RecentlyViewedProduct struct {
+	models.ProductListView
+	ViewedAt time.Time `json:"viewed_at"`
+}
+
+// GetRecentlyViewed - produk aktif yang terakhir dilihat user, satu baris per produk
+func GetRecentlyViewed(db *gorm.DB, userID uint, limit, offset int) ([]RecentlyViewedProduct, int64, error) {
+	var total int64
+	if err := db.Table("product_views").
+		Joins("JOIN products ON product_views.product_id = products.id").
+		Where("product_views.user_id = ? AND products.is_active = ?", userID, true).
+		Distinct("product_views.product_id").
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var products []RecentlyViewedProduct
+	lastViews := db.Table("product_views").
+		Select("product_id, MAX(viewed_at) as viewed_at").
+		Where("user_id = ?", userID).
+		Group("product_id")
+	err := db.Table("products").
+		Select(`products.id, products.name, products.price, products.rating, 
+                products.total_sold, products.images as image, products.category,
+                seller_profiles.shop_name, seller_profiles.city, last_views.viewed_at`).
+		Joins("JOIN (?) AS last_views ON last_views.product_id = products.id", lastViews).
+		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
+		Where("products.is_active = ?", true).
+		Order("last_views.viewed_at DESC, products.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range products {
+		var imageList []string
+		if json.Unmarshal([]byte(products[i].Image), &imageList) == nil && len(imageList) > 0 {
+			products[i].Image = ThumbnailURL(imageList[0])
+		} else {
+			products[i].Image = ""
+		}
+	}
+	return products, total, nil
+}
+
+// GetRecentlyViewedProductIDs - produk terakhir dilihat user/visitor, terbaru lebih dulu
+func GetRecentlyViewedProductIDs(db *gorm.DB, userID uint, visitorID string, limit int) []uint {
+	var ids []uint
+	if userID == 0 && visitorID == "" {
+		return ids
+	}
+	viewOwner(db.Table("product_views"), userID, visitorID).
+		Group("product_views.product_id").
+		Order("MAX(product_views.viewed_at) DESC").
+		Limit(limit).
+		Pluck("product_views.product_id", &ids)
+	return ids
+}
+
+// GetTopCategoriesByViews - kategori dengan bobot view tertinggi, view lama meluruh dengan halfLife
+func GetTopCategoriesByViews(db *gorm.DB, userID uint, visitorID string, now time.Time, halfLife time.Duration, limit int) []string {
+	var categories []string
+	if userID == 0 && visitorID == "" {
+		return categories
+	}
+
+	var views []struct {
+		Category string
+		ViewedAt time.Time
+	}
+	viewOwner(db.Table("product_views"), userID, visitorID).
+		Select("products.category, product_views.viewed_at").
+		Joins("JOIN products ON product_views.product_id = products.id").
+		Where("product_views.viewed_at > ?", now.Add(-8*halfLife)).
+		Order("product_views.viewed_at DESC").
+		Limit(500).
+		Scan(&views)
+
+	scores := make(map[string]float64)
+	for _, view := range views {
+		if view.Category == "" {
+			continue
+		}
+		if _, ok := scores[view.Category]; !ok {
+			categories = append(categories, view.Category)
+		}
+		scores[view.Category] += ViewDecayWeight(now.Sub(view.ViewedAt), halfLife)
+	}
+	sort.SliceStable(categories, func(i, j int) bool { return scores[categories[i]] > scores[categories[j]] })
+	if len(categories) > limit {
+		categories = categories[:limit]
+	}
+	return categories
+}
+
+// ClearViewHistory - menghapus riwayat view user, productID 0 berarti semua produk.
+// Penghapusan semua produk mencatat view_history_cleared_at supaya view yang masih
+// di buffer ViewRecorder (dilihat sebelum now) tidak ditulis kembali setelahnya.
+func ClearViewHistory(db *gorm.DB, userID, productID uint, now time.Time) (int64, error) {
+	var deleted int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("user_id = ?", userID)
+		if productID > 0 {
+			query = query.Where("product_id = ?", productID)
+		} else if err := tx.Model(&models.UserProfile{}).Where("user_id = ?", userID).
+			Update("view_history_cleared_at", now).Error; err != nil {
+			return err
+		}
+		result := query.Delete(&models.ProductView{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+// AttachVisitorViews - view anonim dari visitor dipindahkan ke user saat login
+func AttachVisitorViews(db *gorm.DB, visitorID string, userID uint) error {
+	if visitorID == "" {
+		return nil
+	}
+	return db.Model(&models.ProductView{}).
+		Where("visitor_id = ? AND user_id IS NULL", visitorID).
+		Update("user_id", userID).Error
+}
+
+// StartViewHistoryCleanup - goroutine yang menghapus view lebih tua dari ViewHistoryRetention tiap jam
+func StartViewHistoryCleanup(db *gorm.DB) {
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			result := db.Where("viewed_at < ?", time.Now().Add(-ViewHistoryRetention())).Delete(&models.ProductView{})
+			if result.Error != nil {
+				log.Printf("Delete old product views failed: %v", result.Error)
+			} else if result.RowsAffected > 0 {
+				log.Printf("Deleted %d old product views", result.RowsAffected)
+			}
+		}
+	}()
+}
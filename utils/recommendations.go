@@ -58,36 +58,109 @@ func GetTopCategoriesByCart(db *gorm.DB, userID uint) []string {
 	return categories
 }
 
-// GetAnonymousRecommendations - rekomendasi untuk anonymous user
-func GetAnonymousRecommendations(db *gorm.DB) []models.ProductListView {
+// CheckUserHasWishlistOrFollows - mengecek apakah user punya wishlist atau mengikuti toko
+func CheckUserHasWishlistOrFollows(db *gorm.DB, userID uint) bool {
+	var count int64
+	db.Table("wishlist_items").Where("user_id = ?", userID).Count(&count)
+	if count > 0 {
+		return true
+	}
+	db.Table("shop_follows").Where("user_id = ?", userID).Count(&count)
+	return count > 0
+}
+
+// GetTopCategoriesByWishlist - kategori yang paling banyak disimpan di wishlist
+func GetTopCategoriesByWishlist(db *gorm.DB, userID uint, limit int) []string {
+	var results []struct {
+		Category string
+		Count    int64
+	}
+
+	db.Table("wishlist_items").
+		Select("products.category, COUNT(*) as count").
+		Joins("JOIN products ON wishlist_items.product_id = products.id").
+		Where("wishlist_items.user_id = ? AND products.is_active = ?", userID, true).
+		Group("products.category").
+		Order("count DESC, MAX(wishlist_items.created_at) DESC").
+		Limit(limit).
+		Find(&results)
+
+	var categories []string
+	for _, result := range results {
+		categories = append(categories, result.Category)
+	}
+
+	return categories
+}
+
+// GetFollowedShopRecommendations - produk terlaris dan terbaru dari toko yang diikuti user,
+// tidak termasuk yang sudah ada di wishlist
+func GetFollowedShopRecommendations(db *gorm.DB, userID uint, limit int) []models.ProductListView {
 	var products []models.ProductListView
 
-	// Coba ambil berdasarkan rating/total_sold
-	err := db.Table("products").
+	db.Table("products").
 		Select(`products.id, products.name, products.price, products.rating, 
-                products.total_sold, products.images,
+                products.total_sold, products.images as image, products.category,
                 seller_profiles.shop_name, seller_profiles.city`).
+		Joins("JOIN shop_follows ON shop_follows.seller_id = products.seller_id AND shop_follows.user_id = ?", userID).
 		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
+		Joins("LEFT JOIN wishlist_items ON wishlist_items.product_id = products.id AND wishlist_items.user_id = ?", userID).
 		Where("products.is_active = ? AND products.stock > 0", true).
-		Where("(products.rating >= 4.0 OR products.total_sold >= 10)").
-		Order("(products.rating * 0.7 + (products.total_sold / 100.0) * 0.3) DESC").
-		Limit(20).
-		Find(&products).Error
+		Where("wishlist_items.id IS NULL").
+		Order("(products.rating * 0.5 + (products.total_sold / 100.0) * 0.3) DESC, products.created_at DESC").
+		Limit(limit).
+		Find(&products)
 
-	// Jika kosong, ambil produk aktif secara random
-	if err != nil || len(products) == 0 {
+	ProcessProductImages(&products)
+	return products
+}
+
+// GetAnonymousRecommendations - rekomendasi untuk anonymous user: produk trending 7 hari
+// terakhir, ditambah best rating/seller jika ranking trending belum cukup
+func GetAnonymousRecommendations(db *gorm.DB) []models.ProductListView {
+	const limit = 20
+
+	products, _, err := GetTrendingProducts(db, models.TrendingWindowWeek, "", limit)
+	if err != nil {
+		products = nil
+	}
+
+	if len(products) < limit {
+		exclude := []uint{0}
+		for _, p := range products {
+			exclude = append(exclude, p.ID)
+		}
+
+		var bestProducts []models.ProductListView
 		db.Table("products").
 			Select(`products.id, products.name, products.price, products.rating, 
-                    products.total_sold, products.images,
+                products.total_sold, products.images as image, products.category,
+                seller_profiles.shop_name, seller_profiles.city`).
+			Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
+			Where("products.is_active = ? AND products.stock > 0", true).
+			Where("products.id NOT IN ?", exclude).
+			Where("(products.rating >= 4.0 OR products.total_sold >= 10)").
+			Order("(products.rating * 0.7 + (products.total_sold / 100.0) * 0.3) DESC").
+			Limit(limit - len(products)).
+			Find(&bestProducts)
+		ProcessProductImages(&bestProducts)
+		products = append(products, bestProducts...)
+	}
+
+	// Toko baru tanpa penjualan/rating: tampilkan produk aktif terbaru
+	if len(products) == 0 {
+		db.Table("products").
+			Select(`products.id, products.name, products.price, products.rating, 
+                    products.total_sold, products.images as image, products.category,
                     seller_profiles.shop_name, seller_profiles.city`).
 			Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
 			Where("products.is_active = ? AND products.stock > 0", true).
-			Order("RAND()").
-			Limit(20).
+			Order("products.created_at DESC, products.id DESC").
+			Limit(limit).
 			Find(&products)
+		ProcessProductImages(&products)
 	}
 
-	ProcessProductImages(&products)
 	return products
 }
 
@@ -98,7 +171,7 @@ func GetCategoryBasedRecommendations(db *gorm.DB, categories []string, userID ui
 	// Query untuk produk dari kategori yang diminati
 	categoryQuery := db.Table("products").
 		Select(`products.id, products.name, products.price, products.rating, 
-                products.total_sold, products.images,
+                products.total_sold, products.images as image, products.category,
                 seller_profiles.shop_name, seller_profiles.city`).
 		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
 		Where("products.is_active = ? AND products.stock > 0", true).
@@ -115,7 +188,7 @@ func GetCategoryBasedRecommendations(db *gorm.DB, categories []string, userID ui
 	var additionalProducts []models.ProductListView
 	additionalQuery := db.Table("products").
 		Select(`products.id, products.name, products.price, products.rating, 
-                products.total_sold, products.images,
+                products.total_sold, products.images as image, products.category,
                 seller_profiles.shop_name, seller_profiles.city`).
 		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
 		Where("products.is_active = ? AND products.stock > 0", true).
@@ -142,21 +215,32 @@ func ProcessProductImages(products *[]models.ProductListView) {
 		var imageList []string
 		err := json.Unmarshal([]byte((*products)[i].Image), &imageList)
 		if err == nil && len(imageList) > 0 {
-			(*products)[i].Image = imageList[0]
+			(*products)[i].Image = ThumbnailURL(imageList[0])
 		} else {
 			(*products)[i].Image = ""
 		}
 	}
 }
 
+// ProductIDSet - ID produk yang sudah dipakai, untuk melewati duplikat saat menggabungkan daftar produk
+type ProductIDSet map[uint]bool
+
+// Add - menandai id sebagai dipakai, false jika id sudah ada sebelumnya
+func (s ProductIDSet) Add(id uint) bool {
+	if s[id] {
+		return false
+	}
+	s[id] = true
+	return true
+}
+
 // RemoveDuplicateProducts - menghapus duplikat produk
 func RemoveDuplicateProducts(products []models.ProductListView) []models.ProductListView {
-	seen := make(map[uint]bool)
+	seen := make(ProductIDSet)
 	var result []models.ProductListView
 
 	for _, product := range products {
-		if !seen[product.ID] {
-			seen[product.ID] = true
+		if seen.Add(product.ID) {
 			result = append(result, product)
 		}
 	}
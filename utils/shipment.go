@@ -0,0 +1,96 @@
+// utils/shipment.go
+package utils
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/shipping"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"time"
+)
+
+// Status yang boleh datang dari event tracking kurir
+var trackingStatuses = map[string]bool{
+	models.ShipmentPickedUp:       true,
+	models.ShipmentInTransit:      true,
+	models.ShipmentOutForDelivery: true,
+	models.ShipmentDelivered:      true,
+	models.ShipmentFailed:         true,
+	models.ShipmentReturned:       true,
+}
+
+// AppendShipmentEvents - menyimpan event tracking secara idempotent (event yang sudah ada diabaikan)
+// lalu menyesuaikan status shipment dengan event terbaru. Mengembalikan jumlah event baru.
+func AppendShipmentEvents(db *gorm.DB, shipmentID uint, events []shipping.TrackingEvent) (int, error) {
+	added := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var shipment models.Shipment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&shipment, shipmentID).Error; err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if !trackingStatuses[event.Status] || event.OccurredAt.IsZero() {
+				continue
+			}
+			record := models.ShipmentEvent{
+				ShipmentID:  shipment.ID,
+				ExternalID:  event.EventID(),
+				Status:      event.Status,
+				Description: event.Description,
+				Location:    event.Location,
+				OccurredAt:  event.OccurredAt,
+			}
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected > 0 {
+				added++
+			}
+		}
+
+		// Shipment yang sudah selesai tidak diubah lagi
+		if added == 0 || shipment.Status == models.ShipmentCompleted {
+			return nil
+		}
+
+		// Event bisa datang tidak berurutan, status diambil dari event dengan waktu terbaru
+		var latest models.ShipmentEvent
+		if err := tx.Where("shipment_id = ?", shipment.ID).Order("occurred_at DESC, id DESC").First(&latest).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"status": latest.Status}
+		if latest.Status == models.ShipmentDelivered && shipment.DeliveredAt == nil {
+			updates["delivered_at"] = latest.OccurredAt
+		}
+		return tx.Model(&shipment).Updates(updates).Error
+	})
+	return added, err
+}
+
+// AutoCompleteDeliveredShipments - shipment yang sudah delivered lebih lama dari grace period
+// otomatis ditandai completed (buyer tidak komplain dalam jangka waktu tersebut)
+func AutoCompleteDeliveredShipments(db *gorm.DB, grace time.Duration, now time.Time) (int64, error) {
+	result := db.Model(&models.Shipment{}).
+		Where("status = ? AND delivered_at IS NOT NULL AND delivered_at <= ?", models.ShipmentDelivered, now.Add(-grace)).
+		Updates(map[string]interface{}{
+			"status":       models.ShipmentCompleted,
+			"completed_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// StartShipmentAutoCompleteRoutine - menjalankan AutoCompleteDeliveredShipments secara berkala
+func StartShipmentAutoCompleteRoutine(db *gorm.DB, grace time.Duration) {
+	ticker := time.NewTicker(10 * time.Minute)
+	go func() {
+		for range ticker.C {
+			if _, err := AutoCompleteDeliveredShipments(db, grace, time.Now()); err != nil {
+				log.Printf("Auto-complete shipments failed: %v", err)
+			}
+		}
+	}()
+}
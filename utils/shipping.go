@@ -0,0 +1,135 @@
+// utils/shipping.go
+package utils
+
+import (
+	"context"
+	"ecommerce-golang/models"
+	"ecommerce-golang/shipping"
+	"gorm.io/gorm"
+	"sort"
+)
+
+// NormalizeProductDimensions - menyamakan field dimensi terstruktur dengan teks Dimensions.
+// Jika panjang/lebar/tinggi diisi, teks dibuat ulang; jika hanya teks yang ada, teks di-parse.
+func NormalizeProductDimensions(product *models.Product) {
+	if product.Length > 0 && product.Width > 0 && product.Height > 0 {
+		if product.Dimensions == "" {
+			product.Dimensions = shipping.FormatDimensions(product.Length, product.Width, product.Height)
+		}
+		return
+	}
+	if length, width, height, ok := shipping.ParseDimensions(product.Dimensions); ok {
+		product.Length, product.Width, product.Height = length, width, height
+	}
+}
+
+// BackfillProductDimensions - migrasi data lama: isi length/width/height dari teks dimensions
+func BackfillProductDimensions(db *gorm.DB) error {
+	var products []models.Product
+	err := db.Select("id", "dimensions").
+		Where("dimensions <> '' AND (length = 0 OR width = 0 OR height = 0)").
+		Find(&products).Error
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		length, width, height, ok := shipping.ParseDimensions(product.Dimensions)
+		if !ok {
+			continue
+		}
+		db.Model(&models.Product{}).Where("id = ?", product.ID).Updates(map[string]interface{}{
+			"length": length,
+			"width":  width,
+			"height": height,
+		})
+	}
+	return nil
+}
+
+// SellerShippingQuote - opsi pengiriman untuk item keranjang dari satu seller
+type SellerShippingQuote struct {
+	SellerID        uint             `json:"seller_id"`
+	ShopName        string           `json:"shop_name"`
+	OriginCity      string           `json:"origin_city"`
+	DestinationCity string           `json:"destination_city"`
+	ItemCount       uint             `json:"item_count"`
+	WeightGrams     float64          `json:"weight_grams"`
+	VolumetricGrams float64          `json:"volumetric_weight_grams"`
+	ChargeableGrams float64          `json:"chargeable_weight_grams"`
+	Options         []shipping.Quote `json:"options"`
+	Error           string           `json:"error,omitempty"`
+}
+
+// GetCartShippingQuotes - menghitung ongkir per seller untuk item aktif yang dicentang di keranjang user
+func GetCartShippingQuotes(ctx context.Context, db *gorm.DB, provider shipping.ShippingProvider, userID uint, destinationCity string) ([]SellerShippingQuote, error) {
+	var lines []struct {
+		SellerID uint
+		ShopName string
+		City     string
+		Quantity uint
+		Weight   float64
+		Length   float64
+		Width    float64
+		Height   float64
+	}
+
+	err := db.Table("product_user_carts").
+		Select(`products.seller_id, seller_profiles.shop_name, seller_profiles.city,
+				product_user_carts.quantity, products.weight,
+				products.length, products.width, products.height`).
+		Joins("JOIN products ON product_user_carts.product_id = products.id").
+		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
+		Where("product_user_carts.user_id = ? AND products.is_active = ?", userID, true).
+		Where("product_user_carts.saved_for_later = ? AND product_user_carts.selected = ?", false, true).
+		Find(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[uint]*SellerShippingQuote)
+	var sellerIDs []uint
+	for _, line := range lines {
+		group, exists := groups[line.SellerID]
+		if !exists {
+			group = &SellerShippingQuote{
+				SellerID:        line.SellerID,
+				ShopName:        line.ShopName,
+				OriginCity:      line.City,
+				DestinationCity: destinationCity,
+			}
+			groups[line.SellerID] = group
+			sellerIDs = append(sellerIDs, line.SellerID)
+		}
+
+		quantity := float64(line.Quantity)
+		group.ItemCount += line.Quantity
+		group.WeightGrams += line.Weight * quantity
+		group.VolumetricGrams += shipping.VolumetricWeightGrams(line.Length, line.Width, line.Height) * quantity
+	}
+
+	sort.Slice(sellerIDs, func(i, j int) bool { return sellerIDs[i] < sellerIDs[j] })
+
+	quotes := make([]SellerShippingQuote, 0, len(sellerIDs))
+	for _, sellerID := range sellerIDs {
+		group := groups[sellerID]
+		pkg := shipping.Package{
+			OriginCity:      group.OriginCity,
+			DestinationCity: group.DestinationCity,
+			WeightGrams:     group.WeightGrams,
+			VolumetricGrams: group.VolumetricGrams,
+		}
+		group.ChargeableGrams = pkg.ChargeableGrams()
+
+		if group.OriginCity == "" {
+			group.Error = "seller has not set shop city"
+		} else if options, err := provider.Quote(ctx, pkg); err != nil {
+			group.Error = err.Error()
+		} else {
+			group.Options = options
+		}
+		quotes = append(quotes, *group)
+	}
+
+	return quotes, nil
+}
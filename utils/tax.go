@@ -0,0 +1,90 @@
+// utils/tax.go
+package utils
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/pricing"
+	"errors"
+	"gorm.io/gorm"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidNPWP = errors.New("NPWP must be 15 or 16 digits")
+
+// DefaultTaxRate - tarif PPN umum dalam persen dari env PPN_RATE, default 11.
+// Dipakai jika tidak ada TaxRule dengan kategori kosong.
+func DefaultTaxRate() float64 {
+	if rate, err := strconv.ParseFloat(os.Getenv("PPN_RATE"), 64); err == nil && rate >= 0 {
+		return rate
+	}
+	return 11
+}
+
+// TaxRules - tarif PPN (basis point) per kategori, hasil LoadTaxRules
+type TaxRules struct {
+	Default    int64
+	ByCategory map[string]int64
+}
+
+// LoadTaxRules - membaca semua TaxRule dari database
+func LoadTaxRules(db *gorm.DB) (TaxRules, error) {
+	rules := TaxRules{
+		Default:    pricing.PercentToBasisPoints(DefaultTaxRate()),
+		ByCategory: make(map[string]int64),
+	}
+
+	var rows []models.TaxRule
+	if err := db.Find(&rows).Error; err != nil {
+		return rules, err
+	}
+	for _, row := range rows {
+		bp := pricing.PercentToBasisPoints(row.Rate)
+		if category := strings.ToLower(strings.TrimSpace(row.Category)); category != "" {
+			rules.ByCategory[category] = bp
+		} else {
+			rules.Default = bp
+		}
+	}
+	return rules, nil
+}
+
+// RateFor - tarif PPN untuk kategori produk
+func (r TaxRules) RateFor(category string) int64 {
+	if bp, ok := r.ByCategory[strings.ToLower(strings.TrimSpace(category))]; ok {
+		return bp
+	}
+	return r.Default
+}
+
+// LineTax - PPN untuk satu produk. Seller non-PKP tidak memungut PPN.
+func (r TaxRules) LineTax(isPKP bool, priceTaxMode, category string) pricing.Tax {
+	if !isPKP {
+		return pricing.Tax{}
+	}
+	return pricing.Tax{
+		BasisPoints: r.RateFor(category),
+		Inclusive:   priceTaxMode != models.PriceTaxExclusive,
+	}
+}
+
+// ValidateTaxRule - tarif 0-100 persen
+func ValidateTaxRule(rule models.TaxRule) error {
+	if rule.Rate < 0 || rule.Rate > 100 {
+		return errors.New("rate must be between 0 and 100")
+	}
+	return nil
+}
+
+var nonDigit = regexp.MustCompile(`\D`)
+
+// NormalizeNPWP - menghapus titik dan strip, NPWP lama 15 digit atau NPWP 16 digit (NIK)
+func NormalizeNPWP(npwp string) (string, error) {
+	digits := nonDigit.ReplaceAllString(npwp, "")
+	if len(digits) != 15 && len(digits) != 16 {
+		return "", ErrInvalidNPWP
+	}
+	return digits, nil
+}
@@ -0,0 +1,374 @@
+// utils/trending.go
+package utils
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/shipping"
+	"errors"
+	"gorm.io/gorm"
+	"log"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// Bobot tiap jenis interaksi untuk skor trending. Pembelian paling kuat, view paling lemah
+// karena murah dan mudah berulang.
+const (
+	trendingSaleWeight = 3.0
+	trendingCartWeight = 1.0
+	trendingViewWeight = 0.2
+
+	// Produk dari seller di kota yang sama mendapat boost di ranking kota
+	trendingLocalSellerBoost = 1.5
+
+	trendingNationalLimit = 100
+	trendingCityLimit     = 50
+)
+
+var ErrUnknownTrendingWindow = errors.New("window must be 24h or 7d")
+
+// TrendingWindow - panjang window dan half-life peluruhan skor
+type TrendingWindow struct {
+	Name     string
+	Length   time.Duration
+	HalfLife time.Duration
+}
+
+// TrendingWindows - window yang dimaterialisasi, dari yang terpendek
+var TrendingWindows = []TrendingWindow{
+	{Name: models.TrendingWindowDay, Length: 24 * time.Hour, HalfLife: 6 * time.Hour},
+	{Name: models.TrendingWindowWeek, Length: 7 * 24 * time.Hour, HalfLife: 48 * time.Hour},
+}
+
+// LookupTrendingWindow - window berdasarkan nama ("24h" / "7d")
+func LookupTrendingWindow(name string) (TrendingWindow, error) {
+	for _, window := range TrendingWindows {
+		if window.Name == name {
+			return window, nil
+		}
+	}
+	return TrendingWindow{}, ErrUnknownTrendingWindow
+}
+
+// TrendingRefreshInterval - dari env TRENDING_REFRESH_INTERVAL (default 10 menit)
+func TrendingRefreshInterval() time.Duration {
+	if interval, err := time.ParseDuration(os.Getenv("TRENDING_REFRESH_INTERVAL")); err == nil && interval > 0 {
+		return interval
+	}
+	return 10 * time.Minute
+}
+
+// TrendingEvent - interaksi yang sudah diagregasi per produk, kota pembeli dan jam
+type TrendingEvent struct {
+	ProductID uint
+	City      string // kota pembeli yang sudah dinormalisasi, kosong jika tidak diketahui
+	At        time.Time
+	Sales     uint
+	Carts     uint
+	Views     uint
+}
+
+// TrendingProductInfo - kota seller produk yang masih layak tampil (aktif dan ada stok)
+type TrendingProductInfo struct {
+	SellerCity string
+}
+
+// TrendingScore - hasil perhitungan satu produk di satu ranking
+type TrendingScore struct {
+	ProductID uint
+	Score     float64
+	Sales     uint
+	Carts     uint
+	Views     uint
+}
+
+// LoadTrendingEvents - pembelian, keranjang dan view sejak `since`, dikelompokkan per jam.
+// Kota pembeli diambil dari alamat default, lalu kota di profil (sama seperti GetUserDestination).
+func LoadTrendingEvents(db *gorm.DB, since time.Time) ([]TrendingEvent, error) {
+	type eventRow struct {
+		ProductID  uint
+		BuyerCity  string
+		HourBucket int64
+		Total      uint
+	}
+	buyerCity := "COALESCE(NULLIF(user_addresses.city, ''), user_profiles.city, '') as buyer_city"
+	withBuyerCity := func(query *gorm.DB, userColumn string) *gorm.DB {
+		return query.
+			Joins("LEFT JOIN user_addresses ON user_addresses.user_id = "+userColumn+" AND user_addresses.is_default = ?", true).
+			Joins("LEFT JOIN user_profiles ON user_profiles.user_id = " + userColumn)
+	}
+
+	hourBucket := func(column string) string {
+		// SQLite dipakai cmd/receval -synthetic
+		if db.Dialector.Name() == "sqlite" {
+			return "CAST(strftime('%s', " + column + ") AS INTEGER) / 3600"
+		}
+		return "FLOOR(UNIX_TIMESTAMP(" + column + ") / 3600)"
+	}
+
+	var sales, carts, views []eventRow
+	if err := withBuyerCity(db.Table("product_user_histories"), "product_user_histories.user_id").
+		Select("product_user_histories.product_id, "+buyerCity+
+			", "+hourBucket("product_user_histories.created_at")+" as hour_bucket"+
+			", SUM(product_user_histories.quantity) as total").
+		Where("product_user_histories.created_at >= ?", since).
+		Group("product_user_histories.product_id, buyer_city, hour_bucket").
+		Scan(&sales).Error; err != nil {
+		return nil, err
+	}
+	// Keranjang memakai updated_at: menambah quantity juga sinyal minat yang baru
+	if err := withBuyerCity(db.Table("product_user_carts"), "product_user_carts.user_id").
+		Select("product_user_carts.product_id, "+buyerCity+
+			", "+hourBucket("product_user_carts.updated_at")+" as hour_bucket"+
+			", COUNT(*) as total").
+		Where("product_user_carts.updated_at >= ?", since).
+		Group("product_user_carts.product_id, buyer_city, hour_bucket").
+		Scan(&carts).Error; err != nil {
+		return nil, err
+	}
+	// View anonim tidak punya kota, hanya dihitung di ranking nasional
+	if err := withBuyerCity(db.Table("product_views"), "product_views.user_id").
+		Select("product_views.product_id, "+buyerCity+
+			", "+hourBucket("product_views.viewed_at")+" as hour_bucket"+
+			", COUNT(*) as total").
+		Where("product_views.viewed_at >= ?", since).
+		Group("product_views.product_id, buyer_city, hour_bucket").
+		Scan(&views).Error; err != nil {
+		return nil, err
+	}
+
+	// Event diberi waktu di tengah jamnya
+	toEvent := func(row eventRow) TrendingEvent {
+		return TrendingEvent{
+			ProductID: row.ProductID,
+			City:      shipping.NormalizeCity(row.BuyerCity),
+			At:        time.Unix(row.HourBucket*3600+1800, 0),
+		}
+	}
+	events := make([]TrendingEvent, 0, len(sales)+len(carts)+len(views))
+	for _, row := range sales {
+		event := toEvent(row)
+		event.Sales = row.Total
+		events = append(events, event)
+	}
+	for _, row := range carts {
+		event := toEvent(row)
+		event.Carts = row.Total
+		events = append(events, event)
+	}
+	for _, row := range views {
+		event := toEvent(row)
+		event.Views = row.Total
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// LoadTrendingProductInfo - kota seller untuk produk yang masih aktif dan ada stok.
+// Produk yang tidak ada di hasil tidak ikut diranking.
+func LoadTrendingProductInfo(db *gorm.DB, productIDs []uint) (map[uint]TrendingProductInfo, error) {
+	info := make(map[uint]TrendingProductInfo, len(productIDs))
+	if len(productIDs) == 0 {
+		return info, nil
+	}
+
+	var rows []struct {
+		ID         uint
+		SellerCity string
+	}
+	if err := db.Table("products").
+		Select("products.id, COALESCE(seller_profiles.city, '') as seller_city").
+		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
+		Where("products.id IN ? AND products.is_active = ? AND products.stock > 0", productIDs, true).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		info[row.ID] = TrendingProductInfo{SellerCity: shipping.NormalizeCity(row.SellerCity)}
+	}
+	return info, nil
+}
+
+// ComputeTrending - skor per kota ("" = nasional) untuk satu window. Tiap event diberi bobot
+// sesuai jenisnya lalu diluruhkan eksponensial berdasarkan umurnya, event di luar window diabaikan.
+// Ranking kota hanya menghitung pembeli dari kota itu, dengan boost untuk seller lokal.
+func ComputeTrending(events []TrendingEvent, products map[uint]TrendingProductInfo, window TrendingWindow, now time.Time) map[string][]TrendingScore {
+	since := now.Add(-window.Length)
+	tallies := make(map[string]map[uint]*TrendingScore)
+	add := func(city string, event TrendingEvent, score float64) {
+		byProduct, ok := tallies[city]
+		if !ok {
+			byProduct = make(map[uint]*TrendingScore)
+			tallies[city] = byProduct
+		}
+		tally, ok := byProduct[event.ProductID]
+		if !ok {
+			tally = &TrendingScore{ProductID: event.ProductID}
+			byProduct[event.ProductID] = tally
+		}
+		tally.Score += score
+		tally.Sales += event.Sales
+		tally.Carts += event.Carts
+		tally.Views += event.Views
+	}
+
+	for _, event := range events {
+		if event.At.Before(since) {
+			continue
+		}
+		if _, ok := products[event.ProductID]; !ok {
+			continue
+		}
+		weight := trendingSaleWeight*float64(event.Sales) +
+			trendingCartWeight*float64(event.Carts) +
+			trendingViewWeight*float64(event.Views)
+		score := weight * ViewDecayWeight(now.Sub(event.At), window.HalfLife)
+
+		add("", event, score)
+		if event.City != "" {
+			add(event.City, event, score)
+		}
+	}
+
+	rankings := make(map[string][]TrendingScore, len(tallies))
+	for city, byProduct := range tallies {
+		limit := trendingNationalLimit
+		if city != "" {
+			limit = trendingCityLimit
+		}
+
+		scores := make([]TrendingScore, 0, len(byProduct))
+		for productID, tally := range byProduct {
+			if city != "" && products[productID].SellerCity == city {
+				tally.Score *= trendingLocalSellerBoost
+			}
+			scores = append(scores, *tally)
+		}
+		sort.Slice(scores, func(i, j int) bool {
+			if scores[i].Score != scores[j].Score {
+				return scores[i].Score > scores[j].Score
+			}
+			return scores[i].ProductID < scores[j].ProductID
+		})
+		if len(scores) > limit {
+			scores = scores[:limit]
+		}
+		rankings[city] = scores
+	}
+	return rankings
+}
+
+// SaveTrendingRankings - mengganti seluruh ranking satu window dalam satu transaksi,
+// sehingga pembaca tidak pernah melihat ranking setengah jadi
+func SaveTrendingRankings(db *gorm.DB, window string, rankings map[string][]TrendingScore, now time.Time) (int, error) {
+	var rows []models.TrendingProduct
+	for city, scores := range rankings {
+		for i, score := range scores {
+			rows = append(rows, models.TrendingProduct{
+				Window:     window,
+				City:       city,
+				ProductID:  score.ProductID,
+				Rank:       uint(i + 1),
+				Score:      math.Round(score.Score*1e4) / 1e4,
+				Sales:      score.Sales,
+				Carts:      score.Carts,
+				Views:      score.Views,
+				ComputedAt: now,
+			})
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("time_window = ?", window).Delete(&models.TrendingProduct{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rows, 500).Error
+	})
+	return len(rows), err
+}
+
+// RefreshTrendingProducts - menghitung ulang semua window dari event 7 hari terakhir
+func RefreshTrendingProducts(db *gorm.DB, now time.Time) (int, error) {
+	longest := TrendingWindows[len(TrendingWindows)-1]
+	events, err := LoadTrendingEvents(db, now.Add(-longest.Length))
+	if err != nil {
+		return 0, err
+	}
+
+	seen := make(map[uint]bool)
+	var productIDs []uint
+	for _, event := range events {
+		if !seen[event.ProductID] {
+			seen[event.ProductID] = true
+			productIDs = append(productIDs, event.ProductID)
+		}
+	}
+	products, err := LoadTrendingProductInfo(db, productIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, window := range TrendingWindows {
+		rows, err := SaveTrendingRankings(db, window.Name, ComputeTrending(events, products, window, now), now)
+		if err != nil {
+			return total, err
+		}
+		total += rows
+	}
+	return total, nil
+}
+
+// StartTrendingRefresh - hitung ranking saat start lalu ulangi setiap TrendingRefreshInterval
+func StartTrendingRefresh(db *gorm.DB) {
+	go func() {
+		interval := TrendingRefreshInterval()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := RefreshTrendingProducts(db, time.Now()); err != nil {
+				log.Printf("Refresh trending products failed: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// GetTrendingProducts - produk trending urut rank untuk window dan kota (kosong = nasional).
+// Produk yang sudah tidak aktif atau habis sejak refresh terakhir dilewati.
+func GetTrendingProducts(db *gorm.DB, window, city string, limit int) ([]models.ProductListView, time.Time, error) {
+	var products []models.ProductListView
+	err := db.Table("trending_products").
+		Select(`products.id, products.name, products.price, products.rating,
+                products.total_sold, products.images as image, products.category,
+                seller_profiles.shop_name, seller_profiles.city`).
+		Joins("JOIN products ON trending_products.product_id = products.id").
+		Joins("LEFT JOIN seller_profiles ON products.seller_id = seller_profiles.seller_id").
+		Where("trending_products.time_window = ? AND trending_products.city = ?", window, shipping.NormalizeCity(city)).
+		Where("products.is_active = ? AND products.stock > 0", true).
+		Order("trending_products.rank ASC").
+		Limit(limit).
+		Find(&products).Error
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var computedAt time.Time
+	if len(products) > 0 {
+		var latest models.TrendingProduct
+		if err := db.Where("time_window = ? AND city = ?", window, shipping.NormalizeCity(city)).
+			Order("computed_at DESC").
+			First(&latest).Error; err == nil {
+			computedAt = latest.ComputedAt
+		}
+	}
+
+	ProcessProductImages(&products)
+	return products, computedAt, nil
+}
@@ -0,0 +1,148 @@
+// utils/viewRecorder.go
+package utils
+
+import (
+	"ecommerce-golang/models"
+	"gorm.io/gorm"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// ViewRecorder - mencatat view produk di background. Record tidak pernah menunggu database:
+// event masuk ke buffer channel lalu ditulis per batch (saat batch penuh atau tiap flushInterval).
+// Jika buffer penuh event dibuang, halaman produk tidak boleh melambat karena pencatatan.
+type ViewRecorder struct {
+	db            *gorm.DB
+	events        chan models.ProductView
+	batchSize     int
+	flushInterval time.Duration
+	dropped       atomic.Int64
+}
+
+func NewViewRecorder(db *gorm.DB, bufferSize, batchSize int, flushInterval time.Duration) *ViewRecorder {
+	return &ViewRecorder{
+		db:            db,
+		events:        make(chan models.ProductView, bufferSize),
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+	}
+}
+
+// Record - antre satu view, false jika buffer penuh
+func (r *ViewRecorder) Record(view models.ProductView) bool {
+	select {
+	case r.events <- view:
+		return true
+	default:
+		r.dropped.Add(1)
+		return false
+	}
+}
+
+// Dropped - jumlah view yang dibuang karena buffer penuh
+func (r *ViewRecorder) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+func (r *ViewRecorder) Start() {
+	go func() {
+		ticker := time.NewTicker(r.flushInterval)
+		defer ticker.Stop()
+
+		batch := make([]models.ProductView, 0, r.batchSize)
+		for {
+			select {
+			case view := <-r.events:
+				batch = append(batch, view)
+				if len(batch) < r.batchSize {
+					continue
+				}
+			case <-ticker.C:
+				if len(batch) == 0 {
+					continue
+				}
+			}
+			if err := r.flush(batch); err != nil {
+				log.Printf("Record %d product views failed: %v", len(batch), err)
+			}
+			batch = batch[:0]
+		}
+	}()
+}
+
+// flush - membuang view dari user yang menjeda riwayat, view dari sebelum user menghapus riwayatnya
+// dan view ganda (user/visitor dan produk yang sama) di batch yang sama, lalu menulis sisanya sekaligus
+func (r *ViewRecorder) flush(batch []models.ProductView) error {
+	var userIDs []uint
+	for _, view := range batch {
+		if view.UserID != nil {
+			userIDs = append(userIDs, *view.UserID)
+		}
+	}
+	profiles := make(map[uint]models.UserProfile)
+	if len(userIDs) > 0 {
+		var found []models.UserProfile
+		if err := r.db.Select("user_id", "view_history_paused", "view_history_cleared_at").
+			Where("user_id IN ? AND (view_history_paused = ? OR view_history_cleared_at IS NOT NULL)", userIDs, true).
+			Find(&found).Error; err != nil {
+			return err
+		}
+		for _, profile := range found {
+			profiles[profile.UserID] = profile
+		}
+	}
+
+	type viewKey struct {
+		userID    uint
+		visitorID string
+		productID uint
+	}
+	latest := make(map[viewKey]int)
+	rows := make([]models.ProductView, 0, len(batch))
+	for _, view := range batch {
+		key := viewKey{visitorID: view.VisitorID, productID: view.ProductID}
+		if view.UserID != nil {
+			profile := profiles[*view.UserID]
+			if profile.ViewHistoryPaused ||
+				(profile.ViewHistoryClearedAt != nil && !view.ViewedAt.After(*profile.ViewHistoryClearedAt)) {
+				continue
+			}
+			key = viewKey{userID: *view.UserID, productID: view.ProductID}
+		}
+		if i, ok := latest[key]; ok {
+			if view.ViewedAt.After(rows[i].ViewedAt) {
+				rows[i].ViewedAt = view.ViewedAt
+			}
+			continue
+		}
+		latest[key] = len(rows)
+		rows = append(rows, view)
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(rows, r.batchSize).Error
+}
+
+var viewRecorder *ViewRecorder
+
+// StartViewRecorder - recorder global yang dipakai RecordProductView
+func StartViewRecorder(db *gorm.DB) {
+	viewRecorder = NewViewRecorder(db, 10000, 200, 5*time.Second)
+	viewRecorder.Start()
+}
+
+// RecordProductView - mencatat view dari user (userID > 0) atau visitor anonim.
+// Tidak melakukan apa-apa jika recorder belum dijalankan atau tidak ada identitas.
+func RecordProductView(userID uint, visitorID string, productID uint, viewedAt time.Time) {
+	if viewRecorder == nil || (userID == 0 && visitorID == "") {
+		return
+	}
+	view := models.ProductView{VisitorID: visitorID, ProductID: productID, ViewedAt: viewedAt}
+	if userID > 0 {
+		view.UserID = &userID
+	}
+	viewRecorder.Record(view)
+}
@@ -0,0 +1,316 @@
+// utils/voucher.go
+package utils
+
+import (
+	"ecommerce-golang/models"
+	"ecommerce-golang/pricing"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"log"
+	"strings"
+	"time"
+)
+
+var (
+	ErrVoucherNotFound      = errors.New("voucher not found")
+	ErrVoucherInactive      = errors.New("voucher is not active")
+	ErrVoucherNotStarted    = errors.New("voucher is not yet valid")
+	ErrVoucherExpired       = errors.New("voucher has expired")
+	ErrVoucherNotApplicable = pricing.ErrVoucherNotApplicable
+	ErrVoucherMinSpend      = pricing.ErrVoucherMinSpend
+	ErrVoucherExhausted     = errors.New("voucher quota has been used up")
+	ErrVoucherUserLimit     = errors.New("you have reached the usage limit for this voucher")
+	ErrVoucherNotUsable     = errors.New("applied voucher can no longer be used")
+)
+
+// VoucherClaimTTL - lama kuota voucher dipegang keranjang. Klaim yang tidak dipakai checkout
+// dalam waktu ini dilepas ReleaseExpiredVoucherClaims; memasang ulang voucher memperpanjangnya.
+const VoucherClaimTTL = 30 * time.Minute
+
+// CheckVoucherValidity - cek status aktif dan periode berlaku voucher
+func CheckVoucherValidity(voucher models.Voucher, now time.Time) error {
+	if !voucher.IsActive {
+		return ErrVoucherInactive
+	}
+	if now.Before(voucher.StartsAt) {
+		return ErrVoucherNotStarted
+	}
+	if !now.Before(voucher.EndsAt) {
+		return ErrVoucherExpired
+	}
+	return nil
+}
+
+// VoucherRule - aturan voucher dalam bentuk yang dipakai pricing engine
+func VoucherRule(voucher models.Voucher) pricing.Voucher {
+	rule := pricing.Voucher{
+		Code:        voucher.Code,
+		Type:        voucher.Type,
+		Amount:      pricing.FromFloat(voucher.Value),
+		MinSpend:    voucher.MinSpend.Amount,
+		MaxDiscount: voucher.MaxDiscount.Amount,
+		SellerID:    voucher.SellerID,
+		Category:    voucher.Category,
+	}
+	if voucher.Type == models.VoucherPercentage {
+		rule.BasisPoints = pricing.PercentToBasisPoints(voucher.Value)
+		rule.Amount = 0
+	}
+	return rule
+}
+
+// GetAppliedVoucher - voucher yang sedang dipasang di keranjang user, nil jika tidak ada
+// atau klaimnya sudah kedaluwarsa
+func GetAppliedVoucher(db *gorm.DB, userID uint, now time.Time) (*models.Voucher, error) {
+	var redemption models.VoucherRedemption
+	err := db.Preload("Voucher").
+		Where("user_id = ? AND status = ? AND expires_at > ?", userID, models.RedemptionApplied, now).
+		Order("id DESC").
+		First(&redemption).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &redemption.Voucher, nil
+}
+
+// ApplyVoucherToCart - memasang voucher ke keranjang user dan langsung mengklaim kuotanya.
+// Row voucher dan user dikunci (SELECT ... FOR UPDATE) sehingga request paralel tidak bisa
+// melewati batas pemakaian global maupun per user. Voucher lain yang sedang dipasang dilepas.
+// Klaim berlaku VoucherClaimTTL, memasang voucher yang sama memperpanjangnya.
+func ApplyVoucherToCart(db *gorm.DB, userID uint, code string, now time.Time) (models.Voucher, pricing.Result, error) {
+	var voucher models.Voucher
+	var result pricing.Result
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Serialisasi apply/remove milik user yang sama
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.User{}, userID).Error; err != nil {
+			return err
+		}
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("code = ?", strings.ToUpper(strings.TrimSpace(code))).
+			First(&voucher).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrVoucherNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := CheckVoucherValidity(voucher, now); err != nil {
+			return err
+		}
+		lines, err := GetCartLines(tx, userID, now)
+		if err != nil {
+			return err
+		}
+		rule := VoucherRule(voucher)
+		if result, err = pricing.Calculate(pricing.Input{Lines: lines, Voucher: &rule}); err != nil {
+			return err
+		}
+
+		var current models.VoucherRedemption
+		err = tx.Where("user_id = ? AND status = ?", userID, models.RedemptionApplied).First(&current).Error
+		if err == nil {
+			if current.VoucherID == voucher.ID {
+				// Voucher yang sama sudah dipasang
+				return tx.Model(&current).Update("expires_at", now.Add(VoucherClaimTTL)).Error
+			}
+			if _, err := releaseRedemption(tx, current); err != nil {
+				return err
+			}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if voucher.UsageLimit > 0 && voucher.UsedCount >= voucher.UsageLimit {
+			return ErrVoucherExhausted
+		}
+		if voucher.PerUserLimit > 0 {
+			var used int64
+			if err := tx.Model(&models.VoucherRedemption{}).
+				Where("voucher_id = ? AND user_id = ?", voucher.ID, userID).
+				Count(&used).Error; err != nil {
+				return err
+			}
+			if uint(used) >= voucher.PerUserLimit {
+				return ErrVoucherUserLimit
+			}
+		}
+
+		expiresAt := now.Add(VoucherClaimTTL)
+		redemption := models.VoucherRedemption{
+			VoucherID: voucher.ID,
+			UserID:    userID,
+			Status:    models.RedemptionApplied,
+			ExpiresAt: &expiresAt,
+		}
+		if err := tx.Create(&redemption).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Voucher{}).Where("id = ?", voucher.ID).
+			UpdateColumn("used_count", gorm.Expr("used_count + 1")).Error; err != nil {
+			return err
+		}
+		voucher.UsedCount++
+		return nil
+	})
+
+	return voucher, result, err
+}
+
+// RemoveVoucherFromCart - melepas voucher dari keranjang dan mengembalikan kuotanya.
+// Mengembalikan ErrVoucherNotFound jika tidak ada voucher yang dipasang.
+func RemoveVoucherFromCart(db *gorm.DB, userID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.User{}, userID).Error; err != nil {
+			return err
+		}
+
+		var current models.VoucherRedemption
+		err := tx.Where("user_id = ? AND status = ?", userID, models.RedemptionApplied).First(&current).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrVoucherNotFound
+		}
+		if err != nil {
+			return err
+		}
+		_, err = releaseRedemption(tx, current)
+		return err
+	})
+}
+
+// releaseRedemption - menghapus klaim yang masih applied dan mengembalikan kuotanya.
+// Status dicek ulang supaya kuota tidak dikembalikan dua kali (cleanup dan user bersamaan).
+func releaseRedemption(tx *gorm.DB, redemption models.VoucherRedemption) (bool, error) {
+	result := tx.Where("id = ? AND status = ?", redemption.ID, models.RedemptionApplied).
+		Delete(&models.VoucherRedemption{})
+	return restoreVoucherQuota(tx, redemption, result)
+}
+
+// releaseExpiredRedemption - seperti releaseRedemption tetapi hanya jika klaim masih kedaluwarsa
+// pada now, sehingga klaim yang diperpanjang setelah dibaca cleanup tidak ikut dihapus
+func releaseExpiredRedemption(tx *gorm.DB, redemption models.VoucherRedemption, now time.Time) (bool, error) {
+	result := tx.Where("id = ? AND status = ? AND (expires_at IS NULL OR expires_at <= ?)",
+		redemption.ID, models.RedemptionApplied, now).
+		Delete(&models.VoucherRedemption{})
+	return restoreVoucherQuota(tx, redemption, result)
+}
+
+// restoreVoucherQuota - mengembalikan kuota voucher jika delete klaim benar-benar menghapus baris
+func restoreVoucherQuota(tx *gorm.DB, redemption models.VoucherRedemption, deleted *gorm.DB) (bool, error) {
+	if deleted.Error != nil || deleted.RowsAffected == 0 {
+		return false, deleted.Error
+	}
+	err := tx.Model(&models.Voucher{}).
+		Where("id = ? AND used_count > 0", redemption.VoucherID).
+		UpdateColumn("used_count", gorm.Expr("used_count - 1")).Error
+	return err == nil, err
+}
+
+// RedeemAppliedVoucher - klaim voucher user yang masih berlaku menjadi redeemed saat pesanan dibuat
+func RedeemAppliedVoucher(tx *gorm.DB, userID uint, now time.Time) error {
+	return tx.Model(&models.VoucherRedemption{}).
+		Where("user_id = ? AND status = ? AND expires_at > ?", userID, models.RedemptionApplied, now).
+		Updates(map[string]interface{}{"status": models.RedemptionRedeemed, "expires_at": nil}).Error
+}
+
+// ReleaseExpiredVoucherClaims - mengembalikan kuota voucher yang dipasang di keranjang tetapi
+// tidak dipakai checkout sampai klaimnya kedaluwarsa (termasuk klaim lama tanpa expires_at)
+func ReleaseExpiredVoucherClaims(db *gorm.DB, now time.Time) (int, error) {
+	var expired []models.VoucherRedemption
+	if err := db.Where("status = ? AND (expires_at IS NULL OR expires_at <= ?)", models.RedemptionApplied, now).
+		Limit(500).Find(&expired).Error; err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, redemption := range expired {
+		changed := false
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			changed, err = releaseExpiredRedemption(tx, redemption, now)
+			return err
+		})
+		if err != nil {
+			return released, err
+		}
+		if changed {
+			released++
+		}
+	}
+	return released, nil
+}
+
+// StartVoucherClaimCleanup - goroutine yang melepas klaim voucher kedaluwarsa setiap menit
+func StartVoucherClaimCleanup(db *gorm.DB) {
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if released, err := ReleaseExpiredVoucherClaims(db, time.Now()); err != nil {
+				log.Printf("Release voucher claims failed: %v", err)
+			} else if released > 0 {
+				log.Printf("Released %d expired voucher claims", released)
+			}
+		}
+	}()
+}
+
+// ValidateVoucher - validasi konfigurasi voucher sebelum disimpan
+func ValidateVoucher(voucher *models.Voucher) error {
+	voucher.Code = strings.ToUpper(strings.TrimSpace(voucher.Code))
+	voucher.Category = strings.TrimSpace(voucher.Category)
+
+	if voucher.Code == "" || strings.ContainsAny(voucher.Code, " \t") {
+		return errors.New("code is required and must not contain spaces")
+	}
+
+	switch voucher.Type {
+	case models.VoucherPercentage:
+		if voucher.Value <= 0 || voucher.Value > 100 {
+			return errors.New("percentage value must be between 0 and 100")
+		}
+	case models.VoucherFixed:
+		if voucher.Value <= 0 {
+			return errors.New("fixed value must be greater than 0")
+		}
+	case models.VoucherFreeShipping:
+		if voucher.Value < 0 {
+			return errors.New("free shipping cap must not be negative")
+		}
+	default:
+		return errors.New("type must be one of: percentage, fixed, free_shipping")
+	}
+
+	if voucher.MinSpend.Amount < 0 || voucher.MaxDiscount.Amount < 0 {
+		return errors.New("min_spend and max_discount must not be negative")
+	}
+
+	switch voucher.Scope {
+	case models.VoucherScopePlatform:
+		if voucher.SellerID != nil || voucher.Category != "" {
+			return errors.New("platform voucher must not be limited to a seller or category")
+		}
+	case models.VoucherScopeSeller:
+		if voucher.SellerID == nil {
+			return errors.New("seller voucher requires seller_id")
+		}
+	case models.VoucherScopeCategory:
+		if voucher.Category == "" {
+			return errors.New("category voucher requires category")
+		}
+	default:
+		return errors.New("scope must be one of: platform, seller, category")
+	}
+
+	if voucher.StartsAt.IsZero() || voucher.EndsAt.IsZero() || !voucher.EndsAt.After(voucher.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// utils/wishlist.go
+package utils
+
+import (
+	"ecommerce-golang/models"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrShopNotFound       = errors.New("shop not found")
+	ErrCollectionNotFound = errors.New("wishlist collection not found")
+)
+
+// FollowShop - user mengikuti toko. Idempotent: follow ulang tidak menambah follower_count.
+// Mengembalikan true jika follow baru dibuat.
+func FollowShop(db *gorm.DB, userID, sellerID uint) (bool, error) {
+	created := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var profile models.SellerProfile
+		if err := tx.Select("id").Where("seller_id = ?", sellerID).First(&profile).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrShopNotFound
+			}
+			return err
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.ShopFollow{UserID: userID, SellerID: sellerID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		created = true
+		return tx.Model(&models.SellerProfile{}).
+			Where("seller_id = ?", sellerID).
+			UpdateColumn("follower_count", gorm.Expr("follower_count + 1")).Error
+	})
+	return created, err
+}
+
+// UnfollowShop - berhenti mengikuti toko. Mengembalikan true jika sebelumnya mengikuti.
+func UnfollowShop(db *gorm.DB, userID, sellerID uint) (bool, error) {
+	removed := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ? AND seller_id = ?", userID, sellerID).Delete(&models.ShopFollow{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		removed = true
+		return tx.Model(&models.SellerProfile{}).
+			Where("seller_id = ? AND follower_count > 0", sellerID).
+			UpdateColumn("follower_count", gorm.Expr("follower_count - 1")).Error
+	})
+	return removed, err
+}
+
+// IsFollowingShop - untuk menampilkan tombol follow/unfollow di halaman toko
+func IsFollowingShop(db *gorm.DB, userID, sellerID uint) bool {
+	var count int64
+	db.Model(&models.ShopFollow{}).Where("user_id = ? AND seller_id = ?", userID, sellerID).Count(&count)
+	return count > 0
+}
+
+// CheckUserCollection - memastikan koleksi wishlist milik user
+func CheckUserCollection(db *gorm.DB, userID uint, collectionID *uint) error {
+	if collectionID == nil {
+		return nil
+	}
+	var count int64
+	if err := db.Model(&models.WishlistCollection{}).
+		Where("id = ? AND user_id = ?", *collectionID, userID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrCollectionNotFound
+	}
+	return nil
+}